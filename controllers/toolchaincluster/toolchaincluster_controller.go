@@ -12,6 +12,7 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	kubeclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -20,9 +21,11 @@ import (
 
 // Reconciler reconciles a ToolchainCluster object
 type Reconciler struct {
-	Client      client.Client
-	Scheme      *runtime.Scheme
-	RequeAfter  time.Duration
+	Client     client.Client
+	Scheme     *runtime.Scheme
+	RequeAfter time.Duration
+	// Clock the clock used to set the timestamps of the conditions in the status, the real clock if nil
+	Clock       clock.PassiveClock
 	checkHealth func(context.Context, *kubeclientset.Clientset) (bool, error)
 }
 
@@ -84,7 +87,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.
 }
 
 func (r *Reconciler) updateStatus(ctx context.Context, toolchainCluster *toolchainv1alpha1.ToolchainCluster, cachedToolchainCluster *cluster.CachedToolchainCluster, currentConditions ...toolchainv1alpha1.Condition) error {
-	toolchainCluster.Status.Conditions = condition.AddOrUpdateStatusConditionsWithLastUpdatedTimestamp(toolchainCluster.Status.Conditions, currentConditions...)
+	toolchainCluster.Status.Conditions = condition.WithClock(r.Clock).AddOrUpdateStatusConditionsWithLastUpdatedTimestamp(toolchainCluster.Status.Conditions, currentConditions...)
 
 	if cachedToolchainCluster != nil {
 		toolchainCluster.Status.APIEndpoint = cachedToolchainCluster.APIEndpoint
@@ -9,12 +9,14 @@ import (
 	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
 	"github.com/codeready-toolchain/toolchain-common/pkg/cluster"
 	"github.com/codeready-toolchain/toolchain-common/pkg/test"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/h2non/gock.v1"
 	corev1 "k8s.io/api/core/v1"
 	kubeclientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	clocktesting "k8s.io/utils/clock/testing"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -99,6 +101,35 @@ func TestClusterControllerChecks(t *testing.T) {
 		assertClusterStatus(t, cl, "stable", clusterReadyCondition())
 	})
 
+	t.Run("status timestamps set with the clock", func(t *testing.T) {
+		// given
+		stable, sec := newToolchainCluster(t, "stable", tcNs, "https://cluster.com")
+
+		cl := test.NewFakeClient(t, stable, sec)
+		reset := setupCachedClusters(t, cl, stable)
+
+		defer reset()
+		controller, req := prepareReconcile(stable, cl, requeAfter)
+		firstProbe := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		clock := clocktesting.NewFakePassiveClock(firstProbe)
+		controller.Clock = clock
+
+		// when
+		_, err := controller.Reconcile(context.TODO(), req)
+		require.NoError(t, err)
+		clock.SetTime(firstProbe.Add(requeAfter))
+		_, err = controller.Reconcile(context.TODO(), req)
+
+		// then
+		require.NoError(t, err)
+		tc := &toolchainv1alpha1.ToolchainCluster{}
+		require.NoError(t, cl.Get(context.TODO(), req.NamespacedName, tc))
+		require.Len(t, tc.Status.Conditions, 1)
+		assert.Equal(t, firstProbe, tc.Status.Conditions[0].LastTransitionTime.UTC()) // the status didn't change
+		require.NotNil(t, tc.Status.Conditions[0].LastUpdatedTime)
+		assert.Equal(t, firstProbe.Add(requeAfter), tc.Status.Conditions[0].LastUpdatedTime.UTC())
+	})
+
 	t.Run("toolchain cluster cache not found", func(t *testing.T) {
 		// given
 		unstable, _ := newToolchainCluster(t, "unstable", tcNs, "http://unstable.com")
@@ -0,0 +1,240 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v52/github"
+	errs "github.com/pkg/errors"
+)
+
+// CommitSourceProvider the kind of server which hosts the source code repositories
+type CommitSourceProvider string
+
+const (
+	// GitHubProvider the repositories are hosted on GitHub
+	GitHubProvider CommitSourceProvider = "github"
+	// GitLabProvider the repositories are hosted on a GitLab instance
+	GitLabProvider CommitSourceProvider = "gitlab"
+	// GitProvider the repositories are served by any git server supporting the smart HTTP protocol (eg, Gitea or a mirror)
+	GitProvider CommitSourceProvider = "git"
+)
+
+// Commit the latest commit of a branch
+type Commit struct {
+	SHA string
+	// Timestamp the date of the commit, which is zero when the provider doesn't return it (eg, with the plain git protocol)
+	Timestamp time.Time
+}
+
+// CommitSource retrieves the latest commit of a branch of a source code repository
+type CommitSource interface {
+	LatestCommit(ctx context.Context, repo Repository) (*Commit, error)
+}
+
+// GetCommitSourceFunc a func that returns a CommitSource authenticated with the given access token
+type GetCommitSourceFunc func(ctx context.Context, accessToken string) CommitSource
+
+// NewCommitSourceFunc returns a func which creates the CommitSources for the given provider.
+// The base URL is ignored for GitHub, is the URL of the instance for GitLab (eg, `https://gitlab.example.com`),
+// and is the URL under which the repositories are served for plain git (eg, `https://git.example.com`).
+func NewCommitSourceFunc(provider CommitSourceProvider, baseURL string) (GetCommitSourceFunc, error) {
+	switch provider {
+	case GitHubProvider, "":
+		return func(ctx context.Context, accessToken string) CommitSource {
+			return NewGitHubCommitSource(NewGitHubClient(ctx, accessToken))
+		}, nil
+	case GitLabProvider:
+		return func(_ context.Context, accessToken string) CommitSource {
+			return &GitLabCommitSource{BaseURL: baseURL, AccessToken: accessToken}
+		}, nil
+	case GitProvider:
+		return func(_ context.Context, accessToken string) CommitSource {
+			return &GitCommitSource{BaseURL: baseURL, AccessToken: accessToken}
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported commit source provider '%s'", provider)
+	}
+}
+
+// GitHubCommitSource retrieves the commits using the GitHub API
+type GitHubCommitSource struct {
+	client *github.Client
+}
+
+// NewGitHubCommitSource returns a CommitSource which uses the given GitHub client
+func NewGitHubCommitSource(client *github.Client) *GitHubCommitSource {
+	return &GitHubCommitSource{client: client}
+}
+
+func (s *GitHubCommitSource) LatestCommit(ctx context.Context, repo Repository) (*Commit, error) {
+	latestCommit, commitResponse, err := s.client.Repositories.GetCommit(ctx, repo.Org, repo.Name, repo.Branch, &github.ListOptions{})
+	if commitResponse != nil {
+		defer commitResponse.Body.Close()
+	}
+	if err != nil {
+		if ghErr, ok := err.(*github.ErrorResponse); ok { //nolint:errorlint
+			return nil, errs.New(ghErr.Message) // this strips out the URL called, useful when unit testing since the port changes with each test execution.
+		}
+		// the rate limits detected by the GitHub client itself are reported the same way as the ones detected by the GitHubRateLimiter
+		if rlErr, ok := err.(*github.RateLimitError); ok { //nolint:errorlint
+			return nil, &RateLimitError{Until: rlErr.Rate.Reset.Time}
+		}
+		if abuseErr, ok := err.(*github.AbuseRateLimitError); ok && abuseErr.RetryAfter != nil { //nolint:errorlint
+			return nil, &RateLimitError{Until: time.Now().Add(*abuseErr.RetryAfter), Secondary: true}
+		}
+		return nil, err
+	}
+	if commitResponse.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("invalid response code from github commits API. resp.Response.StatusCode: %d, repoName: %s, repoBranch: %s", commitResponse.StatusCode, repo.Name, repo.Branch)
+	}
+	if reflect.DeepEqual(latestCommit, &github.RepositoryCommit{}) {
+		return nil, fmt.Errorf("no commits returned. repoName: %s, repoBranch: %s", repo.Name, repo.Branch)
+	}
+	return &Commit{
+		SHA:       latestCommit.GetSHA(),
+		Timestamp: latestCommit.GetCommit().GetAuthor().GetDate().Time,
+	}, nil
+}
+
+// GitLabCommitSource retrieves the commits using the API of a GitLab instance.
+// The Org and Name of the repository are the namespace and the name of the GitLab project.
+type GitLabCommitSource struct {
+	BaseURL     string
+	AccessToken string
+	// HTTPClient the client used to call the API, http.DefaultClient if nil
+	HTTPClient *http.Client
+}
+
+type gitLabCommit struct {
+	ID           string    `json:"id"`
+	AuthoredDate time.Time `json:"authored_date"`
+}
+
+func (s *GitLabCommitSource) LatestCommit(ctx context.Context, repo Repository) (*Commit, error) {
+	commitURL := fmt.Sprintf("%s/api/v4/projects/%s/repository/commits/%s", strings.TrimSuffix(s.BaseURL, "/"),
+		url.PathEscape(repo.Org+"/"+repo.Name), url.PathEscape(repo.Branch))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, commitURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.AccessToken != "" {
+		req.Header.Set("PRIVATE-TOKEN", s.AccessToken)
+	}
+	resp, err := httpClientOrDefault(s.HTTPClient).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("invalid response code from gitlab commits API. resp.Response.StatusCode: %d, repoName: %s, repoBranch: %s", resp.StatusCode, repo.Name, repo.Branch)
+	}
+	commit := &gitLabCommit{}
+	if err := json.NewDecoder(resp.Body).Decode(commit); err != nil {
+		return nil, errs.Wrap(err, "unable to decode the response of the gitlab commits API")
+	}
+	if commit.ID == "" {
+		return nil, fmt.Errorf("no commits returned. repoName: %s, repoBranch: %s", repo.Name, repo.Branch)
+	}
+	return &Commit{
+		SHA:       commit.ID,
+		Timestamp: commit.AuthoredDate,
+	}, nil
+}
+
+// GitCommitSource retrieves the commits from any git server using the smart HTTP protocol, as `git ls-remote` does.
+// The repositories are expected at `<BaseURL>/<Org>/<Name>`. The protocol doesn't return the date of the commits,
+// so the Timestamp of the returned commits is zero.
+type GitCommitSource struct {
+	BaseURL string
+	// AccessToken the token used as password with the basic authentication, if not empty
+	AccessToken string
+	// HTTPClient the client used to call the git server, http.DefaultClient if nil
+	HTTPClient *http.Client
+}
+
+func (s *GitCommitSource) LatestCommit(ctx context.Context, repo Repository) (*Commit, error) {
+	refsURL := fmt.Sprintf("%s/%s/%s/info/refs?service=git-upload-pack", strings.TrimSuffix(s.BaseURL, "/"), repo.Org, repo.Name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, refsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.AccessToken != "" {
+		req.SetBasicAuth("oauth2", s.AccessToken)
+	}
+	resp, err := httpClientOrDefault(s.HTTPClient).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("invalid response code from git server. resp.Response.StatusCode: %d, repoName: %s, repoBranch: %s", resp.StatusCode, repo.Name, repo.Branch)
+	}
+	refs, err := parseRefs(resp.Body)
+	if err != nil {
+		return nil, errs.Wrapf(err, "unable to read the refs of the repository. repoName: %s", repo.Name)
+	}
+	ref := repo.Branch
+	if ref != "HEAD" {
+		ref = "refs/heads/" + ref
+	}
+	sha, found := refs[ref]
+	if !found {
+		return nil, fmt.Errorf("no commits returned. repoName: %s, repoBranch: %s", repo.Name, repo.Branch)
+	}
+	return &Commit{SHA: sha}, nil
+}
+
+// parseRefs parses the refs advertised by a git server in the pkt-line format, and returns the commit SHAs indexed by ref name.
+// See https://git-scm.com/docs/http-protocol#_smart_clients
+func parseRefs(r io.Reader) (map[string]string, error) {
+	refs := map[string]string{}
+	reader := bufio.NewReader(r)
+	for {
+		length := make([]byte, 4)
+		if _, err := io.ReadFull(reader, length); err != nil {
+			if err == io.EOF { //nolint:errorlint
+				return refs, nil
+			}
+			return nil, err
+		}
+		size, err := strconv.ParseUint(string(length), 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pkt-line length '%s'", string(length))
+		}
+		if size <= 4 {
+			// flush packet
+			continue
+		}
+		line := make([]byte, size-4)
+		if _, err := io.ReadFull(reader, line); err != nil {
+			return nil, err
+		}
+		content := strings.TrimSuffix(string(line), "\n")
+		if strings.HasPrefix(content, "#") {
+			// service announcement
+			continue
+		}
+		// the first ref is followed by the capabilities of the server
+		content, _, _ = strings.Cut(content, "\x00")
+		if sha, name, found := strings.Cut(content, " "); found {
+			refs[name] = sha
+		}
+	}
+}
+
+func httpClientOrDefault(cl *http.Client) *http.Client {
+	if cl == nil {
+		return http.DefaultClient
+	}
+	return cl
+}
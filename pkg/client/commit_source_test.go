@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCommitSourceFunc(t *testing.T) {
+	t.Run("supported providers", func(t *testing.T) {
+		for provider, expected := range map[CommitSourceProvider]CommitSource{
+			GitHubProvider: &GitHubCommitSource{},
+			"":             &GitHubCommitSource{},
+			GitLabProvider: &GitLabCommitSource{},
+			GitProvider:    &GitCommitSource{},
+		} {
+			t.Run(string(provider), func(t *testing.T) {
+				// when
+				getCommitSource, err := NewCommitSourceFunc(provider, "https://git.example.com")
+
+				// then
+				require.NoError(t, err)
+				assert.IsType(t, expected, getCommitSource(context.TODO(), "token"))
+			})
+		}
+	})
+
+	t.Run("unsupported provider", func(t *testing.T) {
+		// when
+		_, err := NewCommitSourceFunc("svn", "https://svn.example.com")
+
+		// then
+		require.EqualError(t, err, "unsupported commit source provider 'svn'")
+	})
+}
+
+func TestGitLabCommitSource(t *testing.T) {
+	// given
+	repo := Repository{Org: "codeready-toolchain", Name: "host-operator", Branch: "master"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/codeready-toolchain%2Fhost-operator/repository/commits/master" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("PRIVATE-TOKEN") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "1234abcd", "authored_date": "2024-01-02T03:04:05Z"}`))
+	}))
+	defer server.Close()
+
+	t.Run("latest commit", func(t *testing.T) {
+		// given
+		source := &GitLabCommitSource{BaseURL: server.URL, AccessToken: "token"}
+
+		// when
+		commit, err := source.LatestCommit(context.TODO(), repo)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "1234abcd", commit.SHA)
+		assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), commit.Timestamp.UTC())
+	})
+
+	t.Run("unknown project", func(t *testing.T) {
+		// given
+		source := &GitLabCommitSource{BaseURL: server.URL, AccessToken: "token"}
+
+		// when
+		_, err := source.LatestCommit(context.TODO(), Repository{Org: "codeready-toolchain", Name: "unknown", Branch: "master"})
+
+		// then
+		require.EqualError(t, err, "invalid response code from gitlab commits API. resp.Response.StatusCode: 404, repoName: unknown, repoBranch: master")
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		// given
+		source := &GitLabCommitSource{BaseURL: server.URL, AccessToken: "invalid"}
+
+		// when
+		_, err := source.LatestCommit(context.TODO(), repo)
+
+		// then
+		require.EqualError(t, err, "invalid response code from gitlab commits API. resp.Response.StatusCode: 401, repoName: host-operator, repoBranch: master")
+	})
+}
+
+func TestGitCommitSource(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/codeready-toolchain/host-operator/info/refs" || r.URL.Query().Get("service") != "git-upload-pack" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, password, ok := r.BasicAuth(); !ok || password != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-git-upload-pack-advertisement")
+		for _, line := range []string{
+			"# service=git-upload-pack\n",
+			"",
+			"1234abcd1234abcd1234abcd1234abcd1234abcd HEAD\x00multi_ack side-band-64k symref=HEAD:refs/heads/master\n",
+			"1234abcd1234abcd1234abcd1234abcd1234abcd refs/heads/master\n",
+			"5678efgh5678efgh5678efgh5678efgh5678efgh refs/heads/feature\n",
+			"",
+		} {
+			_, _ = w.Write([]byte(pktLine(line)))
+		}
+	}))
+	defer server.Close()
+	source := &GitCommitSource{BaseURL: server.URL, AccessToken: "token"}
+
+	t.Run("latest commit", func(t *testing.T) {
+		for branch, expected := range map[string]string{
+			"HEAD":    "1234abcd1234abcd1234abcd1234abcd1234abcd",
+			"master":  "1234abcd1234abcd1234abcd1234abcd1234abcd",
+			"feature": "5678efgh5678efgh5678efgh5678efgh5678efgh",
+		} {
+			t.Run(branch, func(t *testing.T) {
+				// when
+				commit, err := source.LatestCommit(context.TODO(), Repository{Org: "codeready-toolchain", Name: "host-operator", Branch: branch})
+
+				// then
+				require.NoError(t, err)
+				assert.Equal(t, expected, commit.SHA)
+				assert.True(t, commit.Timestamp.IsZero())
+			})
+		}
+	})
+
+	t.Run("unknown branch", func(t *testing.T) {
+		// when
+		_, err := source.LatestCommit(context.TODO(), Repository{Org: "codeready-toolchain", Name: "host-operator", Branch: "unknown"})
+
+		// then
+		require.EqualError(t, err, "no commits returned. repoName: host-operator, repoBranch: unknown")
+	})
+
+	t.Run("unknown repository", func(t *testing.T) {
+		// when
+		_, err := source.LatestCommit(context.TODO(), Repository{Org: "codeready-toolchain", Name: "unknown", Branch: "master"})
+
+		// then
+		require.EqualError(t, err, "invalid response code from git server. resp.Response.StatusCode: 404, repoName: unknown, repoBranch: master")
+	})
+}
+
+// pktLine encodes the given content in the pkt-line format, or returns a flush packet if the content is empty
+func pktLine(content string) string {
+	if content == "" {
+		return "0000"
+	}
+	return fmt.Sprintf("%04x%s", len(content)+4, content)
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/google/go-github/v52/github"
@@ -14,22 +15,41 @@ const GitHubAPICallDelay = 1 * time.Minute
 // GetGitHubClientFunc a func that returns a GitHub client instance
 type GetGitHubClientFunc func(context.Context, string) *github.Client
 
-type GitHubRepository struct {
+// Repository a source code repository, along with the branch and the commit SHA of the deployed version
+type Repository struct {
 	Org, Name, Branch, DeployedCommitSHA string
 }
 
+// GitHubRepository a repository hosted on GitHub
+//
+// Deprecated: use Repository, which is not specific to GitHub
+type GitHubRepository = Repository
+
 // NewGitHubClient return a client that interacts with GitHub and has rate limiter configured.
 // With authenticated GitHub api you can make 5,000 requests per hour.
 // see: https://github.com/google/go-github#rate-limiting
+// All the clients with the same access token share the same GitHubRateLimiter.
 func NewGitHubClient(ctx context.Context, accessToken string) *github.Client {
+	return NewGitHubClientWithRateLimiter(ctx, accessToken, sharedGitHubRateLimiter(accessToken))
+}
+
+// NewGitHubClientWithRateLimiter return a client that interacts with GitHub and applies the rate limits of the given GitHubRateLimiter
+func NewGitHubClientWithRateLimiter(ctx context.Context, accessToken string, limiter *GitHubRateLimiter) *github.Client {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: accessToken},
 	)
+	// the token is set by the oauth2 transport, which then delegates to the rate limited transport
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: limiter.Transport(nil)})
 	tc := oauth2.NewClient(ctx, ts)
 	return github.NewClient(tc)
 }
 
 // CanIssueGitHubRequest checks if we already called the GitHub API and if call it again since the preconfigured threshold delay expired.
 func CanIssueGitHubRequest(lastGitHubAPICall time.Time) bool {
-	return lastGitHubAPICall.IsZero() || time.Now().After(lastGitHubAPICall.Add(GitHubAPICallDelay))
+	return CanIssueGitHubRequestAt(lastGitHubAPICall, time.Now())
+}
+
+// CanIssueGitHubRequestAt same as CanIssueGitHubRequest, at the given time
+func CanIssueGitHubRequestAt(lastGitHubAPICall, now time.Time) bool {
+	return lastGitHubAPICall.IsZero() || now.After(lastGitHubAPICall.Add(GitHubAPICallDelay))
 }
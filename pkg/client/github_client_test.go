@@ -41,3 +41,12 @@ func TestCanIssueGitHubRequest(t *testing.T) {
 		require.True(t, ok) // ok to call
 	})
 }
+
+func TestCanIssueGitHubRequestAt(t *testing.T) {
+	// given
+	lastGitHubAPICall := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// then
+	require.False(t, CanIssueGitHubRequestAt(lastGitHubAPICall, lastGitHubAPICall.Add(GitHubAPICallDelay)))
+	require.True(t, CanIssueGitHubRequestAt(lastGitHubAPICall, lastGitHubAPICall.Add(GitHubAPICallDelay+time.Nanosecond)))
+}
@@ -0,0 +1,269 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// secondaryRateLimitBackoff the initial delay before calling the GitHub API again after hitting a secondary rate limit
+	// without a `Retry-After` header. The delay doubles with each consecutive secondary rate limit.
+	secondaryRateLimitBackoff = 1 * time.Minute
+	// maxSecondaryRateLimitBackoff the maximum delay before calling the GitHub API again after hitting a secondary rate limit
+	maxSecondaryRateLimitBackoff = 1 * time.Hour
+)
+
+// maxGitHubRateLimiters the maximum number of rate limiters shared by the GitHub clients created with NewGitHubClient.
+// The least recently used rate limiter is evicted when a rate limiter is needed for another token.
+const maxGitHubRateLimiters = 100
+
+var (
+	// gitHubRateLimiters the rate limiters shared by the GitHub clients created with NewGitHubClient, indexed by the hash
+	// of their access token, since the rate limits of the GitHub API apply per token
+	gitHubRateLimiters     = map[string]*sharedRateLimiter{}
+	gitHubRateLimitersLock sync.Mutex
+)
+
+type sharedRateLimiter struct {
+	limiter  *GitHubRateLimiter
+	lastUsed time.Time
+}
+
+// sharedGitHubRateLimiter returns the rate limiter shared by the GitHub clients created with NewGitHubClient for the given access token
+func sharedGitHubRateLimiter(accessToken string) *GitHubRateLimiter {
+	key := hashToken(accessToken)
+	gitHubRateLimitersLock.Lock()
+	defer gitHubRateLimitersLock.Unlock()
+	shared, found := gitHubRateLimiters[key]
+	if !found {
+		if len(gitHubRateLimiters) >= maxGitHubRateLimiters {
+			evictLeastRecentlyUsedRateLimiter()
+		}
+		shared = &sharedRateLimiter{limiter: NewGitHubRateLimiter()}
+		gitHubRateLimiters[key] = shared
+	}
+	shared.lastUsed = time.Now()
+	return shared.limiter
+}
+
+func evictLeastRecentlyUsedRateLimiter() {
+	var oldestKey string
+	var oldest time.Time
+	for key, shared := range gitHubRateLimiters {
+		if oldestKey == "" || shared.lastUsed.Before(oldest) {
+			oldestKey, oldest = key, shared.lastUsed
+		}
+	}
+	delete(gitHubRateLimiters, oldestKey)
+}
+
+// hashToken returns the hash of the given token (or `Authorization` header), so that the tokens are not kept as is in memory
+func hashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+// RateLimitError the error returned by the GitHub clients when a request is not sent because the rate limit is exceeded
+type RateLimitError struct {
+	// Until the time after which the requests can be sent again
+	Until time.Time
+	// Secondary `true` if a secondary rate limit was hit, `false` if the quota of requests is exhausted
+	Secondary bool
+}
+
+func (e *RateLimitError) Error() string {
+	kind := "rate limit"
+	if e.Secondary {
+		kind = "secondary rate limit"
+	}
+	return fmt.Sprintf("github API %s exceeded, requests are blocked until %s", kind, e.Until.Format(time.RFC3339))
+}
+
+// GitHubRateLimiter keeps track of the rate limits of the GitHub API across the clients which share it, so that:
+//   - no request is sent while the quota of requests is exhausted, according to the `X-RateLimit-*` response headers
+//   - no request is sent during the backoff following a secondary rate limit, according to the `Retry-After` header
+//     or, if missing, with an exponential delay
+//   - the GET requests are conditional (`If-None-Match`) when the response was cached with its ETag, so that the
+//     `304 Not Modified` responses, which don't count against the quota, are replaced with the cached response
+//
+// It is safe for concurrent use.
+type GitHubRateLimiter struct {
+	sync.Mutex
+	// remaining the number of requests left in the current rate limit window, negative if unknown
+	remaining    int
+	reset        time.Time
+	backoffUntil time.Time
+	backoffs     int
+	responses    map[string]cachedResponse
+}
+
+type cachedResponse struct {
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+// NewGitHubRateLimiter returns a new GitHubRateLimiter
+func NewGitHubRateLimiter() *GitHubRateLimiter {
+	return &GitHubRateLimiter{
+		remaining: -1,
+		responses: map[string]cachedResponse{},
+	}
+}
+
+// Transport returns a RoundTripper which applies the rate limits before delegating to the given RoundTripper
+// (or http.DefaultTransport if nil)
+func (l *GitHubRateLimiter) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &rateLimitedTransport{
+		limiter: l,
+		base:    base,
+	}
+}
+
+// check returns a RateLimitError if no request should be sent at the moment
+func (l *GitHubRateLimiter) check() error {
+	l.Lock()
+	defer l.Unlock()
+	now := time.Now()
+	if now.Before(l.backoffUntil) {
+		return &RateLimitError{Until: l.backoffUntil, Secondary: true}
+	}
+	if l.remaining == 0 && now.Before(l.reset) {
+		return &RateLimitError{Until: l.reset}
+	}
+	return nil
+}
+
+// update records the rate limit headers of the given response, along with the backoff after a secondary rate limit
+func (l *GitHubRateLimiter) update(resp *http.Response, body []byte) {
+	l.Lock()
+	defer l.Unlock()
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		l.remaining = remaining
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		l.reset = time.Unix(reset, 0)
+	}
+	if !isSecondaryRateLimit(resp, body) {
+		if resp.StatusCode < http.StatusBadRequest {
+			l.backoffs = 0
+		}
+		return
+	}
+	delay := secondaryRateLimitBackoff << l.backoffs
+	if delay > maxSecondaryRateLimitBackoff || delay <= 0 {
+		delay = maxSecondaryRateLimitBackoff
+	}
+	if retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		delay = time.Duration(retryAfter) * time.Second
+	}
+	l.backoffs++
+	l.backoffUntil = time.Now().Add(delay)
+}
+
+// isSecondaryRateLimit returns `true` if the given response reports a secondary rate limit.
+// See https://docs.github.com/en/rest/using-the-rest-api/rate-limits-for-the-rest-api#exceeding-the-rate-limit
+func isSecondaryRateLimit(resp *http.Response, body []byte) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		// the primary rate limit is exceeded, which is handled via the reset time
+		return false
+	}
+	return resp.Header.Get("Retry-After") != "" || strings.Contains(strings.ToLower(string(body)), "secondary rate limit")
+}
+
+func (l *GitHubRateLimiter) cached(key string) (cachedResponse, bool) {
+	l.Lock()
+	defer l.Unlock()
+	cached, found := l.responses[key]
+	return cached, found
+}
+
+func (l *GitHubRateLimiter) store(key string, cached cachedResponse) {
+	l.Lock()
+	defer l.Unlock()
+	l.responses[key] = cached
+}
+
+type rateLimitedTransport struct {
+	limiter *GitHubRateLimiter
+	base    http.RoundTripper
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.check(); err != nil {
+		return nil, err
+	}
+	// the responses are cached per token, so that the clients with different tokens don't share them
+	key := req.URL.String() + " " + hashToken(req.Header.Get("Authorization"))
+	cached, isCached := t.limiter.cached(key)
+	if req.Method != http.MethodGet {
+		isCached = false
+	}
+	if isCached {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	var body []byte
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests ||
+		(req.Method == http.MethodGet && resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "") {
+		// the body is needed to detect the secondary rate limits and to cache the responses
+		if body, err = readAndClose(resp); err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	t.limiter.update(resp, body)
+
+	switch {
+	case isCached && resp.StatusCode == http.StatusNotModified:
+		resp.Body.Close()
+		header := cached.header.Clone()
+		// keep the up-to-date rate limit headers
+		for name, values := range resp.Header {
+			if strings.HasPrefix(name, "X-Ratelimit-") {
+				header[name] = values
+			}
+		}
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(cached.body)),
+			ContentLength: int64(len(cached.body)),
+			Request:       req,
+		}, nil
+	case req.Method == http.MethodGet && resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		t.limiter.store(key, cachedResponse{
+			etag:   resp.Header.Get("ETag"),
+			header: resp.Header.Clone(),
+			body:   body,
+		})
+	}
+	return resp, nil
+}
+
+func readAndClose(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,250 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubRateLimiter(t *testing.T) {
+	get := func(t *testing.T, cl *http.Client, url string) (*http.Response, string, error) {
+		req, err := http.NewRequestWithContext(context.TODO(), http.MethodGet, url, nil)
+		require.NoError(t, err)
+		resp, err := cl.Do(req)
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(body), nil
+	}
+
+	t.Run("conditional requests", func(t *testing.T) {
+		// given
+		var calls, notModified int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.Header().Set("X-RateLimit-Remaining", "100")
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				atomic.AddInt32(&notModified, 1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = w.Write([]byte(`{"sha":"1234abcd"}`))
+		}))
+		defer server.Close()
+		cl := &http.Client{Transport: NewGitHubRateLimiter().Transport(nil)}
+
+		for i := 0; i < 3; i++ {
+			// when
+			resp, body, err := get(t, cl, server.URL+"/repos/codeready-toolchain/host-operator/commits/HEAD")
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, `{"sha":"1234abcd"}`, body)
+		}
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&notModified))
+	})
+
+	t.Run("responses are not cached with the token", func(t *testing.T) {
+		// given
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = w.Write([]byte(`{"sha":"1234abcd"}`))
+		}))
+		defer server.Close()
+		limiter := NewGitHubRateLimiter()
+		cl := &http.Client{Transport: limiter.Transport(nil)}
+		req, err := http.NewRequestWithContext(context.TODO(), http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer secret-token")
+
+		// when
+		resp, err := cl.Do(req)
+
+		// then
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		limiter.Lock()
+		defer limiter.Unlock()
+		require.Len(t, limiter.responses, 1)
+		for key := range limiter.responses {
+			assert.NotContains(t, key, "secret-token")
+		}
+	})
+
+	t.Run("quota exhausted", func(t *testing.T) {
+		// given
+		var calls int32
+		reset := time.Now().Add(time.Hour).Truncate(time.Second)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+		cl := &http.Client{Transport: NewGitHubRateLimiter().Transport(nil)}
+		_, _, err := get(t, cl, server.URL)
+		require.NoError(t, err)
+
+		// when
+		_, _, err = get(t, cl, server.URL)
+
+		// then
+		rlErr := &RateLimitError{}
+		require.True(t, errors.As(err, &rlErr))
+		assert.False(t, rlErr.Secondary)
+		assert.Equal(t, reset, rlErr.Until)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls)) // the second request was not sent
+	})
+
+	t.Run("secondary rate limit", func(t *testing.T) {
+		t.Run("with Retry-After header", func(t *testing.T) {
+			// given
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Retry-After", "120")
+				w.WriteHeader(http.StatusForbidden)
+			}))
+			defer server.Close()
+			cl := &http.Client{Transport: NewGitHubRateLimiter().Transport(nil)}
+			resp, _, err := get(t, cl, server.URL)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+			// when
+			_, _, err = get(t, cl, server.URL)
+
+			// then
+			rlErr := &RateLimitError{}
+			require.True(t, errors.As(err, &rlErr))
+			assert.True(t, rlErr.Secondary)
+			assert.WithinDuration(t, time.Now().Add(2*time.Minute), rlErr.Until, 5*time.Second)
+		})
+
+		t.Run("with exponential backoff", func(t *testing.T) {
+			// given
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message":"You have exceeded a secondary rate limit."}`))
+			}))
+			defer server.Close()
+			limiter := NewGitHubRateLimiter()
+			cl := &http.Client{Transport: limiter.Transport(nil)}
+
+			for i, expected := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute} {
+				// when
+				resp, body, err := get(t, cl, server.URL)
+
+				// then
+				require.NoError(t, err, "attempt %d", i)
+				assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+				assert.Contains(t, body, "secondary rate limit") // the body is still readable
+				assert.WithinDuration(t, time.Now().Add(expected), limiter.backoffUntil, 5*time.Second)
+				limiter.backoffUntil = time.Time{} // let the next request go through
+			}
+		})
+
+		t.Run("forbidden without rate limit", func(t *testing.T) {
+			// given
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message":"Resource not accessible by integration"}`))
+			}))
+			defer server.Close()
+			cl := &http.Client{Transport: NewGitHubRateLimiter().Transport(nil)}
+			_, _, err := get(t, cl, server.URL)
+			require.NoError(t, err)
+
+			// when
+			resp, _, err := get(t, cl, server.URL)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		})
+	})
+
+	t.Run("concurrent requests", func(t *testing.T) {
+		// given
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Remaining", "100")
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+		cl := &http.Client{Transport: NewGitHubRateLimiter().Transport(nil)}
+		var wg sync.WaitGroup
+
+		// when
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := cl.Get(server.URL) //nolint:noctx
+				if assert.NoError(t, err) {
+					assert.Equal(t, http.StatusOK, resp.StatusCode)
+					resp.Body.Close()
+				}
+			}()
+		}
+
+		// then
+		wg.Wait()
+	})
+}
+
+func TestSharedGitHubRateLimiter(t *testing.T) {
+	t.Run("per token", func(t *testing.T) {
+		// when
+		first := sharedGitHubRateLimiter("token-1")
+		second := sharedGitHubRateLimiter("token-1")
+		other := sharedGitHubRateLimiter("token-2")
+
+		// then
+		assert.Same(t, first, second)
+		assert.NotSame(t, first, other)
+		gitHubRateLimitersLock.Lock()
+		defer gitHubRateLimitersLock.Unlock()
+		assert.NotContains(t, gitHubRateLimiters, "token-1")
+		assert.Contains(t, gitHubRateLimiters, hashToken("token-1"))
+	})
+
+	t.Run("least recently used is evicted", func(t *testing.T) {
+		// given
+		gitHubRateLimitersLock.Lock()
+		gitHubRateLimiters = map[string]*sharedRateLimiter{}
+		gitHubRateLimitersLock.Unlock()
+		first := sharedGitHubRateLimiter("first-token")
+		time.Sleep(time.Millisecond) // the other tokens are used more recently
+
+		// when
+		for i := 0; i < maxGitHubRateLimiters; i++ {
+			sharedGitHubRateLimiter("token-" + strconv.Itoa(i))
+		}
+
+		// then
+		gitHubRateLimitersLock.Lock()
+		assert.Len(t, gitHubRateLimiters, maxGitHubRateLimiters)
+		gitHubRateLimitersLock.Unlock()
+		assert.NotSame(t, first, sharedGitHubRateLimiter("first-token"))
+	})
+}
@@ -0,0 +1,210 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	errs "github.com/pkg/errors"
+)
+
+const (
+	dockerHubRegistry = "docker.io"
+	// dockerHubRegistryHost the host serving the distribution API of Docker Hub
+	dockerHubRegistryHost = "registry-1.docker.io"
+)
+
+// manifestMediaTypes the media types of the manifests accepted when resolving a tag, so that the registry returns
+// the digest of the image index (or manifest list) for multi-arch images, as reported in the status of the pods
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// ImageReference a reference to a tagged container image, eg, `quay.io/codeready-toolchain/host-operator:latest`
+type ImageReference struct {
+	// Registry the host (and port) of the registry, eg, `quay.io`
+	Registry string
+	// Repository the name of the repository in the registry, eg, `codeready-toolchain/host-operator`
+	Repository string
+	// Tag the tag of the image, eg, `latest`
+	Tag string
+}
+
+// ParseImageReference parses the given tagged image reference. The registry defaults to Docker Hub and the tag to `latest`.
+// References by digest are not supported since they don't point to the latest version of an image.
+func ParseImageReference(image string) (ImageReference, error) {
+	if image == "" {
+		return ImageReference{}, fmt.Errorf("image reference is empty")
+	}
+	if strings.Contains(image, "@") {
+		return ImageReference{}, fmt.Errorf("image reference '%s' must use a tag rather than a digest", image)
+	}
+	ref := ImageReference{
+		Registry: dockerHubRegistry,
+		Tag:      "latest",
+	}
+	name := image
+	if registry, remainder, found := strings.Cut(image, "/"); found && (strings.ContainsAny(registry, ".:") || registry == "localhost") {
+		ref.Registry = registry
+		name = remainder
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+	}
+	if ref.Registry == dockerHubRegistry && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	ref.Repository = name
+	return ref, nil
+}
+
+func (r ImageReference) String() string {
+	return fmt.Sprintf("%s/%s:%s", r.Registry, r.Repository, r.Tag)
+}
+
+// DigestFromImageID returns the digest of the given image ID, as reported in the status of the containers of a pod
+// (eg, `quay.io/codeready-toolchain/host-operator@sha256:...` or `docker-pullable://...@sha256:...`)
+func DigestFromImageID(imageID string) string {
+	if _, digest, found := strings.Cut(imageID, "@"); found {
+		return digest
+	}
+	return imageID
+}
+
+// ImageDigestSource retrieves the digest which a tag of an image currently points to
+type ImageDigestSource interface {
+	Digest(ctx context.Context, ref ImageReference) (string, error)
+}
+
+// RegistryClient retrieves the digests of the images using the OCI distribution API.
+// It supports anonymous access and the bearer token authentication used by the public registries (eg, Quay or Docker Hub).
+type RegistryClient struct {
+	// Username and Password the credentials used to get the access tokens, if not empty
+	Username, Password string
+	// PlainHTTP uses HTTP instead of HTTPS to call the registry, eg, for a local registry
+	PlainHTTP bool
+	// HTTPClient the client used to call the registry, http.DefaultClient if nil
+	HTTPClient *http.Client
+}
+
+// Digest returns the digest of the manifest which the tag of the given image points to
+func (c *RegistryClient) Digest(ctx context.Context, ref ImageReference) (string, error) {
+	resp, err := c.headManifest(ctx, ref, "")
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := c.token(ctx, resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return "", errs.Wrapf(err, "unable to authenticate to the registry of the '%s' image", ref)
+		}
+		if resp, err = c.headManifest(ctx, ref, token); err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("invalid response code from the image registry. resp.Response.StatusCode: %d, image: %s", resp.StatusCode, ref)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("no digest returned. image: %s", ref)
+	}
+	return digest, nil
+}
+
+func (c *RegistryClient) headManifest(ctx context.Context, ref ImageReference, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, fmt.Sprintf("%s/v2/%s/manifests/%s", c.baseURL(ref), ref.Repository, ref.Tag), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	return httpClientOrDefault(c.HTTPClient).Do(req)
+}
+
+func (c *RegistryClient) baseURL(ref ImageReference) string {
+	scheme := "https"
+	if c.PlainHTTP {
+		scheme = "http"
+	}
+	host := ref.Registry
+	if host == dockerHubRegistry {
+		host = dockerHubRegistryHost
+	}
+	return scheme + "://" + host
+}
+
+// token retrieves an access token from the authorization server specified in the given `WWW-Authenticate` challenge,
+// eg: `Bearer realm="https://quay.io/v2/auth",service="quay.io",scope="repository:codeready-toolchain/host-operator:pull"`
+func (c *RegistryClient) token(ctx context.Context, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("unsupported authentication challenge '%s'", challenge)
+	}
+	values := url.Values{}
+	var realm string
+	for _, param := range strings.Split(params, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found {
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		if key == "realm" {
+			realm = value
+			continue
+		}
+		values.Set(key, value)
+	}
+	if realm == "" {
+		return "", fmt.Errorf("no realm in the authentication challenge '%s'", challenge)
+	}
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return "", errs.Wrapf(err, "invalid realm in the authentication challenge '%s'", challenge)
+	}
+	// the realm may already have a query
+	query := tokenURL.Query()
+	for key := range values {
+		query.Set(key, values.Get(key))
+	}
+	tokenURL.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	resp, err := httpClientOrDefault(c.HTTPClient).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("invalid response code from the authorization server. resp.Response.StatusCode: %d", resp.StatusCode)
+	}
+	result := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", errs.Wrap(err, "unable to decode the response of the authorization server")
+	}
+	if result.Token != "" {
+		return result.Token, nil
+	}
+	return result.AccessToken, nil
+}
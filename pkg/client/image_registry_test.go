@@ -0,0 +1,137 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/codeready-toolchain/toolchain-common/pkg/client"
+	"github.com/codeready-toolchain/toolchain-common/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImageReference(t *testing.T) {
+	t.Run("valid references", func(t *testing.T) {
+		for image, expected := range map[string]client.ImageReference{
+			"quay.io/codeready-toolchain/host-operator:v1.2": {Registry: "quay.io", Repository: "codeready-toolchain/host-operator", Tag: "v1.2"},
+			"quay.io/codeready-toolchain/host-operator":      {Registry: "quay.io", Repository: "codeready-toolchain/host-operator", Tag: "latest"},
+			"localhost:5000/host-operator:dev":               {Registry: "localhost:5000", Repository: "host-operator", Tag: "dev"},
+			"codeready-toolchain/host-operator:v1":           {Registry: "docker.io", Repository: "codeready-toolchain/host-operator", Tag: "v1"},
+			"busybox":                                        {Registry: "docker.io", Repository: "library/busybox", Tag: "latest"},
+		} {
+			t.Run(image, func(t *testing.T) {
+				// when
+				ref, err := client.ParseImageReference(image)
+
+				// then
+				require.NoError(t, err)
+				assert.Equal(t, expected, ref)
+			})
+		}
+	})
+
+	t.Run("invalid references", func(t *testing.T) {
+		for image, expectedErr := range map[string]string{
+			"": "image reference is empty",
+			"quay.io/codeready-toolchain/host-operator@sha256:1234": "image reference 'quay.io/codeready-toolchain/host-operator@sha256:1234' must use a tag rather than a digest",
+		} {
+			t.Run(image, func(t *testing.T) {
+				// when
+				_, err := client.ParseImageReference(image)
+
+				// then
+				require.EqualError(t, err, expectedErr)
+			})
+		}
+	})
+}
+
+func TestDigestFromImageID(t *testing.T) {
+	assert.Equal(t, "sha256:1234", client.DigestFromImageID("quay.io/codeready-toolchain/host-operator@sha256:1234"))
+	assert.Equal(t, "sha256:1234", client.DigestFromImageID("docker-pullable://quay.io/codeready-toolchain/host-operator@sha256:1234"))
+	assert.Equal(t, "sha256:1234", client.DigestFromImageID("sha256:1234"))
+}
+
+func TestRegistryClientDigest(t *testing.T) {
+	// given
+	registry := test.NewFakeRegistry(t)
+	registry.SetDigest("codeready-toolchain/host-operator", "latest", "sha256:1234")
+	registryClient := &client.RegistryClient{PlainHTTP: true}
+	ref, err := client.ParseImageReference(registry.Host() + "/codeready-toolchain/host-operator:latest")
+	require.NoError(t, err)
+
+	t.Run("anonymous access", func(t *testing.T) {
+		// when
+		digest, err := registryClient.Digest(context.TODO(), ref)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:1234", digest)
+	})
+
+	t.Run("unknown tag", func(t *testing.T) {
+		// given
+		unknown := ref
+		unknown.Tag = "unknown"
+
+		// when
+		_, err := registryClient.Digest(context.TODO(), unknown)
+
+		// then
+		require.EqualError(t, err, "invalid response code from the image registry. resp.Response.StatusCode: 404, image: "+unknown.String())
+	})
+
+	t.Run("token authentication", func(t *testing.T) {
+		// given
+		registry.RequireToken("secret-token")
+		registry.SetDigest("codeready-toolchain/host-operator", "latest", "sha256:5678")
+
+		// when
+		digest, err := registryClient.Digest(context.TODO(), ref)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:5678", digest)
+	})
+}
+
+func TestRegistryClientDigestWithRealmQuery(t *testing.T) {
+	// given
+	var tokenQuery url.Values
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer secret-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token?account=robot",service="fake-registry",scope="repository:org/image:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", "sha256:1234")
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, req *http.Request) {
+		tokenQuery = req.URL.Query()
+		_, _ = w.Write([]byte(`{"token": "secret-token"}`))
+	})
+	ref, err := client.ParseImageReference(strings.TrimPrefix(server.URL, "http://") + "/org/image:latest")
+	require.NoError(t, err)
+
+	// when
+	digest, err := (&client.RegistryClient{PlainHTTP: true}).Digest(context.TODO(), ref)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:1234", digest)
+	assert.Equal(t, url.Values{
+		"account": {"robot"},
+		"service": {"fake-registry"},
+		"scope":   {"repository:org/image:pull"},
+	}, tokenQuery)
+}
@@ -5,29 +5,52 @@ import (
 
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
 )
 
+// Updater adds or updates the conditions with the time of its clock, eg, a fake clock in tests.
+// The package-level funcs, as well as the zero value, use the real clock.
+type Updater struct {
+	clock clock.PassiveClock
+}
+
+// WithClock returns an Updater which sets the timestamps of the conditions with the time of the given clock,
+// or with the time of the real clock if nil
+func WithClock(c clock.PassiveClock) Updater {
+	return Updater{clock: c}
+}
+
 // AddOrUpdateStatusConditions appends the new conditions to the condition slice. If there is already a condition
 // with the same type in the current condition array then the condition is updated in the result slice.
 // If the condition is not changed then the same unmodified slice is returned.
 // Also returns a bool flag which indicates if the conditions where updated/added
 func AddOrUpdateStatusConditions(conditions []toolchainv1alpha1.Condition, newConditions ...toolchainv1alpha1.Condition) ([]toolchainv1alpha1.Condition, bool) {
-	return addOrUpdateStatusConditions(conditions, false, newConditions...)
+	return WithClock(nil).AddOrUpdateStatusConditions(conditions, newConditions...)
+}
+
+// AddOrUpdateStatusConditions same as the AddOrUpdateStatusConditions func, using the clock of the Updater
+func (u Updater) AddOrUpdateStatusConditions(conditions []toolchainv1alpha1.Condition, newConditions ...toolchainv1alpha1.Condition) ([]toolchainv1alpha1.Condition, bool) {
+	return u.addOrUpdateStatusConditions(conditions, false, newConditions...)
 }
 
 // AddOrUpdateStatusConditionsWithLastUpdatedTimestamp appends the new conditions to the condition slice. If there is already a condition
 // with the same type in the current condition array then the condition is updated in the result slice.
 // The condition's LastUpdatedTime is always updated to the current time even if nothing else is changed.
 func AddOrUpdateStatusConditionsWithLastUpdatedTimestamp(conditions []toolchainv1alpha1.Condition, newConditions ...toolchainv1alpha1.Condition) []toolchainv1alpha1.Condition {
-	cs, _ := addOrUpdateStatusConditions(conditions, true, newConditions...)
+	return WithClock(nil).AddOrUpdateStatusConditionsWithLastUpdatedTimestamp(conditions, newConditions...)
+}
+
+// AddOrUpdateStatusConditionsWithLastUpdatedTimestamp same as the AddOrUpdateStatusConditionsWithLastUpdatedTimestamp func, using the clock of the Updater
+func (u Updater) AddOrUpdateStatusConditionsWithLastUpdatedTimestamp(conditions []toolchainv1alpha1.Condition, newConditions ...toolchainv1alpha1.Condition) []toolchainv1alpha1.Condition {
+	cs, _ := u.addOrUpdateStatusConditions(conditions, true, newConditions...)
 	return cs
 }
 
-func addOrUpdateStatusConditions(conditions []toolchainv1alpha1.Condition, updateLastUpdatedTimestamp bool, newConditions ...toolchainv1alpha1.Condition) ([]toolchainv1alpha1.Condition, bool) {
+func (u Updater) addOrUpdateStatusConditions(conditions []toolchainv1alpha1.Condition, updateLastUpdatedTimestamp bool, newConditions ...toolchainv1alpha1.Condition) ([]toolchainv1alpha1.Condition, bool) {
 	var atLeastOneUpdated bool
 	var updated bool
 	for _, cond := range newConditions {
-		conditions, updated = addOrUpdateStatusCondition(conditions, cond, updateLastUpdatedTimestamp)
+		conditions, updated = u.addOrUpdateStatusCondition(conditions, cond, updateLastUpdatedTimestamp)
 		atLeastOneUpdated = atLeastOneUpdated || updated
 	}
 
@@ -37,9 +60,14 @@ func addOrUpdateStatusConditions(conditions []toolchainv1alpha1.Condition, updat
 // AddStatusConditions adds the given conditions *without* checking for duplicate types (as opposed to `AddOrUpdateStatusConditions`)
 // Also, it sets the `LastTransitionTime` to `metav1.Now()` for each given condition if needed
 func AddStatusConditions(conditions []toolchainv1alpha1.Condition, newConditions ...toolchainv1alpha1.Condition) []toolchainv1alpha1.Condition {
+	return WithClock(nil).AddStatusConditions(conditions, newConditions...)
+}
+
+// AddStatusConditions same as the AddStatusConditions func, using the clock of the Updater
+func (u Updater) AddStatusConditions(conditions []toolchainv1alpha1.Condition, newConditions ...toolchainv1alpha1.Condition) []toolchainv1alpha1.Condition {
 	for _, cond := range newConditions {
 		if cond.LastTransitionTime.IsZero() {
-			cond.LastTransitionTime = metav1.Now()
+			cond.LastTransitionTime = u.now()
 		}
 		conditions = append(conditions, cond)
 	}
@@ -106,8 +134,16 @@ func Count(conditions []toolchainv1alpha1.Condition, conditionType toolchainv1al
 	return count
 }
 
-func addOrUpdateStatusCondition(conditions []toolchainv1alpha1.Condition, newCondition toolchainv1alpha1.Condition, updateLastUpdatedTimestamp bool) ([]toolchainv1alpha1.Condition, bool) {
-	now := metav1.Now()
+func (u Updater) now() metav1.Time {
+	if u.clock == nil {
+		// zero value of the Updater
+		return metav1.Now()
+	}
+	return metav1.NewTime(u.clock.Now())
+}
+
+func (u Updater) addOrUpdateStatusCondition(conditions []toolchainv1alpha1.Condition, newCondition toolchainv1alpha1.Condition, updateLastUpdatedTimestamp bool) ([]toolchainv1alpha1.Condition, bool) {
+	now := u.now()
 	newCondition.LastTransitionTime = now
 	if updateLastUpdatedTimestamp {
 		newCondition.LastUpdatedTime = &now
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
 )
 
 func TestAddOrUpdateStatusConditions(t *testing.T) {
@@ -146,6 +147,90 @@ func TestAddOrUpdateStatusConditions(t *testing.T) {
 	})
 }
 
+func TestUpdaterWithClock(t *testing.T) {
+	// given
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := clocktesting.NewFakePassiveClock(now)
+	updater := condition.WithClock(clock)
+
+	t.Run("add or update", func(t *testing.T) {
+		// when
+		result, updated := updater.AddOrUpdateStatusConditions(nil, newConditions(1)...)
+
+		// then
+		assert.True(t, updated)
+		require.Len(t, result, 1)
+		assert.Equal(t, metav1.NewTime(now), result[0].LastTransitionTime)
+		assert.Nil(t, result[0].LastUpdatedTime)
+
+		t.Run("keep the transition time when the status doesn't change", func(t *testing.T) {
+			// given
+			clock.SetTime(now.Add(time.Minute))
+			cond := result[0]
+			cond.Message = "changed"
+
+			// when
+			result, updated := updater.AddOrUpdateStatusConditions(result, cond)
+
+			// then
+			assert.True(t, updated)
+			assert.Equal(t, metav1.NewTime(now), result[0].LastTransitionTime)
+		})
+
+		t.Run("set the transition time when the status changes", func(t *testing.T) {
+			// given
+			clock.SetTime(now.Add(2 * time.Minute))
+			cond := result[0]
+			cond.Status = reverseStatus(cond.Status)
+
+			// when
+			result, updated := updater.AddOrUpdateStatusConditions(result, cond)
+
+			// then
+			assert.True(t, updated)
+			assert.Equal(t, metav1.NewTime(now.Add(2*time.Minute)), result[0].LastTransitionTime)
+		})
+	})
+
+	t.Run("with LastUpdatedTime", func(t *testing.T) {
+		// given
+		clock.SetTime(now)
+		current := updater.AddOrUpdateStatusConditionsWithLastUpdatedTimestamp(nil, newConditions(1)...)
+		clock.SetTime(now.Add(time.Minute))
+
+		// when
+		result := updater.AddOrUpdateStatusConditionsWithLastUpdatedTimestamp(current, current[0])
+
+		// then
+		require.Len(t, result, 1)
+		assert.Equal(t, metav1.NewTime(now), result[0].LastTransitionTime)
+		require.NotNil(t, result[0].LastUpdatedTime)
+		assert.Equal(t, metav1.NewTime(now.Add(time.Minute)), *result[0].LastUpdatedTime)
+	})
+
+	t.Run("add", func(t *testing.T) {
+		// given
+		clock.SetTime(now)
+
+		// when
+		result := updater.AddStatusConditions(nil, newConditions(2)...)
+
+		// then
+		require.Len(t, result, 2)
+		for _, c := range result {
+			assert.Equal(t, metav1.NewTime(now), c.LastTransitionTime)
+		}
+	})
+
+	t.Run("real clock when nil", func(t *testing.T) {
+		// when
+		result := condition.WithClock(nil).AddOrUpdateStatusConditionsWithLastUpdatedTimestamp(nil, newConditions(1)...)
+
+		// then
+		test.AssertConditionsMatchAndRecentTimestamps(t, result, newConditions(1)...)
+	})
+}
+
 func TestAddStatusConditions(t *testing.T) {
 
 	t.Run("without duplicate types", func(t *testing.T) {
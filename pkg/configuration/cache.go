@@ -4,29 +4,61 @@ import (
 	"context"
 	"sync"
 
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+
 	errs "github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 var configCache = &cache{}
 
+// configScheme the scheme of the configuration resources, used to find the kind of the resource of the default cache
+var configScheme = newConfigScheme()
+
+func newConfigScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	utilruntime.Must(toolchainv1alpha1.AddToScheme(s))
+	return s
+}
+
 var cacheLog = logf.Log.WithName("cache_toolchainconfig")
 
 type cache struct {
 	sync.RWMutex
-	configObj runtime.Object
-	secrets   map[string]map[string]string // map of secret key-value pairs indexed by secret name
+	configObj     runtime.Object
+	secrets       map[string]map[string]string // map of secret key-value pairs indexed by secret name
+	subscriptions subscriptions
 }
 
 func (c *cache) set(config runtime.Object, secrets map[string]map[string]string) {
 	c.Lock()
-	defer c.Unlock()
-	c.configObj = config.DeepCopyObject()
-	c.secrets = CopyOf(secrets)
+	change := ConfigChange{
+		Old:        c.configObj,
+		OldSecrets: c.secrets,
+		New:        config.DeepCopyObject(),
+		NewSecrets: CopyOf(secrets),
+	}
+	c.configObj = change.New
+	c.secrets = change.NewSecrets
+	c.Unlock()
+
+	// the listeners are notified outside of the lock, so that they can read the cache
+	diff, err := Diff(change.Old, change.OldSecrets, change.New, change.NewSecrets)
+	if err != nil {
+		cacheLog.Error(err, "unable to compute the changes in the configuration, the subscribers are not notified")
+		return
+	}
+	if diff.IsEmpty() {
+		return
+	}
+	change.Diff = diff
+	c.subscriptions.notify(change)
 }
 
 func (c *cache) get() (runtime.Object, map[string]map[string]string) {
@@ -39,33 +71,54 @@ func (c *cache) get() (runtime.Object, map[string]map[string]string) {
 }
 
 func UpdateConfig(config runtime.Object, secrets map[string]map[string]string) {
+	bindDefaultKind(config, configScheme)
 	configCache.set(config, secrets)
 }
 
-// loadLatest retrieves the latest configuration object and secrets using the provided client and updates the cache.
+// bindDefaultKind binds the default cache to the kind of the given configuration resource, unless it is already bound to a kind
+func bindDefaultKind(config runtime.Object, s *runtime.Scheme) {
+	if gvk, err := apiutil.GVKForObject(config, s); err == nil {
+		caches.setDefaultKind(gvk)
+	}
+}
+
+// loadLatest retrieves the latest configuration object and the secrets it references using the provided client and updates the cache.
 // If the resource is not found, then returns nil for the configuration and secret.
 // If any failure happens while getting the configuration object or secrets, then returns an error.
 func LoadLatest(cl client.Client, configObj client.Object) (runtime.Object, map[string]map[string]string, error) {
-	namespace, err := GetWatchNamespace()
+	name, err := defaultCacheName()
 	if err != nil {
 		return nil, nil, errs.Wrap(err, "failed to get watch namespace")
 	}
+	bindDefaultKind(configObj, cl.Scheme())
+	return loadLatest(cl, name, configObj, configCache)
+}
+
+// defaultCacheName returns the name of the configuration resource of the default cache, ie, `config` in the watch namespace
+func defaultCacheName() (types.NamespacedName, error) {
+	namespace, err := GetWatchNamespace()
+	if err != nil {
+		return types.NamespacedName{}, err
+	}
+	return types.NamespacedName{Namespace: namespace, Name: "config"}, nil
+}
 
-	if err := cl.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: "config"}, configObj); err != nil {
+func loadLatest(cl client.Client, name types.NamespacedName, configObj client.Object, target *cache) (runtime.Object, map[string]map[string]string, error) {
+	if err := cl.Get(context.TODO(), name, configObj); err != nil {
 		if apierrors.IsNotFound(err) {
-			cacheLog.Info("ToolchainConfig resource with the name 'config' wasn't found, default configuration will be used", "namespace", namespace)
+			cacheLog.Info("configuration resource wasn't found, default configuration will be used", "namespace", name.Namespace, "name", name.Name)
 			return nil, nil, nil
 		}
 		return nil, nil, err
 	}
 
-	allSecrets, err := LoadSecrets(cl, namespace)
+	secrets, err := LoadReferencedSecrets(cl, name.Namespace, SecretRefs(configObj))
 	if err != nil {
 		return nil, nil, err
 	}
 
-	configCache.set(configObj, allSecrets)
-	configCopy, secretsCopy := configCache.get()
+	target.set(configObj, secrets)
+	configCopy, secretsCopy := target.get()
 	return configCopy, secretsCopy, nil
 }
 
@@ -82,14 +135,16 @@ func GetConfig(cl client.Client, configObj client.Object) (runtime.Object, map[s
 	return config, secrets, nil
 }
 
-// getCachedConfig returns the cached toolchainconfig or a toolchainconfig with default values
+// getCachedConfig returns the cached toolchainconfig or a toolchainconfig with default values.
+// It is a shortcut for the default cache; use CacheFor to cache several configuration resources.
 func GetCachedConfig() (runtime.Object, map[string]map[string]string) {
 	return configCache.get()
 }
 
-// Reset resets the cache.
+// Reset resets the default cache and the keyed caches, and removes all the subscriptions.
 // Should be used only in tests, but since it has to be used in other packages,
 // then the function has to be exported and placed here.
 func ResetCache() {
 	configCache = &cache{}
+	caches = &registry{}
 }
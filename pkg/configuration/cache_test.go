@@ -145,18 +145,21 @@ func TestGetConfigFailed(t *testing.T) {
 	})
 
 	t.Run("load secrets error", func(t *testing.T) {
-		config := NewToolchainConfigObjWithReset(t)
+		config := NewToolchainConfigObjWithReset(t, testconfig.Notifications().Secret().Ref("notification-secret"))
 		// given
 		cl := test.NewFakeClient(t, config)
-		cl.MockList = func(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
-			return fmt.Errorf("list error")
+		cl.MockGet = func(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if _, ok := obj.(*v1.Secret); ok {
+				return fmt.Errorf("get secret error")
+			}
+			return cl.Client.Get(ctx, key, obj, opts...)
 		}
 
 		// when
 		actual, secrets, err := LoadLatest(cl, &toolchainv1alpha1.ToolchainConfig{})
 
 		// then
-		require.EqualError(t, err, "list error")
+		require.EqualError(t, err, "get secret error")
 		assert.Nil(t, actual)
 		assert.Empty(t, secrets)
 	})
@@ -199,7 +202,7 @@ func TestLoadLatest(t *testing.T) {
 	restore := test.SetEnvVarAndRestore(t, "WATCH_NAMESPACE", test.HostOperatorNs)
 	defer restore()
 	t.Run("config found", func(t *testing.T) {
-		initConfig := NewToolchainConfigObjWithReset(t, testconfig.AutomaticApproval().Enabled(true))
+		initConfig := NewToolchainConfigObjWithReset(t, testconfig.AutomaticApproval().Enabled(true), testconfig.Notifications().Secret().Ref("notification-secret"))
 		initSecret := &v1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "notification-secret",
@@ -293,18 +296,21 @@ func TestLoadLatest(t *testing.T) {
 	})
 
 	t.Run("load secrets error", func(t *testing.T) {
-		initconfig := NewToolchainConfigObjWithReset(t)
+		initconfig := NewToolchainConfigObjWithReset(t, testconfig.Notifications().Secret().Ref("notification-secret"))
 		// given
 		cl := test.NewFakeClient(t, initconfig)
-		cl.MockList = func(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
-			return fmt.Errorf("list error")
+		cl.MockGet = func(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if _, ok := obj.(*v1.Secret); ok {
+				return fmt.Errorf("get secret error")
+			}
+			return cl.Client.Get(ctx, key, obj, opts...)
 		}
 
 		// when
 		actual, secrets, err := LoadLatest(cl, &toolchainv1alpha1.ToolchainConfig{})
 
 		// then
-		require.EqualError(t, err, "list error")
+		require.EqualError(t, err, "get secret error")
 		assert.Nil(t, actual)
 		assert.Empty(t, secrets)
 	})
@@ -319,7 +325,7 @@ func TestMultipleExecutionsInParallel(t *testing.T) {
 	var waitForFinished sync.WaitGroup
 	initconfig := NewToolchainConfigObjWithReset(t, testconfig.Members().SpecificPerMemberCluster("member", toolchainv1alpha1.MemberOperatorConfigSpec{
 		Environment: ptr.To("env"),
-	}))
+	}), testconfig.Notifications().Secret().Ref("notification-secret"))
 
 	secret := &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
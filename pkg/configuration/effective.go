@@ -0,0 +1,263 @@
+package configuration
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Source the origin of the value of a setting in an effective configuration
+type Source string
+
+const (
+	// SourceCR the value is set in the configuration resource
+	SourceCR Source = "CR"
+	// SourceDefault the value is not set in the configuration resource, so the default value applies
+	SourceDefault Source = "default"
+	// SourceSecret the value is read from a key of a secret referenced in the configuration resource (or of the Secret of an Overlay)
+	SourceSecret Source = "secret"
+)
+
+// Setting a setting of an effective configuration
+type Setting struct {
+	// Path the path of the setting in the spec of the configuration resource, with the fields separated by dots
+	Path string `json:"path"`
+	// Value the effective value of the setting, which is Sensitive when the Source is SourceSecret
+	Value interface{} `json:"value"`
+	// Source where the effective value comes from
+	Source Source `json:"source"`
+	// Default the default value of the setting, if any
+	Default interface{} `json:"default,omitempty"`
+	// Invalid the value set in the configuration resource which was rejected by the validation, if any.
+	// In that case, the default value applies.
+	Invalid interface{} `json:"invalid,omitempty"`
+}
+
+// EffectiveConfig lists all the settings of a configuration resource, along with their effective value and where it comes from
+type EffectiveConfig struct {
+	Settings []Setting `json:"settings"`
+}
+
+// Get returns the setting with the given path
+func (c *EffectiveConfig) Get(path string) (Setting, bool) {
+	for _, s := range c.Settings {
+		if s.Path == path {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+// MarkInvalid replaces the values set in the configuration resource which are rejected by the given validation errors
+// (eg, a `memberStatus.refreshPeriod` which is not a valid duration) with their default value, since this is the value
+// returned by the getters of the configuration. The rejected value is kept in the Invalid field of the setting.
+// Only the errors of the `Invalid` type apply, whose path is expected to start with `spec`.
+func (c *EffectiveConfig) MarkInvalid(errs field.ErrorList) {
+	for _, err := range errs {
+		if err.Type != field.ErrorTypeInvalid {
+			continue
+		}
+		path := settingPath(err.Field)
+		for i := range c.Settings {
+			s := &c.Settings[i]
+			if s.Path != path || s.Source != SourceCR {
+				continue
+			}
+			s.Invalid = s.Value
+			s.Value = s.Default
+			s.Source = SourceDefault
+		}
+	}
+}
+
+// settingPath converts the given field path (eg, `spec.members.specificPerMemberCluster[member-1].environment`)
+// into the path of a setting (eg, `members.specificPerMemberCluster.member-1.environment`)
+func settingPath(fieldPath string) string {
+	path := strings.TrimPrefix(fieldPath, "spec.")
+	path = strings.ReplaceAll(path, "[", ".")
+	return strings.ReplaceAll(path, "]", "")
+}
+
+// WriteJSON writes the effective configuration as indented JSON, with the secret values redacted
+func (c *EffectiveConfig) WriteJSON(w io.Writer) error {
+	return WriteEffectiveConfig(w, c)
+}
+
+// WriteYAML writes the effective configuration as YAML, with the secret values redacted
+func (c *EffectiveConfig) WriteYAML(w io.Writer) error {
+	out, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// PrefixDefaults returns a copy of the given default values, with the given prefix added to their paths.
+// For example, to use the defaults of a MemberOperatorConfig for the member configs of a ToolchainConfig, use the
+// `members.default` and `members.specificPerMemberCluster.*` prefixes.
+func PrefixDefaults(prefix string, defaults map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(defaults))
+	for path, value := range defaults {
+		result[prefix+"."+path] = value
+	}
+	return result
+}
+
+// NewEffectiveConfig lists all the settings of the given spec (a pointer to the spec of a configuration resource),
+// along with their effective value and its source, based on the given default values and secrets (indexed by secret name).
+//
+// The default values are indexed by the path of their setting (eg, `toolchainCluster.healthCheckPeriod`),
+// in which a `*` segment matches any key of a map (eg, `members.specificPerMemberCluster.*.environment`).
+// The keys of secrets (eg, `memberStatus.gitHubSecret.accessTokenKey`) are resolved from the secret referenced
+// in the same section, and listed with their Sensitive value.
+func NewEffectiveConfig[V string | Sensitive](spec interface{}, defaults map[string]interface{}, secrets map[string]map[string]V) (*EffectiveConfig, error) {
+	value := reflect.ValueOf(spec)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a pointer to a struct but got %T", spec)
+	}
+	e := effectiveConfigBuilder{
+		defaults: defaults,
+		secrets:  make(map[string]map[string]Sensitive, len(secrets)),
+	}
+	for name, data := range secrets {
+		e.secrets[name] = make(map[string]Sensitive, len(data))
+		for k, v := range data {
+			e.secrets[name][k] = Sensitive(v)
+		}
+	}
+	e.walkStruct("", value.Elem())
+	return &EffectiveConfig{Settings: e.settings}, nil
+}
+
+type effectiveConfigBuilder struct {
+	defaults map[string]interface{}
+	secrets  map[string]map[string]Sensitive
+	settings []Setting
+}
+
+func (e *effectiveConfigBuilder) walkStruct(prefix string, value reflect.Value) {
+	// if the struct embeds a ToolchainSecret, then the values of the other fields are keys in the referenced secret
+	var secret *toolchainv1alpha1.ToolchainSecret
+	for i := 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).Type != toolchainSecretType {
+			continue
+		}
+		if s, ok := value.Field(i).Interface().(toolchainv1alpha1.ToolchainSecret); ok {
+			secret = &s
+		}
+	}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, inline := jsonName(field)
+		if name == "-" {
+			continue
+		}
+		if inline {
+			e.walk(prefix, value.Field(i), nil)
+			continue
+		}
+		e.walk(join(prefix, name), value.Field(i), secret)
+	}
+}
+
+func (e *effectiveConfigBuilder) walk(path string, value reflect.Value, secret *toolchainv1alpha1.ToolchainSecret) {
+	switch {
+	case value.Kind() == reflect.Ptr && value.Type().Elem().Kind() == reflect.Struct:
+		if value.IsNil() {
+			// list the settings of the missing section with their default values
+			e.walkStruct(path, reflect.New(value.Type().Elem()).Elem())
+			return
+		}
+		e.walkStruct(path, value.Elem())
+	case value.Kind() == reflect.Struct:
+		e.walkStruct(path, value)
+	case value.Kind() == reflect.Map && value.Type().Elem().Kind() == reflect.Struct:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+		for _, k := range keys {
+			e.walkStruct(join(path, k.String()), value.MapIndex(k))
+		}
+	default:
+		e.addSetting(path, value, secret)
+	}
+}
+
+func (e *effectiveConfigBuilder) addSetting(path string, value reflect.Value, secret *toolchainv1alpha1.ToolchainSecret) {
+	setting := Setting{
+		Path:    path,
+		Default: e.defaultValue(path),
+	}
+	switch {
+	case value.Kind() == reflect.Ptr && value.IsNil(), value.Kind() == reflect.Map && value.IsNil(), value.Kind() == reflect.Slice && value.IsNil():
+		setting.Value = setting.Default
+		setting.Source = SourceDefault
+	case secret != nil && value.Kind() == reflect.Ptr && value.Elem().Kind() == reflect.String:
+		// the value is a key in the referenced secret
+		setting.Value = e.secrets[GetString(secret.Ref, "")][value.Elem().String()]
+		setting.Source = SourceSecret
+	case value.Kind() == reflect.Ptr:
+		setting.Value = value.Elem().Interface()
+		setting.Source = SourceCR
+	default:
+		setting.Value = value.Interface()
+		setting.Source = SourceCR
+	}
+	e.settings = append(e.settings, setting)
+}
+
+func (e *effectiveConfigBuilder) defaultValue(path string) interface{} {
+	if value, found := e.defaults[path]; found {
+		return value
+	}
+	// look for a default value with wildcards, eg: `members.specificPerMemberCluster.*.environment`
+	segments := strings.Split(path, ".")
+	for pattern, value := range e.defaults {
+		patternSegments := strings.Split(pattern, ".")
+		if len(patternSegments) != len(segments) {
+			continue
+		}
+		matches := true
+		for i := range segments {
+			if patternSegments[i] != "*" && patternSegments[i] != segments[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return value
+		}
+	}
+	return nil
+}
+
+// jsonName returns the name of the given field in its JSON representation, and whether the field is inlined
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return field.Name, field.Anonymous
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return field.Name, field.Anonymous || strings.Contains(opts, "inline")
+	}
+	return name, false
+}
+
+func join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,212 @@
+package configuration
+
+import (
+	"bytes"
+	"testing"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	testconfig "github.com/codeready-toolchain/toolchain-common/pkg/test/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestNewEffectiveConfig(t *testing.T) {
+	// given
+	memberConfig := testconfig.NewMemberOperatorConfigObj(testconfig.MemberEnvironment("dev"))
+	config := testconfig.NewToolchainConfigObj(t,
+		testconfig.AutomaticApproval().Enabled(true),
+		testconfig.Notifications().Secret().Ref("notification-secret").MailgunAPIKey("mailgunAPIKey").MailgunDomain("mailgunDomain"),
+		testconfig.Members().SpecificPerMemberCluster("member-1", memberConfig.Spec))
+	defaults := map[string]interface{}{
+		"host.automaticApproval.enabled": false,
+		"host.environment":               "prod",
+	}
+	for path, value := range PrefixDefaults("members.specificPerMemberCluster.*", map[string]interface{}{"environment": "prod", "console.routeName": "console"}) {
+		defaults[path] = value
+	}
+	secrets := map[string]map[string]string{
+		"notification-secret": {
+			"mailgunAPIKey": "abc123",
+		},
+	}
+
+	// when
+	effective, err := NewEffectiveConfig(&config.Spec, defaults, secrets)
+
+	// then
+	require.NoError(t, err)
+
+	t.Run("value from CR", func(t *testing.T) {
+		setting, found := effective.Get("host.automaticApproval.enabled")
+		require.True(t, found)
+		assert.Equal(t, Setting{Path: "host.automaticApproval.enabled", Value: true, Source: SourceCR, Default: false}, setting)
+	})
+
+	t.Run("default value", func(t *testing.T) {
+		setting, found := effective.Get("host.environment")
+		require.True(t, found)
+		assert.Equal(t, Setting{Path: "host.environment", Value: "prod", Source: SourceDefault, Default: "prod"}, setting)
+	})
+
+	t.Run("no default value", func(t *testing.T) {
+		setting, found := effective.Get("host.tiers.defaultSpaceTier")
+		require.True(t, found)
+		assert.Equal(t, Setting{Path: "host.tiers.defaultSpaceTier", Source: SourceDefault}, setting)
+	})
+
+	t.Run("value from secret", func(t *testing.T) {
+		setting, found := effective.Get("host.notifications.secret.mailgunAPIKey")
+		require.True(t, found)
+		assert.Equal(t, Setting{Path: "host.notifications.secret.mailgunAPIKey", Value: Sensitive("abc123"), Source: SourceSecret}, setting)
+
+		setting, found = effective.Get("host.notifications.secret.mailgunDomain")
+		require.True(t, found)
+		assert.Equal(t, Setting{Path: "host.notifications.secret.mailgunDomain", Value: Sensitive(""), Source: SourceSecret}, setting)
+
+		setting, found = effective.Get("host.notifications.secret.ref")
+		require.True(t, found)
+		assert.Equal(t, Setting{Path: "host.notifications.secret.ref", Value: "notification-secret", Source: SourceCR}, setting)
+	})
+
+	t.Run("member configs", func(t *testing.T) {
+		setting, found := effective.Get("members.specificPerMemberCluster.member-1.environment")
+		require.True(t, found)
+		assert.Equal(t, Setting{Path: "members.specificPerMemberCluster.member-1.environment", Value: "dev", Source: SourceCR, Default: "prod"}, setting)
+
+		setting, found = effective.Get("members.specificPerMemberCluster.member-1.console.routeName")
+		require.True(t, found)
+		assert.Equal(t, Setting{Path: "members.specificPerMemberCluster.member-1.console.routeName", Value: "console", Source: SourceDefault, Default: "console"}, setting)
+
+		// the settings of the missing sections are listed too
+		_, found = effective.Get("members.default.webhook.secret.virtualMachineAccessKey")
+		assert.True(t, found)
+	})
+
+	t.Run("unknown setting", func(t *testing.T) {
+		_, found := effective.Get("host.unknown")
+		assert.False(t, found)
+	})
+
+	t.Run("invalid spec", func(t *testing.T) {
+		_, err := NewEffectiveConfig[string](config.Spec, nil, nil)
+		require.EqualError(t, err, "expected a pointer to a struct but got v1alpha1.ToolchainConfigSpec")
+	})
+}
+
+func TestNewEffectiveConfigWithPopulatedSpec(t *testing.T) {
+	// given
+	weight := uint(10)
+	memberConfig := testconfig.NewMemberOperatorConfigObj(
+		testconfig.MemberEnvironment("dev"),
+		testconfig.MemberStatus().RefreshPeriod("1 minute"))
+	config := testconfig.NewToolchainConfigObj(t,
+		testconfig.Environment(testconfig.E2E),
+		testconfig.AutomaticApproval().Enabled(true).Domains("acme.com"),
+		testconfig.Deactivation().DeactivatingNotificationDays(5),
+		testconfig.Notifications().DurationBeforeNotificationDeletion("1 day").AdminEmail("admin@acme.com"),
+		testconfig.Notifications().Secret().Ref("notification-secret").MailgunAPIKey("mailgunAPIKey"),
+		testconfig.Tiers().DefaultSpaceTier("base").FeatureToggle("feature-1", &weight),
+		testconfig.Members().Default(memberConfig.Spec),
+		testconfig.Members().SpecificPerMemberCluster("member-1", memberConfig.Spec))
+	defaults := map[string]interface{}{
+		"host.environment":                                      "prod",
+		"host.automaticApproval.enabled":                        false,
+		"host.deactivation.deactivatingNotificationDays":        3,
+		"host.notifications.durationBeforeNotificationDeletion": "24h",
+		"host.tiers.defaultSpaceTier":                           "base1ns",
+	}
+	for _, prefix := range []string{"members.default", "members.specificPerMemberCluster.*"} {
+		for path, value := range PrefixDefaults(prefix, map[string]interface{}{"environment": "prod", "memberStatus.refreshPeriod": "5s"}) {
+			defaults[path] = value
+		}
+	}
+	secrets := map[string]map[string]string{
+		"notification-secret": {
+			"mailgunAPIKey": "abc123",
+		},
+	}
+
+	// when
+	effective, err := NewEffectiveConfig(&config.Spec, defaults, secrets)
+	require.NoError(t, err)
+	effective.MarkInvalid(field.ErrorList{
+		field.Invalid(field.NewPath("spec", "host", "notifications", "durationBeforeNotificationDeletion"), "1 day", "value is not a valid duration"),
+		field.Invalid(field.NewPath("spec", "members", "specificPerMemberCluster").Key("member-1").Child("memberStatus", "refreshPeriod"), "1 minute", "value is not a valid duration"),
+		// only the `Invalid` errors are applied
+		field.NotFound(field.NewPath("spec", "host", "notifications", "secret", "ref"), "notification-secret"),
+	})
+
+	// then
+	for path, expected := range map[string]Setting{
+		"host.environment":                               {Value: "e2e-tests", Source: SourceCR, Default: "prod"},
+		"host.automaticApproval.enabled":                 {Value: true, Source: SourceCR, Default: false},
+		"host.automaticApproval.domains":                 {Value: "acme.com", Source: SourceCR},
+		"host.deactivation.deactivatingNotificationDays": {Value: 5, Source: SourceCR, Default: 3},
+		"host.notifications.adminEmail":                  {Value: "admin@acme.com", Source: SourceCR},
+		"host.notifications.secret.ref":                  {Value: "notification-secret", Source: SourceCR},
+		"host.notifications.secret.mailgunAPIKey":        {Value: Sensitive("abc123"), Source: SourceSecret},
+		"host.tiers.defaultSpaceTier":                    {Value: "base", Source: SourceCR, Default: "base1ns"},
+		"host.tiers.featureToggles":                      {Value: []toolchainv1alpha1.FeatureToggle{{Name: "feature-1", Weight: &weight}}, Source: SourceCR},
+		// invalid values are replaced with the default value
+		"host.notifications.durationBeforeNotificationDeletion":                {Value: "24h", Source: SourceDefault, Default: "24h", Invalid: "1 day"},
+		"members.specificPerMemberCluster.member-1.memberStatus.refreshPeriod": {Value: "5s", Source: SourceDefault, Default: "5s", Invalid: "1 minute"},
+		// the same value is not marked as invalid in another section
+		"members.default.memberStatus.refreshPeriod":            {Value: "1 minute", Source: SourceCR, Default: "5s"},
+		"members.default.environment":                           {Value: "dev", Source: SourceCR, Default: "prod"},
+		"members.specificPerMemberCluster.member-1.environment": {Value: "dev", Source: SourceCR, Default: "prod"},
+	} {
+		t.Run(path, func(t *testing.T) {
+			expected.Path = path
+			setting, found := effective.Get(path)
+			require.True(t, found)
+			assert.Equal(t, expected, setting)
+		})
+	}
+}
+
+func TestWriteEffectiveConfig(t *testing.T) {
+	// given
+	effective := &EffectiveConfig{
+		Settings: []Setting{
+			{Path: "environment", Value: "dev", Source: SourceCR, Default: "prod"},
+			{Path: "memberStatus.gitHubSecret.accessTokenKey", Value: Sensitive("abc123"), Source: SourceSecret},
+		},
+	}
+
+	t.Run("json", func(t *testing.T) {
+		// given
+		buf := &bytes.Buffer{}
+
+		// when
+		err := effective.WriteJSON(buf)
+
+		// then
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"settings": [
+			{"path": "environment", "value": "dev", "source": "CR", "default": "prod"},
+			{"path": "memberStatus.gitHubSecret.accessTokenKey", "value": "[REDACTED]", "source": "secret"}
+		]}`, buf.String())
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		// given
+		buf := &bytes.Buffer{}
+
+		// when
+		err := effective.WriteYAML(buf)
+
+		// then
+		require.NoError(t, err)
+		assert.YAMLEq(t, `settings:
+- path: environment
+  value: dev
+  source: CR
+  default: prod
+- path: memberStatus.gitHubSecret.accessTokenKey
+  value: '[REDACTED]'
+  source: secret
+`, buf.String())
+	})
+}
@@ -0,0 +1,229 @@
+package configuration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/ghodss/yaml"
+	errs "github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// ConfigFileName the name of the file which contains the configuration resource in the directory of a FileSource
+	ConfigFileName = "config.yaml"
+	// SecretsDirName the name of the subdirectory which contains the secrets in the directory of a FileSource
+	SecretsDirName = "secrets"
+
+	// reloadDelay the delay between the first change of a file and the reload of the configuration,
+	// so that a set of changes (eg, the update of a mounted ConfigMap or Secret) triggers a single reload
+	reloadDelay = 100 * time.Millisecond
+)
+
+var fileLog = cacheLog.WithName("file")
+
+// FileSource loads the configuration from files on disk rather than from the cluster, and reloads it when the files change.
+// The directory of the source contains:
+//   - a `config.yaml` file with the configuration resource (eg, a ToolchainConfig or a MemberOperatorConfig)
+//   - a `secrets` subdirectory with a subdirectory per secret, which contains a file per key, ie, the same layout as
+//     a Secret mounted in a Pod (eg, `secrets/github/accessToken`)
+//
+// By default, the configuration is stored in the same cache as the one loaded from the cluster, so the subscribers
+// are notified of the changes in the files. Use the WithCache option to store it in the cache of a given key instead.
+type FileSource struct {
+	dir          string
+	newConfigObj func() runtime.Object
+	cache        *Cache
+}
+
+// FileSourceOption an option to configure a FileSource
+type FileSourceOption func(*FileSource)
+
+// WithCache stores the configuration loaded from the files in the given cache (eg, `CacheFor(key)`) instead of the default cache
+func WithCache(cache Cache) FileSourceOption {
+	return func(s *FileSource) {
+		s.cache = &cache
+	}
+}
+
+// NewFileSource returns a new FileSource which reads the configuration files in the given directory,
+// and decodes the configuration resource in an object created with the given func (eg, `&toolchainv1alpha1.ToolchainConfig{}`)
+func NewFileSource(dir string, newConfigObj func() runtime.Object, options ...FileSourceOption) *FileSource {
+	s := &FileSource{
+		dir:          dir,
+		newConfigObj: newConfigObj,
+	}
+	for _, apply := range options {
+		apply(s)
+	}
+	return s
+}
+
+// target returns the cache in which the configuration is stored
+func (s *FileSource) target() *cache {
+	if s.cache != nil {
+		return s.cache.entry()
+	}
+	return configCache
+}
+
+// Load reads the configuration resource and the secrets in the directory of the source and updates the cache.
+// If there is no configuration file, then returns nil for the configuration and secrets, and the cache is not updated.
+func (s *FileSource) Load() (runtime.Object, map[string]map[string]string, error) {
+	content, err := os.ReadFile(filepath.Join(s.dir, ConfigFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			fileLog.Info("configuration file wasn't found, default configuration will be used", "dir", s.dir)
+			return nil, nil, nil
+		}
+		return nil, nil, errs.Wrapf(err, "unable to read the configuration file in '%s'", s.dir)
+	}
+	config := s.newConfigObj()
+	if err := yaml.Unmarshal(content, config); err != nil {
+		return nil, nil, errs.Wrapf(err, "unable to decode the configuration file in '%s'", s.dir)
+	}
+	secrets, err := s.loadSecrets()
+	if err != nil {
+		return nil, nil, err
+	}
+	target := s.target()
+	if target == configCache {
+		bindDefaultKind(config, configScheme)
+	}
+	target.set(config, secrets)
+	configCopy, secretsCopy := target.get()
+	return configCopy, secretsCopy, nil
+}
+
+func (s *FileSource) loadSecrets() (map[string]map[string]string, error) {
+	secrets := map[string]map[string]string{}
+	secretDirs, err := s.secretDirs()
+	if err != nil {
+		return nil, err
+	}
+	for name, dir := range secretDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, errs.Wrapf(err, "unable to read the '%s' secret", name)
+		}
+		data := map[string]string{}
+		for _, entry := range entries {
+			if isHidden(entry.Name()) {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			// mounted secrets contain symlinks, so the entries have to be resolved
+			if info, err := os.Stat(path); err != nil || info.IsDir() {
+				continue
+			}
+			value, err := os.ReadFile(path)
+			if err != nil {
+				return nil, errs.Wrapf(err, "unable to read the '%s' key of the '%s' secret", entry.Name(), name)
+			}
+			data[entry.Name()] = string(value)
+		}
+		secrets[name] = data
+	}
+	return secrets, nil
+}
+
+// secretDirs returns the directories of the secrets indexed by secret name
+func (s *FileSource) secretDirs() (map[string]string, error) {
+	secretsDir := filepath.Join(s.dir, SecretsDirName)
+	entries, err := os.ReadDir(secretsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, errs.Wrapf(err, "unable to read the secrets in '%s'", secretsDir)
+	}
+	dirs := map[string]string{}
+	for _, entry := range entries {
+		if isHidden(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(secretsDir, entry.Name())
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			dirs[entry.Name()] = path
+		}
+	}
+	return dirs, nil
+}
+
+// isHidden returns `true` for the hidden files, such as the `..data` symlinks of the mounted volumes
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// Start loads the configuration, then watches the files of the source and reloads the configuration when they change,
+// until the given context is done. Errors while reloading are logged, and the previous configuration is kept in the cache.
+// It implements the controller-runtime `manager.Runnable` interface, so the source can be added to a manager.
+func (s *FileSource) Start(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errs.Wrap(err, "unable to create the file watcher")
+	}
+	defer watcher.Close()
+	if err := s.watch(watcher); err != nil {
+		return err
+	}
+	if _, _, err := s.Load(); err != nil {
+		return err
+	}
+
+	var reload <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			fileLog.V(1).Info("configuration file changed", "name", event.Name, "op", event.Op.String())
+			if reload == nil {
+				reload = time.After(reloadDelay)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fileLog.Error(err, "error while watching the configuration files", "dir", s.dir)
+		case <-reload:
+			reload = nil
+			// new secrets may have been added
+			if err := s.watch(watcher); err != nil {
+				fileLog.Error(err, "unable to watch the configuration files", "dir", s.dir)
+			}
+			if _, _, err := s.Load(); err != nil {
+				fileLog.Error(err, "unable to reload the configuration, keeping the previous one", "dir", s.dir)
+			}
+		}
+	}
+}
+
+// watch adds the directory of the source, its secrets directory and the directory of each secret to the given watcher.
+// Adding a directory which is already watched is a no-op.
+func (s *FileSource) watch(watcher *fsnotify.Watcher) error {
+	dirs := []string{s.dir}
+	if info, err := os.Stat(filepath.Join(s.dir, SecretsDirName)); err == nil && info.IsDir() {
+		dirs = append(dirs, filepath.Join(s.dir, SecretsDirName))
+	}
+	secretDirs, err := s.secretDirs()
+	if err != nil {
+		return err
+	}
+	for _, dir := range secretDirs {
+		dirs = append(dirs, dir)
+	}
+	for _, dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			return errs.Wrapf(err, "unable to watch '%s'", dir)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package configuration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	testconfig "github.com/codeready-toolchain/toolchain-common/pkg/test/config"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestFileSourceLoad(t *testing.T) {
+	newConfigObj := func() runtime.Object {
+		return &toolchainv1alpha1.ToolchainConfig{}
+	}
+
+	t.Run("config and secrets", func(t *testing.T) {
+		// given
+		t.Cleanup(ResetCache)
+		dir := t.TempDir()
+		config := testconfig.NewToolchainConfigObj(t, testconfig.Notifications().Secret().Ref("notifications").MailgunAPIKey("mailgunAPIKey"))
+		writeConfigFile(t, dir, config)
+		writeSecretFile(t, dir, "notifications", "mailgunAPIKey", "abc")
+		writeSecretFile(t, dir, "notifications", "..data", "ignored")
+
+		// when
+		actual, secrets, err := NewFileSource(dir, newConfigObj).Load()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, config.Spec, actual.(*toolchainv1alpha1.ToolchainConfig).Spec)
+		assert.Equal(t, map[string]map[string]string{"notifications": {"mailgunAPIKey": "abc"}}, secrets)
+		cached, cachedSecrets := configCache.get()
+		assert.Equal(t, config.Spec, cached.(*toolchainv1alpha1.ToolchainConfig).Spec)
+		assert.Equal(t, secrets, cachedSecrets)
+	})
+
+	t.Run("with cache", func(t *testing.T) {
+		// given
+		t.Cleanup(ResetCache)
+		dir := t.TempDir()
+		config := testconfig.NewToolchainConfigObj(t, testconfig.Environment(testconfig.E2E))
+		writeConfigFile(t, dir, config)
+		cache := CacheFor(NewCacheKey(toolchainv1alpha1.GroupVersion.WithKind("ToolchainConfig"), "toolchain-host-operator", "other"))
+
+		// when
+		_, _, err := NewFileSource(dir, newConfigObj, WithCache(cache)).Load()
+
+		// then
+		require.NoError(t, err)
+		cached, _ := cache.GetCached()
+		require.NotNil(t, cached)
+		assert.Equal(t, config.Spec, cached.(*toolchainv1alpha1.ToolchainConfig).Spec)
+		// the default cache is not updated
+		cached, _ = configCache.get()
+		assert.Nil(t, cached)
+	})
+
+	t.Run("no config file", func(t *testing.T) {
+		// given
+		t.Cleanup(ResetCache)
+
+		// when
+		actual, secrets, err := NewFileSource(t.TempDir(), newConfigObj).Load()
+
+		// then
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, secrets)
+		cached, _ := configCache.get()
+		assert.Nil(t, cached)
+	})
+
+	t.Run("invalid config file", func(t *testing.T) {
+		// given
+		t.Cleanup(ResetCache)
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ConfigFileName), []byte("spec: [invalid"), 0600))
+
+		// when
+		_, _, err := NewFileSource(dir, newConfigObj).Load()
+
+		// then
+		require.ErrorContains(t, err, "unable to decode the configuration file")
+		cached, _ := configCache.get()
+		assert.Nil(t, cached)
+	})
+}
+
+func TestFileSourceStart(t *testing.T) {
+	// given
+	t.Cleanup(ResetCache)
+	dir := t.TempDir()
+	writeConfigFile(t, dir, testconfig.NewToolchainConfigObj(t, testconfig.AutomaticApproval().Enabled(false)))
+	changes := make(chan ConfigChange, 10)
+	unsubscribe := SubscribeChannel(changes)
+	defer unsubscribe()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- NewFileSource(dir, func() runtime.Object {
+			return &toolchainv1alpha1.ToolchainConfig{}
+		}).Start(ctx)
+	}()
+	change := receiveChange(t, changes)
+	assert.Equal(t, []string{"host.automaticApproval"}, change.Diff.ChangedSections)
+
+	t.Run("config file changed", func(t *testing.T) {
+		// when
+		writeConfigFile(t, dir, testconfig.NewToolchainConfigObj(t, testconfig.AutomaticApproval().Enabled(true)))
+
+		// then
+		change := receiveChange(t, changes)
+		assert.Equal(t, []string{"host.automaticApproval"}, change.Diff.ChangedSections)
+		assert.True(t, *change.New.(*toolchainv1alpha1.ToolchainConfig).Spec.Host.AutomaticApproval.Enabled)
+	})
+
+	t.Run("secret added", func(t *testing.T) {
+		// when
+		writeSecretFile(t, dir, "notifications", "mailgunAPIKey", "abc")
+
+		// then
+		change := receiveChange(t, changes)
+		assert.True(t, change.Diff.SecretsChanged)
+		assert.Equal(t, map[string]map[string]string{"notifications": {"mailgunAPIKey": "abc"}}, change.NewSecrets)
+	})
+
+	t.Run("secret changed", func(t *testing.T) {
+		// when
+		writeSecretFile(t, dir, "notifications", "mailgunAPIKey", "def")
+
+		// then
+		change := receiveChange(t, changes)
+		assert.True(t, change.Diff.SecretsChanged)
+		assert.Equal(t, map[string]map[string]string{"notifications": {"mailgunAPIKey": "def"}}, change.NewSecrets)
+	})
+
+	t.Run("invalid config file keeps the previous config", func(t *testing.T) {
+		// when
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ConfigFileName), []byte("spec: [invalid"), 0600))
+
+		// then
+		select {
+		case change := <-changes:
+			t.Fatalf("unexpected change: %v", change.Diff)
+		case <-time.After(5 * reloadDelay):
+		}
+		cached, _ := configCache.get()
+		assert.True(t, *cached.(*toolchainv1alpha1.ToolchainConfig).Spec.Host.AutomaticApproval.Enabled)
+	})
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		// when
+		cancel()
+
+		// then
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the file source didn't stop")
+		}
+	})
+}
+
+func writeConfigFile(t *testing.T, dir string, config runtime.Object) {
+	content, err := yaml.Marshal(config)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ConfigFileName), content, 0600))
+}
+
+func writeSecretFile(t *testing.T, dir, secret, key, value string) {
+	secretDir := filepath.Join(dir, SecretsDirName, secret)
+	require.NoError(t, os.MkdirAll(secretDir, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(secretDir, key), []byte(value), 0600))
+}
+
+func receiveChange(t *testing.T, changes <-chan ConfigChange) ConfigChange {
+	select {
+	case change := <-changes:
+		return change
+	case <-time.After(5 * time.Second):
+		t.Fatal("no configuration change was received")
+		return ConfigChange{}
+	}
+}
@@ -0,0 +1,158 @@
+package configuration
+
+import (
+	"fmt"
+	"sync"
+
+	errs "github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var caches = &registry{}
+
+// CacheKey identifies a configuration resource by its kind, namespace and name
+type CacheKey struct {
+	schema.GroupVersionKind
+	types.NamespacedName
+}
+
+// NewCacheKey returns the key of the configuration resource of the given kind, with the given namespace and name
+func NewCacheKey(gvk schema.GroupVersionKind, namespace, name string) CacheKey {
+	return CacheKey{
+		GroupVersionKind: gvk,
+		NamespacedName:   types.NamespacedName{Namespace: namespace, Name: name},
+	}
+}
+
+func (k CacheKey) String() string {
+	return fmt.Sprintf("%s/%s", k.GroupVersionKind.String(), k.NamespacedName.String())
+}
+
+// registry the caches indexed by the key of their configuration resource
+type registry struct {
+	sync.Mutex
+	entries map[CacheKey]*cache
+	// defaultKind the kind of the configuration resource of the default cache, empty until a resource is cached in it
+	defaultKind schema.GroupVersionKind
+}
+
+// get returns the cache for the given key, which is created if it doesn't exist yet.
+// The key of the `config` resource in the watch namespace is bound to the default cache, as long as it has the same kind
+// as the resource of the default cache (or if the default cache doesn't have any kind yet), so that both the keyed cache
+// and the default cache functions (eg, GetCachedConfig) use the same cache for it. The other kinds have their own caches.
+func (r *registry) get(key CacheKey) *cache {
+	r.Lock()
+	defer r.Unlock()
+	if r.isDefault(key) {
+		return configCache
+	}
+	if r.entries == nil {
+		r.entries = map[CacheKey]*cache{}
+	}
+	c, found := r.entries[key]
+	if !found {
+		c = &cache{}
+		r.entries[key] = c
+	}
+	return c
+}
+
+// isDefault returns `true` if the given key is the one of the default cache, in which case the default cache is bound to its kind
+func (r *registry) isDefault(key CacheKey) bool {
+	name, err := defaultCacheName()
+	if err != nil || key.NamespacedName != name {
+		return false
+	}
+	return r.bindDefaultKind(key.GroupVersionKind)
+}
+
+// bindDefaultKind binds the default cache to the given kind if it isn't bound to any kind yet,
+// and returns `true` if the default cache is bound to the given kind. An empty kind is ignored.
+func (r *registry) bindDefaultKind(gvk schema.GroupVersionKind) bool {
+	if gvk.Empty() {
+		return false
+	}
+	if r.defaultKind.Empty() {
+		r.defaultKind = gvk
+	}
+	return r.defaultKind == gvk
+}
+
+func (r *registry) setDefaultKind(gvk schema.GroupVersionKind) {
+	r.Lock()
+	defer r.Unlock()
+	r.bindDefaultKind(gvk)
+}
+
+// Cache a handle on the cache of a single configuration resource, which is independent of the caches of the other resources.
+// This allows a process to cache several configuration resources, eg, a ToolchainConfig and a MemberOperatorConfig,
+// or the configuration resources of several namespaces.
+// The cache of the `config` resource in the watch namespace is the default cache (used by GetCachedConfig, GetConfig and LoadLatest),
+// provided that the resource has the same kind as the one in the default cache.
+// The handle remains valid after ResetCache, in which case the cache is empty again.
+type Cache struct {
+	key CacheKey
+}
+
+// CacheFor returns the handle on the cache of the configuration resource with the given key
+func CacheFor(key CacheKey) Cache {
+	return Cache{key: key}
+}
+
+// Key returns the key of the configuration resource of the cache
+func (c Cache) Key() CacheKey {
+	return c.key
+}
+
+func (c Cache) entry() *cache {
+	return caches.get(c.key)
+}
+
+// Update stores the given config object and secrets in the cache, and notifies the subscribers of the cache if they changed
+func (c Cache) Update(config runtime.Object, secrets map[string]map[string]string) {
+	c.entry().set(config, secrets)
+}
+
+// GetCached returns the cached config object and secrets. The config object is nil if the cache is empty.
+func (c Cache) GetCached() (runtime.Object, map[string]map[string]string) {
+	return c.entry().get()
+}
+
+// LoadLatest retrieves the latest configuration resource of the cache and the secrets it references using the provided client,
+// and updates the cache. The resource is created from the kind of the key, so it must be registered in the scheme of the client.
+// If the resource is not found, then returns nil for the configuration and secrets.
+func (c Cache) LoadLatest(cl client.Client) (runtime.Object, map[string]map[string]string, error) {
+	obj, err := cl.Scheme().New(c.key.GroupVersionKind)
+	if err != nil {
+		return nil, nil, errs.Wrapf(err, "unable to create the configuration resource of the '%s' cache", c.key)
+	}
+	configObj, ok := obj.(client.Object)
+	if !ok {
+		return nil, nil, fmt.Errorf("the '%s' kind is not a valid configuration resource", c.key.GroupVersionKind)
+	}
+	return loadLatest(cl, c.key.NamespacedName, configObj, c.entry())
+}
+
+// Get returns the cached config object and secrets. If the cache is empty, then it loads them using the provided client.
+func (c Cache) Get(cl client.Client) (runtime.Object, map[string]map[string]string, error) {
+	config, secrets := c.GetCached()
+	if config == nil {
+		return c.LoadLatest(cl)
+	}
+	return config, secrets, nil
+}
+
+// Subscribe registers the given listener, which is called each time the configuration in the cache changes.
+// It returns a func to unsubscribe the listener.
+func (c Cache) Subscribe(listener Listener) (unsubscribe func()) {
+	return c.entry().subscriptions.add(listener)
+}
+
+// SubscribeChannel registers the given channel, which receives the changes of the configuration in the cache without blocking.
+// It returns a func to unsubscribe the channel.
+func (c Cache) SubscribeChannel(ch chan<- ConfigChange) (unsubscribe func()) {
+	return c.Subscribe(channelListener(ch))
+}
@@ -0,0 +1,246 @@
+package configuration
+
+import (
+	"testing"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/test"
+	testconfig "github.com/codeready-toolchain/toolchain-common/pkg/test/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+func TestKeyedCache(t *testing.T) {
+	// given
+	toolchainConfig := NewToolchainConfigObjWithReset(t, testconfig.AutomaticApproval().Enabled(true), testconfig.Notifications().Secret().Ref("notification-secret"))
+	memberConfig := testconfig.NewMemberOperatorConfigObj(testconfig.MemberEnvironment("e2e-tests"))
+	otherMemberConfig := testconfig.NewMemberOperatorConfigObj(testconfig.MemberEnvironment("dev"))
+	otherMemberConfig.Namespace = "other-member-operator"
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "notification-secret",
+			Namespace: test.HostOperatorNs,
+		},
+		Data: map[string][]byte{
+			"mailgunAPIKey": []byte("abc"),
+		},
+	}
+	cl := test.NewFakeClient(t, toolchainConfig, memberConfig, otherMemberConfig, secret)
+	toolchainConfigCache := CacheFor(NewCacheKey(toolchainv1alpha1.GroupVersion.WithKind("ToolchainConfig"), test.HostOperatorNs, "config"))
+	memberConfigCache := CacheFor(NewCacheKey(toolchainv1alpha1.GroupVersion.WithKind("MemberOperatorConfig"), test.MemberOperatorNs, "config"))
+	otherMemberConfigCache := CacheFor(NewCacheKey(toolchainv1alpha1.GroupVersion.WithKind("MemberOperatorConfig"), "other-member-operator", "config"))
+
+	t.Run("caches are empty", func(t *testing.T) {
+		for _, c := range []Cache{toolchainConfigCache, memberConfigCache, otherMemberConfigCache} {
+			// when
+			actual, secrets := c.GetCached()
+
+			// then
+			assert.Nil(t, actual)
+			assert.Empty(t, secrets)
+		}
+	})
+
+	t.Run("caches are independent", func(t *testing.T) {
+		// when
+		_, _, err := toolchainConfigCache.Get(cl)
+		require.NoError(t, err)
+		_, _, err = memberConfigCache.Get(cl)
+		require.NoError(t, err)
+		_, _, err = otherMemberConfigCache.LoadLatest(cl)
+		require.NoError(t, err)
+
+		// then
+		actual, secrets := toolchainConfigCache.GetCached()
+		require.IsType(t, &toolchainv1alpha1.ToolchainConfig{}, actual)
+		assert.Equal(t, toolchainConfig.Spec, actual.(*toolchainv1alpha1.ToolchainConfig).Spec)
+		assert.Equal(t, map[string]map[string]string{"notification-secret": {"mailgunAPIKey": "abc"}}, secrets)
+
+		actual, secrets = memberConfigCache.GetCached()
+		require.IsType(t, &toolchainv1alpha1.MemberOperatorConfig{}, actual)
+		assert.Equal(t, "e2e-tests", *actual.(*toolchainv1alpha1.MemberOperatorConfig).Spec.Environment)
+		assert.Empty(t, secrets)
+
+		actual, _ = otherMemberConfigCache.GetCached()
+		require.IsType(t, &toolchainv1alpha1.MemberOperatorConfig{}, actual)
+		assert.Equal(t, "dev", *actual.(*toolchainv1alpha1.MemberOperatorConfig).Spec.Environment)
+
+		// the default cache is not affected
+		actual, _ = GetCachedConfig()
+		assert.Nil(t, actual)
+	})
+
+	t.Run("update notifies the subscribers of the cache only", func(t *testing.T) {
+		// given
+		var memberChanges, otherMemberChanges, defaultChanges []ConfigChange
+		defer memberConfigCache.Subscribe(func(change ConfigChange) {
+			memberChanges = append(memberChanges, change)
+		})()
+		defer otherMemberConfigCache.Subscribe(func(change ConfigChange) {
+			otherMemberChanges = append(otherMemberChanges, change)
+		})()
+		defer Subscribe(func(change ConfigChange) {
+			defaultChanges = append(defaultChanges, change)
+		})()
+		updated := memberConfig.DeepCopy()
+		updated.Spec.Environment = ptr.To("prod")
+
+		// when
+		memberConfigCache.Update(updated, nil)
+
+		// then
+		require.Len(t, memberChanges, 1)
+		assert.Equal(t, []string{"environment"}, memberChanges[0].Diff.ChangedSections)
+		assert.Empty(t, otherMemberChanges)
+		assert.Empty(t, defaultChanges)
+		actual, _ := otherMemberConfigCache.GetCached()
+		assert.Equal(t, "dev", *actual.(*toolchainv1alpha1.MemberOperatorConfig).Spec.Environment)
+	})
+
+	t.Run("resource not found", func(t *testing.T) {
+		// given
+		c := CacheFor(NewCacheKey(toolchainv1alpha1.GroupVersion.WithKind("MemberOperatorConfig"), "unknown", "config"))
+
+		// when
+		actual, secrets, err := c.LoadLatest(cl)
+
+		// then
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, secrets)
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		// given
+		c := CacheFor(NewCacheKey(schema.GroupVersionKind{Group: "unknown", Version: "v1", Kind: "Config"}, test.HostOperatorNs, "config"))
+
+		// when
+		_, _, err := c.LoadLatest(cl)
+
+		// then
+		require.ErrorContains(t, err, "unable to create the configuration resource of the 'unknown/v1, Kind=Config/toolchain-host-operator/config' cache")
+	})
+
+	t.Run("reset empties the caches", func(t *testing.T) {
+		// when
+		ResetCache()
+
+		// then
+		actual, _ := memberConfigCache.GetCached()
+		assert.Nil(t, actual)
+	})
+}
+
+func TestKeyedCacheOfWatchNamespace(t *testing.T) {
+	// given
+	restore := test.SetEnvVarsAndRestore(t, test.Env(WatchNamespaceEnvVar, test.HostOperatorNs))
+	defer restore()
+	toolchainConfig := NewToolchainConfigObjWithReset(t, testconfig.AutomaticApproval().Enabled(true))
+	cl := test.NewFakeClient(t, toolchainConfig)
+	c := CacheFor(NewCacheKey(toolchainv1alpha1.GroupVersion.WithKind("ToolchainConfig"), test.HostOperatorNs, "config"))
+
+	t.Run("keyed cache updates the default cache", func(t *testing.T) {
+		// when
+		_, _, err := c.LoadLatest(cl)
+
+		// then
+		require.NoError(t, err)
+		actual, _ := GetCachedConfig()
+		require.IsType(t, &toolchainv1alpha1.ToolchainConfig{}, actual)
+		assert.True(t, *actual.(*toolchainv1alpha1.ToolchainConfig).Spec.Host.AutomaticApproval.Enabled)
+	})
+
+	t.Run("default cache updates the keyed cache", func(t *testing.T) {
+		// given
+		var changes []ConfigChange
+		defer c.Subscribe(func(change ConfigChange) {
+			changes = append(changes, change)
+		})()
+		updated := toolchainConfig.DeepCopy()
+		updated.Spec.Host.AutomaticApproval.Enabled = ptr.To(false)
+
+		// when
+		UpdateConfig(updated, nil)
+
+		// then
+		actual, _ := c.GetCached()
+		assert.False(t, *actual.(*toolchainv1alpha1.ToolchainConfig).Spec.Host.AutomaticApproval.Enabled)
+		require.Len(t, changes, 1)
+	})
+
+	t.Run("other namespace has its own cache", func(t *testing.T) {
+		// given
+		other := CacheFor(NewCacheKey(toolchainv1alpha1.GroupVersion.WithKind("ToolchainConfig"), "other-host-operator", "config"))
+
+		// when
+		actual, _ := other.GetCached()
+
+		// then
+		assert.Nil(t, actual)
+	})
+}
+
+func TestKeyedCachesOfSeveralKindsInWatchNamespace(t *testing.T) {
+	// given
+	restore := test.SetEnvVarsAndRestore(t, test.Env(WatchNamespaceEnvVar, test.HostOperatorNs))
+	defer restore()
+	toolchainConfig := NewToolchainConfigObjWithReset(t, testconfig.AutomaticApproval().Enabled(true))
+	memberConfig := testconfig.NewMemberOperatorConfigObj(testconfig.MemberEnvironment("e2e-tests"))
+	memberConfig.Namespace = test.HostOperatorNs
+	cl := test.NewFakeClient(t, toolchainConfig, memberConfig)
+	toolchainConfigCache := CacheFor(NewCacheKey(toolchainv1alpha1.GroupVersion.WithKind("ToolchainConfig"), test.HostOperatorNs, "config"))
+	memberConfigCache := CacheFor(NewCacheKey(toolchainv1alpha1.GroupVersion.WithKind("MemberOperatorConfig"), test.HostOperatorNs, "config"))
+
+	// when
+	_, _, err := LoadLatest(cl, &toolchainv1alpha1.ToolchainConfig{})
+	require.NoError(t, err)
+	_, _, err = memberConfigCache.LoadLatest(cl)
+	require.NoError(t, err)
+
+	// then
+	actual, _ := toolchainConfigCache.GetCached()
+	require.IsType(t, &toolchainv1alpha1.ToolchainConfig{}, actual)
+	assert.True(t, *actual.(*toolchainv1alpha1.ToolchainConfig).Spec.Host.AutomaticApproval.Enabled)
+	actual, _ = GetCachedConfig()
+	require.IsType(t, &toolchainv1alpha1.ToolchainConfig{}, actual)
+	actual, _ = memberConfigCache.GetCached()
+	require.IsType(t, &toolchainv1alpha1.MemberOperatorConfig{}, actual)
+	assert.Equal(t, "e2e-tests", *actual.(*toolchainv1alpha1.MemberOperatorConfig).Spec.Environment)
+
+	t.Run("updating the default cache doesn't change the other kind", func(t *testing.T) {
+		// given
+		updated := toolchainConfig.DeepCopy()
+		updated.Spec.Host.AutomaticApproval.Enabled = ptr.To(false)
+
+		// when
+		UpdateConfig(updated, nil)
+
+		// then
+		actual, _ := toolchainConfigCache.GetCached()
+		assert.False(t, *actual.(*toolchainv1alpha1.ToolchainConfig).Spec.Host.AutomaticApproval.Enabled)
+		actual, _ = memberConfigCache.GetCached()
+		require.IsType(t, &toolchainv1alpha1.MemberOperatorConfig{}, actual)
+	})
+
+	t.Run("the first kind cached in the watch namespace is the one of the default cache", func(t *testing.T) {
+		// given
+		ResetCache()
+
+		// when
+		_, _, err := memberConfigCache.LoadLatest(cl)
+		require.NoError(t, err)
+		_, _, err = toolchainConfigCache.LoadLatest(cl)
+		require.NoError(t, err)
+
+		// then
+		actual, _ := GetCachedConfig()
+		require.IsType(t, &toolchainv1alpha1.MemberOperatorConfig{}, actual)
+		actual, _ = toolchainConfigCache.GetCached()
+		require.IsType(t, &toolchainv1alpha1.ToolchainConfig{}, actual)
+	})
+}
@@ -73,39 +73,11 @@ func LoadFromSecret(resourceKey string, cl client.Client) (map[string]string, er
 // prefix: represents the operator prefix (HOST_OPERATOR/MEMBER_OPERATOR)
 // resourceKey: is the env var which contains the configmap resource name.
 // cl: is the client that should be used to retrieve the configmap.
+//
+// Deprecated: use LoadOverlay, which does not modify the environment of the process
 func LoadFromConfigMap(prefix, resourceKey string, cl client.Client) error {
-	// get the configMap name
-	configMapName := getResourceName(resourceKey)
-	if configMapName == "" {
-		return nil
-	}
-
-	// get the configMap
-	namespace, err := GetWatchNamespace()
-	if err != nil {
-		return err
-	}
-
-	configMap := &v1.ConfigMap{}
-	namespacedName := types.NamespacedName{Namespace: namespace, Name: configMapName}
-	err = cl.Get(context.TODO(), namespacedName, configMap)
-	if err != nil {
-		if !errs.IsNotFound(err) {
-			return err
-		}
-		logf.Log.Info("configmap is not found")
-	}
-
-	// get configMap data and set environment variables
-	for key, value := range configMap.Data {
-		configKey := createOperatorEnvVarKey(prefix, key)
-		err := os.Setenv(configKey, value)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	_, err := LoadOverlay(cl, prefix, WithConfigMap(resourceKey), ExportToEnv())
+	return err
 }
 
 // getResourceName gets the resource name via env var
@@ -131,6 +103,7 @@ func createOperatorEnvVarKey(prefix, key string) string {
 
 // LoadSecrets lists all secrets in the provided namespace and indexes them into a map by name along with its secret data.
 // Service account secrets are skipped.
+// Use LoadReferencedSecrets to only load the secrets referenced by a configuration object.
 func LoadSecrets(cl client.Client, namespace string) (map[string]map[string]string, error) {
 	var allSecrets = make(map[string]map[string]string)
 	secretList := &v1.SecretList{}
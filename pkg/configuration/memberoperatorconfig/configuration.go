@@ -16,7 +16,7 @@ var logger = logf.Log.WithName("configuration")
 
 type Configuration struct {
 	cfg     *toolchainv1alpha1.MemberOperatorConfigSpec
-	secrets map[string]map[string]string
+	secrets map[string]map[string]commonconfig.Sensitive
 }
 
 // GetConfiguration returns a Configuration using the cache, or if the cache was not initialized
@@ -48,6 +48,24 @@ func ForceLoadConfiguration(cl client.Client) (Configuration, error) {
 	return newConfiguration(config, secrets), nil
 }
 
+// Cache returns the handle on the cache of the MemberOperatorConfig with the name "config" in the given namespace,
+// which is independent of the default cache used by GetConfiguration, GetCachedConfiguration and ForceLoadConfiguration
+func Cache(namespace string) commonconfig.Cache {
+	return commonconfig.CacheFor(commonconfig.NewCacheKey(toolchainv1alpha1.GroupVersion.WithKind("MemberOperatorConfig"), namespace, "config"))
+}
+
+// GetConfigurationFrom returns a Configuration using the given cache, or if the cache was not initialized
+// then retrieves the latest config using the provided client and updates the cache
+func GetConfigurationFrom(cache commonconfig.Cache, cl client.Client) (Configuration, error) {
+	config, secrets, err := cache.Get(cl)
+	if err != nil {
+		// return default config
+		logger.Error(err, "failed to retrieve Configuration", "cache", cache.Key().String())
+		return Configuration{cfg: &toolchainv1alpha1.MemberOperatorConfigSpec{}}, err
+	}
+	return newConfiguration(config, secrets), nil
+}
+
 func newConfiguration(config runtime.Object, secrets map[string]map[string]string) Configuration {
 	if config == nil {
 		// return default config if there's no config resource
@@ -60,11 +78,20 @@ func newConfiguration(config runtime.Object, secrets map[string]map[string]strin
 		logger.Error(fmt.Errorf("cache does not contain Configuration resource type"), "failed to get Configuration from resource, using default configuration")
 		return Configuration{cfg: &toolchainv1alpha1.MemberOperatorConfigSpec{}}
 	}
-	return Configuration{cfg: &membercfg.Spec, secrets: secrets}
+	return Configuration{cfg: &membercfg.Spec, secrets: commonconfig.ToSensitive(secrets)}
 }
 
+// Print logs the effective configuration, with all the default values resolved and the secret values redacted
 func (c *Configuration) Print() {
-	logger.Info("Member operator configuration variables", "MemberOperatorConfigSpec", c.cfg)
+	effective, err := c.EffectiveConfig()
+	if err != nil {
+		logger.Error(err, "unable to compute the effective member operator configuration")
+	} else {
+		logger.Info("Member operator configuration variables", "MemberOperatorConfig", effective.Settings)
+	}
+	if errs := c.Validate(); len(errs) > 0 {
+		logger.Error(errs.ToAggregate(), "invalid member operator configuration, the default values are used for the invalid fields")
+	}
 }
 
 func (c *Configuration) Auth() AuthConfig {
@@ -145,12 +172,12 @@ func (a AutoscalerConfig) BufferReplicas() int {
 
 type GitHubSecret struct {
 	s       toolchainv1alpha1.GitHubSecret
-	secrets map[string]map[string]string
+	secrets map[string]map[string]commonconfig.Sensitive
 }
 
 func (gh GitHubSecret) githubSecret(secretKey string) string {
 	secret := commonconfig.GetString(gh.s.Ref, "")
-	return gh.secrets[secret][secretKey]
+	return gh.secrets[secret][secretKey].Value()
 }
 
 func (gh GitHubSecret) AccessTokenKey() string {
@@ -230,7 +257,7 @@ func (a ToolchainClusterConfig) HealthCheckTimeout() time.Duration {
 
 type WebhookConfig struct {
 	w       toolchainv1alpha1.WebhookConfig
-	secrets map[string]map[string]string
+	secrets map[string]map[string]commonconfig.Sensitive
 }
 
 func (a WebhookConfig) webhookSecret(webhookSecretKey string) string {
@@ -238,7 +265,7 @@ func (a WebhookConfig) webhookSecret(webhookSecretKey string) string {
 		return ""
 	}
 	webhookSecret := commonconfig.GetString(a.w.Secret.Ref, "")
-	return a.secrets[webhookSecret][webhookSecretKey]
+	return a.secrets[webhookSecret][webhookSecretKey].Value()
 }
 
 func (a WebhookConfig) Deploy() bool {
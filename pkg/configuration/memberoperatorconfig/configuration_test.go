@@ -5,9 +5,11 @@ import (
 	"time"
 
 	commonconfig "github.com/codeready-toolchain/toolchain-common/pkg/configuration"
+	"github.com/codeready-toolchain/toolchain-common/pkg/test"
 	testconfig "github.com/codeready-toolchain/toolchain-common/pkg/test/config"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAuth(t *testing.T) {
@@ -145,7 +147,7 @@ func TestGitHubSecret(t *testing.T) {
 		gitHubSecretValues["accessToken"] = "abc123"
 		secrets := make(map[string]map[string]string)
 		secrets["github"] = gitHubSecretValues
-		memberOperatorCfg := Configuration{cfg: &cfg.Spec, secrets: secrets}
+		memberOperatorCfg := Configuration{cfg: &cfg.Spec, secrets: commonconfig.ToSensitive(secrets)}
 
 		assert.Equal(t, "abc123", memberOperatorCfg.GitHubSecret().AccessTokenKey())
 	})
@@ -281,9 +283,36 @@ func TestWebhook(t *testing.T) {
 		webhookSecretValues["vmKey"] = "ssh-rsa abc-123"
 		secrets := make(map[string]map[string]string)
 		secrets["webhook"] = webhookSecretValues
-		memberOperatorCfg := Configuration{cfg: &cfg.Spec, secrets: secrets}
+		memberOperatorCfg := Configuration{cfg: &cfg.Spec, secrets: commonconfig.ToSensitive(secrets)}
 
 		assert.False(t, memberOperatorCfg.Webhook().Deploy())
 		assert.Equal(t, "ssh-rsa abc-123", memberOperatorCfg.Webhook().VMSSHKey())
 	})
 }
+
+func TestGetConfigurationFrom(t *testing.T) {
+	// given
+	t.Cleanup(commonconfig.ResetCache)
+	cfg := testconfig.NewMemberOperatorConfigObj(testconfig.MemberEnvironment("dev"))
+	cl := test.NewFakeClient(t, cfg)
+
+	t.Run("loads the config in the namespace of the cache", func(t *testing.T) {
+		// when
+		memberOperatorCfg, err := GetConfigurationFrom(Cache(test.MemberOperatorNs), cl)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "dev", memberOperatorCfg.Environment())
+		defaultCfg := GetCachedConfiguration()
+		assert.Equal(t, "prod", defaultCfg.Environment()) // the default cache is not affected
+	})
+
+	t.Run("default config when not found", func(t *testing.T) {
+		// when
+		memberOperatorCfg, err := GetConfigurationFrom(Cache("other-namespace"), cl)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "prod", memberOperatorCfg.Environment())
+	})
+}
@@ -0,0 +1,53 @@
+package memberoperatorconfig
+
+import (
+	"io"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	commonconfig "github.com/codeready-toolchain/toolchain-common/pkg/configuration"
+)
+
+// Defaults returns the default values of the settings of a MemberOperatorConfig, indexed by their path in the spec
+func Defaults() map[string]interface{} {
+	c := &Configuration{cfg: &toolchainv1alpha1.MemberOperatorConfigSpec{}}
+	return map[string]interface{}{
+		"auth.idp":                            c.Auth().Idp(),
+		"autoscaler.deploy":                   c.Autoscaler().Deploy(),
+		"autoscaler.bufferMemory":             c.Autoscaler().BufferMemory(),
+		"autoscaler.bufferCPU":                c.Autoscaler().BufferCPU(),
+		"autoscaler.bufferReplicas":           c.Autoscaler().BufferReplicas(),
+		"che.required":                        c.Che().IsRequired(),
+		"che.namespace":                       c.Che().Namespace(),
+		"che.routeName":                       c.Che().RouteName(),
+		"console.namespace":                   c.Console().Namespace(),
+		"console.routeName":                   c.Console().RouteName(),
+		"environment":                         c.Environment(),
+		"memberStatus.refreshPeriod":          c.MemberStatus().RefreshPeriod().String(),
+		"skipUserCreation":                    c.SkipUserCreation(),
+		"toolchainCluster.healthCheckPeriod":  c.ToolchainCluster().HealthCheckPeriod().String(),
+		"toolchainCluster.healthCheckTimeout": c.ToolchainCluster().HealthCheckTimeout().String(),
+		"webhook.deploy":                      c.Webhook().Deploy(),
+	}
+}
+
+// EffectiveConfig lists all the settings of the configuration, with their effective value, their source
+// (the MemberOperatorConfig resource, the default value or a secret) and their default value.
+// The values of the MemberOperatorConfig resource which the getters reject (eg, an invalid duration) are reported
+// with the default value which replaces them.
+func (c *Configuration) EffectiveConfig() (*commonconfig.EffectiveConfig, error) {
+	effective, err := commonconfig.NewEffectiveConfig(c.cfg, Defaults(), c.secrets)
+	if err != nil {
+		return nil, err
+	}
+	effective.MarkInvalid(validateDurations(c.cfg))
+	return effective, nil
+}
+
+// WriteEffective writes the effective configuration as indented JSON, with the secret values redacted
+func (c *Configuration) WriteEffective(w io.Writer) error {
+	effective, err := c.EffectiveConfig()
+	if err != nil {
+		return err
+	}
+	return effective.WriteJSON(w)
+}
@@ -0,0 +1,136 @@
+package memberoperatorconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	commonconfig "github.com/codeready-toolchain/toolchain-common/pkg/configuration"
+	testconfig "github.com/codeready-toolchain/toolchain-common/pkg/test/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteEffective(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		// given
+		cfg := commonconfig.NewMemberOperatorConfigWithReset(t)
+		memberOperatorCfg := Configuration{cfg: &cfg.Spec}
+		buf := &bytes.Buffer{}
+
+		// when
+		err := memberOperatorCfg.WriteEffective(buf)
+
+		// then
+		require.NoError(t, err)
+		settings := settingsByPath(t, buf.Bytes())
+		assert.Equal(t, map[string]interface{}{"path": "auth.idp", "value": "rhd", "source": "default", "default": "rhd"}, settings["auth.idp"])
+		assert.Equal(t, map[string]interface{}{"path": "autoscaler.bufferReplicas", "value": 2.0, "source": "default", "default": 2.0}, settings["autoscaler.bufferReplicas"])
+		assert.Equal(t, map[string]interface{}{"path": "memberStatus.refreshPeriod", "value": "5s", "source": "default", "default": "5s"}, settings["memberStatus.refreshPeriod"])
+		assert.Equal(t, map[string]interface{}{"path": "memberStatus.gitHubSecret.accessTokenKey", "value": nil, "source": "default"}, settings["memberStatus.gitHubSecret.accessTokenKey"])
+		// every default value is written
+		for path := range Defaults() {
+			assert.Contains(t, settings, path)
+		}
+	})
+
+	t.Run("with secrets", func(t *testing.T) {
+		// given
+		cfg := commonconfig.NewMemberOperatorConfigWithReset(t,
+			testconfig.MemberEnvironment("dev"),
+			testconfig.MemberStatus().GitHubSecretRef("github").GitHubSecretAccessTokenKey("accessToken"),
+			testconfig.Webhook().WebhookSecretRef("webhook").VMSSHKey("vmSSHKeys"))
+		memberOperatorCfg := Configuration{cfg: &cfg.Spec, secrets: commonconfig.ToSensitive(map[string]map[string]string{
+			"github":  {"accessToken": "abc123"},
+			"webhook": {"vmSSHKeys": "ssh-rsa AAAA"},
+		})}
+		buf := &bytes.Buffer{}
+
+		// when
+		err := memberOperatorCfg.WriteEffective(buf)
+
+		// then
+		require.NoError(t, err)
+		settings := settingsByPath(t, buf.Bytes())
+		assert.Equal(t, "dev", settings["environment"]["value"])
+		assert.Equal(t, "[REDACTED]", settings["memberStatus.gitHubSecret.accessTokenKey"]["value"])
+		assert.Equal(t, "[REDACTED]", settings["webhook.secret.virtualMachineAccessKey"]["value"])
+		assert.NotContains(t, buf.String(), "abc123")
+		assert.NotContains(t, buf.String(), "ssh-rsa")
+		// the getters still return the actual values
+		assert.Equal(t, "abc123", memberOperatorCfg.GitHubSecret().AccessTokenKey())
+	})
+}
+
+func settingsByPath(t *testing.T, content []byte) map[string]map[string]interface{} {
+	effective := struct {
+		Settings []map[string]interface{} `json:"settings"`
+	}{}
+	require.NoError(t, json.Unmarshal(content, &effective))
+	settings := make(map[string]map[string]interface{}, len(effective.Settings))
+	for _, setting := range effective.Settings {
+		settings[setting["path"].(string)] = setting
+	}
+	return settings
+}
+
+func TestEffectiveConfig(t *testing.T) {
+	// given
+	cfg := commonconfig.NewMemberOperatorConfigWithReset(t,
+		testconfig.ToolchainCluster().HealthCheckPeriod("20s"),
+		testconfig.Webhook().WebhookSecretRef("webhook").VMSSHKey("vmSSHKeys"))
+	memberOperatorCfg := Configuration{cfg: &cfg.Spec, secrets: commonconfig.ToSensitive(map[string]map[string]string{
+		"webhook": {"vmSSHKeys": "ssh-rsa AAAA"},
+	})}
+
+	// when
+	effective, err := memberOperatorCfg.EffectiveConfig()
+
+	// then
+	require.NoError(t, err)
+	expected := map[string]commonconfig.Setting{
+		"auth.idp":                                 {Path: "auth.idp", Value: "rhd", Source: commonconfig.SourceDefault, Default: "rhd"},
+		"autoscaler.bufferReplicas":                {Path: "autoscaler.bufferReplicas", Value: 2, Source: commonconfig.SourceDefault, Default: 2},
+		"environment":                              {Path: "environment", Value: "prod", Source: commonconfig.SourceDefault, Default: "prod"},
+		"toolchainCluster.healthCheckPeriod":       {Path: "toolchainCluster.healthCheckPeriod", Value: "20s", Source: commonconfig.SourceCR, Default: "10s"},
+		"toolchainCluster.healthCheckTimeout":      {Path: "toolchainCluster.healthCheckTimeout", Value: "3s", Source: commonconfig.SourceDefault, Default: "3s"},
+		"webhook.secret.ref":                       {Path: "webhook.secret.ref", Value: "webhook", Source: commonconfig.SourceCR},
+		"webhook.secret.virtualMachineAccessKey":   {Path: "webhook.secret.virtualMachineAccessKey", Value: commonconfig.Sensitive("ssh-rsa AAAA"), Source: commonconfig.SourceSecret},
+		"memberStatus.gitHubSecret.accessTokenKey": {Path: "memberStatus.gitHubSecret.accessTokenKey", Source: commonconfig.SourceDefault},
+	}
+	for path, setting := range expected {
+		actual, found := effective.Get(path)
+		require.True(t, found, "missing setting '%s'", path)
+		assert.Equal(t, setting, actual)
+	}
+	// every default value is used by a setting
+	for path := range Defaults() {
+		_, found := effective.Get(path)
+		assert.True(t, found, "unknown setting '%s'", path)
+	}
+}
+
+func TestEffectiveConfigWithInvalidValues(t *testing.T) {
+	// given
+	cfg := commonconfig.NewMemberOperatorConfigWithReset(t,
+		testconfig.MemberStatus().RefreshPeriod("1 minute"),
+		testconfig.Autoscaler().BufferMemory("5GiB"))
+	memberOperatorCfg := Configuration{cfg: &cfg.Spec}
+
+	// when
+	effective, err := memberOperatorCfg.EffectiveConfig()
+
+	// then
+	require.NoError(t, err)
+	setting, found := effective.Get("memberStatus.refreshPeriod")
+	require.True(t, found)
+	// the getter ignores the invalid value and returns the default value
+	assert.Equal(t, commonconfig.Setting{Path: "memberStatus.refreshPeriod", Value: "5s", Source: commonconfig.SourceDefault, Default: "5s", Invalid: "1 minute"}, setting)
+	assert.Equal(t, "5s", memberOperatorCfg.MemberStatus().RefreshPeriod().String())
+	setting, found = effective.Get("autoscaler.bufferMemory")
+	require.True(t, found)
+	// the getter returns the value as is
+	assert.Equal(t, commonconfig.Setting{Path: "autoscaler.bufferMemory", Value: "5GiB", Source: commonconfig.SourceCR, Default: "50Mi"}, setting)
+	assert.Equal(t, "5GiB", memberOperatorCfg.Autoscaler().BufferMemory())
+}
@@ -0,0 +1,68 @@
+package memberoperatorconfig
+
+import (
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	commonconfig "github.com/codeready-toolchain/toolchain-common/pkg/configuration"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Validate returns all the invalid fields of the configuration, ie, the durations and quantities which cannot be parsed,
+// the negative counts and the secret keys which do not exist in the referenced secrets.
+// The getters of the Configuration fall back to the default values for such fields.
+func (c *Configuration) Validate() field.ErrorList {
+	return validateSpec(c.cfg, c.secrets)
+}
+
+// Validate returns all the invalid fields of the given MemberOperatorConfig, checking the secret keys against the given secrets
+// (indexed by secret name)
+func Validate(config *toolchainv1alpha1.MemberOperatorConfig, secrets map[string]map[string]string) field.ErrorList {
+	return validateSpec(&config.Spec, secrets)
+}
+
+// ValidateMemberOperatorConfig loads the secrets referenced by the given MemberOperatorConfig in its namespace using the provided client,
+// and validates the config. It returns an `Invalid` API error listing all the invalid fields, so it can be used as is
+// in a validating webhook.
+func ValidateMemberOperatorConfig(cl client.Client, config *toolchainv1alpha1.MemberOperatorConfig) error {
+	secrets, err := commonconfig.LoadReferencedSecrets(cl, config.Namespace, commonconfig.SecretRefs(config))
+	if err != nil {
+		return err
+	}
+	return commonconfig.NewInvalidError(toolchainv1alpha1.GroupVersion.WithKind("MemberOperatorConfig").GroupKind(), config.Name, Validate(config, secrets))
+}
+
+// validateDurations returns the invalid durations of the given spec. Unlike the other invalid values, they are rejected
+// by the getters of the configuration, which return the default value instead. They are also part of the errors of validateSpec.
+func validateDurations(spec *toolchainv1alpha1.MemberOperatorConfigSpec) field.ErrorList {
+	errs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+	errs = append(errs, commonconfig.ValidateDuration(specPath.Child("memberStatus", "refreshPeriod"), spec.MemberStatus.RefreshPeriod)...)
+	toolchainClusterPath := specPath.Child("toolchainCluster")
+	errs = append(errs, commonconfig.ValidateDuration(toolchainClusterPath.Child("healthCheckPeriod"), spec.ToolchainCluster.HealthCheckPeriod)...)
+	errs = append(errs, commonconfig.ValidateDuration(toolchainClusterPath.Child("healthCheckTimeout"), spec.ToolchainCluster.HealthCheckTimeout)...)
+	return errs
+}
+
+func validateSpec[V string | commonconfig.Sensitive](spec *toolchainv1alpha1.MemberOperatorConfigSpec, secrets map[string]map[string]V) field.ErrorList {
+	errs := validateDurations(spec)
+	specPath := field.NewPath("spec")
+
+	autoscalerPath := specPath.Child("autoscaler")
+	errs = append(errs, commonconfig.ValidateQuantity(autoscalerPath.Child("bufferMemory"), spec.Autoscaler.BufferMemory)...)
+	errs = append(errs, commonconfig.ValidateQuantity(autoscalerPath.Child("bufferCPU"), spec.Autoscaler.BufferCPU)...)
+	errs = append(errs, commonconfig.ValidateNonNegativeInt(autoscalerPath.Child("bufferReplicas"), spec.Autoscaler.BufferReplicas)...)
+
+	cheSecretPath := specPath.Child("che", "secret")
+	errs = append(errs, commonconfig.ValidateSecretKey(cheSecretPath, spec.Che.Secret.Ref, "cheAdminUsernameKey", spec.Che.Secret.CheAdminUsernameKey, secrets)...)
+	errs = append(errs, commonconfig.ValidateSecretKey(cheSecretPath, spec.Che.Secret.Ref, "cheAdminPasswordKey", spec.Che.Secret.CheAdminPasswordKey, secrets)...)
+
+	memberStatusPath := specPath.Child("memberStatus")
+	gitHubSecret := spec.MemberStatus.GitHubSecret
+	errs = append(errs, commonconfig.ValidateSecretKey(memberStatusPath.Child("gitHubSecret"), gitHubSecret.Ref, "accessTokenKey", gitHubSecret.AccessTokenKey, secrets)...)
+
+	if webhookSecret := spec.Webhook.Secret; webhookSecret != nil {
+		errs = append(errs, commonconfig.ValidateSecretKey(specPath.Child("webhook", "secret"), webhookSecret.Ref, "virtualMachineAccessKey", webhookSecret.VirtualMachineAccessKey, secrets)...)
+	}
+	return errs
+}
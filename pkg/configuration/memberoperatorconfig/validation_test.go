@@ -0,0 +1,130 @@
+package memberoperatorconfig
+
+import (
+	"testing"
+
+	commonconfig "github.com/codeready-toolchain/toolchain-common/pkg/configuration"
+	"github.com/codeready-toolchain/toolchain-common/pkg/test"
+	testconfig "github.com/codeready-toolchain/toolchain-common/pkg/test/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidate(t *testing.T) {
+	secrets := map[string]map[string]string{
+		"github": {
+			"token": "abc",
+		},
+		"webhook": {
+			"vmSSHKeys": "ssh-rsa AAA",
+		},
+	}
+
+	t.Run("default", func(t *testing.T) {
+		// given
+		cfg := commonconfig.NewMemberOperatorConfigWithReset(t)
+		memberOperatorCfg := Configuration{cfg: &cfg.Spec}
+
+		// when
+		errs := memberOperatorCfg.Validate()
+
+		// then
+		assert.Empty(t, errs)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		// given
+		cfg := commonconfig.NewMemberOperatorConfigWithReset(t,
+			testconfig.Autoscaler().BufferMemory("5Gi").BufferCPU("500m").BufferReplicas(0),
+			testconfig.MemberStatus().RefreshPeriod("1m").GitHubSecretRef("github").GitHubSecretAccessTokenKey("token"),
+			testconfig.ToolchainCluster().HealthCheckPeriod("20s").HealthCheckTimeout("5s"),
+			testconfig.Webhook().WebhookSecretRef("webhook").VMSSHKey("vmSSHKeys"))
+		memberOperatorCfg := Configuration{cfg: &cfg.Spec, secrets: commonconfig.ToSensitive(secrets)}
+
+		// when
+		errs := memberOperatorCfg.Validate()
+
+		// then
+		assert.Empty(t, errs)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		// given
+		cfg := commonconfig.NewMemberOperatorConfigWithReset(t,
+			testconfig.Autoscaler().BufferMemory("5GiB").BufferCPU("500m").BufferReplicas(-1),
+			testconfig.MemberStatus().RefreshPeriod("1 minute").GitHubSecretRef("github").GitHubSecretAccessTokenKey("access-token"),
+			testconfig.ToolchainCluster().HealthCheckPeriod("20s").HealthCheckTimeout("5"),
+			testconfig.Webhook().WebhookSecretRef("unknown").VMSSHKey("vmSSHKeys"))
+		memberOperatorCfg := Configuration{cfg: &cfg.Spec, secrets: commonconfig.ToSensitive(secrets)}
+
+		// when
+		errs := memberOperatorCfg.Validate()
+
+		// then
+		require.Len(t, errs, 6)
+		assert.Equal(t, `spec.memberStatus.refreshPeriod: Invalid value: "1 minute": value is not a valid duration`, errs[0].Error())
+		assert.Equal(t, `spec.toolchainCluster.healthCheckTimeout: Invalid value: "5": value is not a valid duration`, errs[1].Error())
+		assert.Equal(t, `spec.autoscaler.bufferMemory: Invalid value: "5GiB": value is not a valid quantity`, errs[2].Error())
+		assert.Equal(t, `spec.autoscaler.bufferReplicas: Invalid value: -1: value must not be negative`, errs[3].Error())
+		assert.Equal(t, `spec.memberStatus.gitHubSecret.accessTokenKey: Not found: "access-token"`, errs[4].Error())
+		assert.Equal(t, `spec.webhook.secret.ref: Not found: "unknown"`, errs[5].Error())
+		// the getters still fall back to the default values
+		assert.Equal(t, "5s", memberOperatorCfg.MemberStatus().RefreshPeriod().String())
+	})
+
+	t.Run("key without secret ref", func(t *testing.T) {
+		// given
+		cfg := commonconfig.NewMemberOperatorConfigWithReset(t, testconfig.MemberStatus().GitHubSecretAccessTokenKey("token"))
+
+		// when
+		errs := Validate(cfg, secrets)
+
+		// then
+		require.Len(t, errs, 1)
+		assert.Equal(t, `spec.memberStatus.gitHubSecret.ref: Required value: a secret must be referenced when a key is set`, errs[0].Error())
+	})
+}
+
+func TestValidateMemberOperatorConfig(t *testing.T) {
+	// given
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "github",
+			Namespace: test.MemberOperatorNs,
+		},
+		Data: map[string][]byte{
+			"token": []byte("abc"),
+		},
+	}
+	cl := test.NewFakeClient(t, secret)
+
+	t.Run("valid", func(t *testing.T) {
+		// given
+		cfg := commonconfig.NewMemberOperatorConfigWithReset(t, testconfig.MemberStatus().GitHubSecretRef("github").GitHubSecretAccessTokenKey("token"))
+
+		// when
+		err := ValidateMemberOperatorConfig(cl, cfg)
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		// given
+		cfg := commonconfig.NewMemberOperatorConfigWithReset(t,
+			testconfig.MemberStatus().GitHubSecretRef("github").GitHubSecretAccessTokenKey("access-token"),
+			testconfig.ToolchainCluster().HealthCheckPeriod("10 seconds"))
+
+		// when
+		err := ValidateMemberOperatorConfig(cl, cfg)
+
+		// then
+		require.Error(t, err)
+		assert.True(t, apierrors.IsInvalid(err))
+		assert.Equal(t, `MemberOperatorConfig.toolchain.dev.openshift.com "config" is invalid: [spec.toolchainCluster.healthCheckPeriod: Invalid value: "10 seconds": value is not a valid duration, spec.memberStatus.gitHubSecret.accessTokenKey: Not found: "access-token"]`, err.Error())
+	})
+}
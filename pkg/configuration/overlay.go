@@ -0,0 +1,195 @@
+package configuration
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	errs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// SourceConfigMap the value is set in the ConfigMap of an Overlay
+	SourceConfigMap Source = "ConfigMap"
+	// SourceEnv the value is set in an environment variable
+	SourceEnv Source = "env"
+)
+
+// Overlay the configuration values loaded from a ConfigMap and a Secret, which override the environment variables and
+// the default values, but not the values set in the configuration resource. In other words, the precedence of
+// the values is: CR > ConfigMap (or Secret) > env > default.
+type Overlay struct {
+	prefix    string
+	configMap map[string]string
+	secret    map[string]Sensitive
+}
+
+type overlayConfig struct {
+	configMapResourceKey string
+	secretResourceKey    string
+	exportToEnv          bool
+}
+
+// OverlayOption an option to configure the loading of an Overlay
+type OverlayOption func(*overlayConfig)
+
+// WithConfigMap loads the data of the ConfigMap whose name is set in the given env var
+func WithConfigMap(resourceKey string) OverlayOption {
+	return func(config *overlayConfig) {
+		config.configMapResourceKey = resourceKey
+	}
+}
+
+// WithSecret loads the data of the Secret whose name is set in the given env var.
+// The values of the Secret override the values of the ConfigMap with the same keys.
+func WithSecret(resourceKey string) OverlayOption {
+	return func(config *overlayConfig) {
+		config.secretResourceKey = resourceKey
+	}
+}
+
+// ExportToEnv also sets an environment variable for each key of the ConfigMap (eg, `HOST_OPERATOR_SUPER_SPECIAL_KEY`
+// for the `super-special-key` key with the `HOST_OPERATOR` prefix), as LoadFromConfigMap used to do.
+// This option modifies the environment of the process, so it should only be used for backwards compatibility.
+func ExportToEnv() OverlayOption {
+	return func(config *overlayConfig) {
+		config.exportToEnv = true
+	}
+}
+
+// LoadOverlay loads the ConfigMap and the Secret specified in the given options in the watch namespace,
+// without modifying the environment of the process (unless the ExportToEnv option is set).
+// The prefix (eg, HOST_OPERATOR or MEMBER_OPERATOR) is used to look up the environment variables of the configuration keys.
+// Missing ConfigMaps and Secrets are ignored.
+func LoadOverlay(cl client.Client, prefix string, options ...OverlayOption) (*Overlay, error) {
+	config := &overlayConfig{}
+	for _, apply := range options {
+		apply(config)
+	}
+	overlay := &Overlay{
+		prefix:    prefix,
+		configMap: map[string]string{},
+		secret:    map[string]Sensitive{},
+	}
+	if config.configMapResourceKey != "" {
+		configMapData, err := loadConfigMapData(config.configMapResourceKey, cl)
+		if err != nil {
+			return nil, err
+		}
+		overlay.configMap = configMapData
+	}
+	if config.secretResourceKey != "" {
+		secretData, err := LoadFromSecret(config.secretResourceKey, cl)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range secretData {
+			overlay.secret[key] = Sensitive(value)
+		}
+	}
+	if config.exportToEnv {
+		for key, value := range overlay.configMap {
+			if err := os.Setenv(createOperatorEnvVarKey(prefix, key), value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return overlay, nil
+}
+
+// loadConfigMapData retrieves the ConfigMap whose name is set in the given env var, and returns its data
+func loadConfigMapData(resourceKey string, cl client.Client) (map[string]string, error) {
+	data := map[string]string{}
+	configMapName := getResourceName(resourceKey)
+	if configMapName == "" {
+		return data, nil
+	}
+	namespace, err := GetWatchNamespace()
+	if err != nil {
+		return nil, err
+	}
+	configMap := &v1.ConfigMap{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: configMapName}, configMap); err != nil {
+		if !errs.IsNotFound(err) {
+			return nil, err
+		}
+		logf.Log.Info("configmap is not found")
+	}
+	for key, value := range configMap.Data {
+		data[key] = value
+	}
+	return data, nil
+}
+
+// Lookup returns the value of the given key (eg, `super-special-key`) in the Secret, the ConfigMap or the environment
+// variables (eg, `HOST_OPERATOR_SUPER_SPECIAL_KEY`), in that order, along with its source
+func (o *Overlay) Lookup(key string) (string, Source, bool) {
+	if value, found := o.secret[key]; found {
+		return value.Value(), SourceSecret, true
+	}
+	if value, found := o.configMap[key]; found {
+		return value, SourceConfigMap, true
+	}
+	if value, found := os.LookupEnv(createOperatorEnvVarKey(o.prefix, key)); found {
+		return value, SourceEnv, true
+	}
+	return "", SourceDefault, false
+}
+
+// GetString returns the value set in the CR if not nil, otherwise the value of the given key in the overlay, otherwise the default value
+func (o *Overlay) GetString(crValue *string, key string, defaultValue string) string {
+	if crValue != nil {
+		return *crValue
+	}
+	if value, _, found := o.Lookup(key); found {
+		return value
+	}
+	return defaultValue
+}
+
+// GetBool returns the value set in the CR if not nil, otherwise the value of the given key in the overlay, otherwise the default value.
+// The default value is also returned if the value in the overlay is not a valid bool.
+func (o *Overlay) GetBool(crValue *bool, key string, defaultValue bool) bool {
+	if crValue != nil {
+		return *crValue
+	}
+	return lookupAndParse(o, key, defaultValue, strconv.ParseBool)
+}
+
+// GetInt returns the value set in the CR if not nil, otherwise the value of the given key in the overlay, otherwise the default value.
+// The default value is also returned if the value in the overlay is not a valid int.
+func (o *Overlay) GetInt(crValue *int, key string, defaultValue int) int {
+	if crValue != nil {
+		return *crValue
+	}
+	return lookupAndParse(o, key, defaultValue, strconv.Atoi)
+}
+
+// GetDuration returns the value set in the CR if it is a valid duration, otherwise the value of the given key in the overlay,
+// otherwise the default value. The default value is also returned if the value in the overlay is not a valid duration.
+func (o *Overlay) GetDuration(crValue *string, key string, defaultValue time.Duration) time.Duration {
+	if crValue != nil {
+		if d, err := time.ParseDuration(*crValue); err == nil {
+			return d
+		}
+	}
+	return lookupAndParse(o, key, defaultValue, time.ParseDuration)
+}
+
+func lookupAndParse[T any](o *Overlay, key string, defaultValue T, parse func(string) (T, error)) T {
+	value, source, found := o.Lookup(key)
+	if !found {
+		return defaultValue
+	}
+	result, err := parse(value)
+	if err != nil {
+		logf.Log.Error(err, "invalid configuration value, using the default value instead", "key", key, "source", source)
+		return defaultValue
+	}
+	return result
+}
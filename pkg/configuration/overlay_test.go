@@ -0,0 +1,156 @@
+package configuration
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-common/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestLoadOverlay(t *testing.T) {
+	// given
+	restore := test.SetEnvVarsAndRestore(t,
+		test.Env("WATCH_NAMESPACE", test.HostOperatorNs),
+		test.Env("HOST_OPERATOR_CONFIG_MAP_NAME", "test-config"),
+		test.Env("HOST_OPERATOR_SECRET_NAME", "test-secret"),
+		test.Env("HOST_OPERATOR_ENV_ONLY", "from-env"),
+		test.Env("HOST_OPERATOR_CONFIG_MAP_KEY", "from-env"),
+		test.Env("HOST_OPERATOR_UNSET_KEY", ""))
+	defer restore()
+	configMap := createConfigMap("test-config", test.HostOperatorNs, map[string]string{
+		"config-map-key": "from-configmap",
+		"secret-key":     "from-configmap",
+		"timeout":        "30s",
+		"enabled":        "true",
+		"replicas":       "three",
+	})
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-secret",
+			Namespace: test.HostOperatorNs,
+		},
+		Data: map[string][]byte{
+			"secret-key": []byte("from-secret"),
+		},
+	}
+	cl := test.NewFakeClient(t, configMap, secret)
+
+	t.Run("precedence", func(t *testing.T) {
+		// when
+		overlay, err := LoadOverlay(cl, "HOST_OPERATOR", WithConfigMap("HOST_OPERATOR_CONFIG_MAP_NAME"), WithSecret("HOST_OPERATOR_SECRET_NAME"))
+
+		// then
+		require.NoError(t, err)
+		// CR > ConfigMap
+		assert.Equal(t, "from-cr", overlay.GetString(ptr.To("from-cr"), "config-map-key", "default"))
+		// ConfigMap > env
+		assert.Equal(t, "from-configmap", overlay.GetString(nil, "config-map-key", "default"))
+		// Secret > ConfigMap
+		assert.Equal(t, "from-secret", overlay.GetString(nil, "secret-key", "default"))
+		// env > default
+		assert.Equal(t, "from-env", overlay.GetString(nil, "env-only", "default"))
+		assert.Equal(t, "default", overlay.GetString(nil, "unknown", "default"))
+
+		value, source, found := overlay.Lookup("env-only")
+		assert.True(t, found)
+		assert.Equal(t, "from-env", value)
+		assert.Equal(t, SourceEnv, source)
+		value, source, found = overlay.Lookup("secret-key")
+		assert.True(t, found)
+		assert.Equal(t, "from-secret", value)
+		assert.Equal(t, SourceSecret, source)
+		value, source, found = overlay.Lookup("config-map-key")
+		assert.True(t, found)
+		assert.Equal(t, "from-configmap", value)
+		assert.Equal(t, SourceConfigMap, source)
+		_, source, found = overlay.Lookup("unknown")
+		assert.False(t, found)
+		assert.Equal(t, SourceDefault, source)
+
+		// the process environment is not modified
+		assert.Equal(t, "from-env", os.Getenv("HOST_OPERATOR_CONFIG_MAP_KEY"))
+		_, found = os.LookupEnv("HOST_OPERATOR_TIMEOUT")
+		assert.False(t, found)
+	})
+
+	t.Run("typed values", func(t *testing.T) {
+		// when
+		overlay, err := LoadOverlay(cl, "HOST_OPERATOR", WithConfigMap("HOST_OPERATOR_CONFIG_MAP_NAME"))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Second, overlay.GetDuration(nil, "timeout", time.Minute))
+		assert.Equal(t, 10*time.Second, overlay.GetDuration(ptr.To("10s"), "timeout", time.Minute))
+		assert.Equal(t, 30*time.Second, overlay.GetDuration(ptr.To("invalid"), "timeout", time.Minute))
+		assert.True(t, overlay.GetBool(nil, "enabled", false))
+		assert.False(t, overlay.GetBool(ptr.To(false), "enabled", true))
+		assert.Equal(t, 1, overlay.GetInt(nil, "replicas", 1)) // invalid value in the ConfigMap
+		assert.Equal(t, 2, overlay.GetInt(ptr.To(2), "replicas", 1))
+		assert.Equal(t, 5, overlay.GetInt(nil, "unknown", 5))
+	})
+
+	t.Run("no resources", func(t *testing.T) {
+		// when
+		overlay, err := LoadOverlay(test.NewFakeClient(t), "HOST_OPERATOR", WithConfigMap("HOST_OPERATOR_CONFIG_MAP_NAME"), WithSecret("HOST_OPERATOR_SECRET_NAME"))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "from-env", overlay.GetString(nil, "config-map-key", "default"))
+	})
+
+	t.Run("export to env", func(t *testing.T) {
+		// given
+		t.Cleanup(func() {
+			for _, key := range []string{"CONFIG_MAP_KEY", "SECRET_KEY", "TIMEOUT", "ENABLED", "REPLICAS"} {
+				require.NoError(t, os.Unsetenv("HOST_OPERATOR_"+key))
+			}
+		})
+
+		// when
+		_, err := LoadOverlay(cl, "HOST_OPERATOR", WithConfigMap("HOST_OPERATOR_CONFIG_MAP_NAME"), ExportToEnv())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "from-configmap", os.Getenv("HOST_OPERATOR_CONFIG_MAP_KEY"))
+		assert.Equal(t, "30s", os.Getenv("HOST_OPERATOR_TIMEOUT"))
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		// given
+		cl := test.NewFakeClient(t, configMap, secret)
+		cl.MockGet = func(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if _, ok := obj.(*v1.Secret); ok {
+				return errors.New("secret error")
+			}
+			return errors.New("configmap error")
+		}
+
+		t.Run("configmap", func(t *testing.T) {
+			// when
+			overlay, err := LoadOverlay(cl, "HOST_OPERATOR", WithConfigMap("HOST_OPERATOR_CONFIG_MAP_NAME"))
+
+			// then
+			require.EqualError(t, err, "configmap error")
+			assert.Nil(t, overlay)
+		})
+
+		t.Run("secret", func(t *testing.T) {
+			// when
+			overlay, err := LoadOverlay(cl, "HOST_OPERATOR", WithSecret("HOST_OPERATOR_SECRET_NAME"))
+
+			// then
+			require.EqualError(t, err, "secret error")
+			assert.Nil(t, overlay)
+		})
+	})
+}
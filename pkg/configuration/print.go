@@ -0,0 +1,14 @@
+package configuration
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// WriteEffectiveConfig writes the given effective configuration (ie, the configuration with all the default values resolved)
+// as indented JSON. Sensitive values are redacted, so the output is safe to share.
+func WriteEffectiveConfig(w io.Writer, effective interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(effective)
+}
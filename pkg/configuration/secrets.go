@@ -0,0 +1,82 @@
+package configuration
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var toolchainSecretType = reflect.TypeOf(toolchainv1alpha1.ToolchainSecret{})
+
+// SecretRefs returns the sorted names of the secrets referenced by the given configuration object,
+// ie, the `ref` of each ToolchainSecret in the object (eg, `GitHubSecret.Ref` or the webhook secret ref of a MemberOperatorConfig,
+// including the ones of the member configs in a ToolchainConfig)
+func SecretRefs(config runtime.Object) []string {
+	refs := map[string]bool{}
+	collectSecretRefs(reflect.ValueOf(config), refs)
+	names := make([]string, 0, len(refs))
+	for ref := range refs {
+		names = append(names, ref)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func collectSecretRefs(value reflect.Value, refs map[string]bool) {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !value.IsNil() {
+			collectSecretRefs(value.Elem(), refs)
+		}
+	case reflect.Struct:
+		if value.Type() == toolchainSecretType {
+			if secret, ok := value.Interface().(toolchainv1alpha1.ToolchainSecret); ok && secret.Ref != nil && *secret.Ref != "" {
+				refs[*secret.Ref] = true
+			}
+			return
+		}
+		for i := 0; i < value.NumField(); i++ {
+			if value.Type().Field(i).IsExported() {
+				collectSecretRefs(value.Field(i), refs)
+			}
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			collectSecretRefs(iter.Value(), refs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			collectSecretRefs(value.Index(i), refs)
+		}
+	}
+}
+
+// LoadReferencedSecrets retrieves the secrets with the given names in the provided namespace and indexes them into a map
+// by name along with their secret data. Secrets which do not exist are skipped.
+func LoadReferencedSecrets(cl client.Client, namespace string, names []string) (map[string]map[string]string, error) {
+	secrets := make(map[string]map[string]string, len(names))
+	for _, name := range names {
+		secret := &v1.Secret{}
+		if err := cl.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				cacheLog.Info("referenced secret was not found", "namespace", namespace, "name", name)
+				continue
+			}
+			return nil, err
+		}
+		secretData := make(map[string]string, len(secret.Data))
+		for key, value := range secret.Data {
+			secretData[key] = string(value)
+		}
+		secrets[secret.Name] = secretData
+	}
+	return secrets, nil
+}
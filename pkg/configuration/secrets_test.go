@@ -0,0 +1,115 @@
+package configuration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/test"
+	testconfig "github.com/codeready-toolchain/toolchain-common/pkg/test/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestSecretRefs(t *testing.T) {
+	t.Run("no secret", func(t *testing.T) {
+		assert.Empty(t, SecretRefs(testconfig.NewMemberOperatorConfigObj()))
+	})
+
+	t.Run("member operator config", func(t *testing.T) {
+		// given
+		config := testconfig.NewMemberOperatorConfigObj(
+			testconfig.MemberStatus().GitHubSecretRef("github").GitHubSecretAccessTokenKey("token"),
+			testconfig.Webhook().WebhookSecretRef("webhook").VMSSHKey("vmSSHKeys"))
+
+		// when
+		refs := SecretRefs(config)
+
+		// then
+		assert.Equal(t, []string{"github", "webhook"}, refs)
+	})
+
+	t.Run("toolchain config", func(t *testing.T) {
+		// given
+		memberConfig := testconfig.NewMemberOperatorConfigObj(testconfig.Webhook().WebhookSecretRef("webhook"))
+		otherMemberConfig := testconfig.NewMemberOperatorConfigObj(testconfig.MemberStatus().GitHubSecretRef("github"))
+		config := testconfig.NewToolchainConfigObj(t,
+			testconfig.Notifications().Secret().Ref("notifications"),
+			testconfig.Members().Default(memberConfig.Spec),
+			testconfig.Members().SpecificPerMemberCluster("member-1", otherMemberConfig.Spec),
+			testconfig.Members().SpecificPerMemberCluster("member-2", memberConfig.Spec))
+
+		// when
+		refs := SecretRefs(config)
+
+		// then
+		assert.Equal(t, []string{"github", "notifications", "webhook"}, refs)
+	})
+}
+
+func TestLoadReferencedSecrets(t *testing.T) {
+	// given
+	newSecret := func(name string, data map[string][]byte) *v1.Secret {
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: test.HostOperatorNs,
+			},
+			Type: v1.SecretTypeOpaque,
+			Data: data,
+		}
+	}
+	notificationSecret := newSecret("notification-secret", map[string][]byte{"mailgunAPIKey": []byte("abc123")})
+	tlsSecret := newSecret("tls-secret", map[string][]byte{"tls.key": []byte("key")})
+
+	t.Run("only referenced secrets are loaded", func(t *testing.T) {
+		// given
+		cl := test.NewFakeClient(t, notificationSecret, tlsSecret)
+
+		// when
+		secrets, err := LoadReferencedSecrets(cl, test.HostOperatorNs, []string{"notification-secret", "unknown"})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]map[string]string{
+			"notification-secret": {"mailgunAPIKey": "abc123"},
+		}, secrets)
+	})
+
+	t.Run("load latest", func(t *testing.T) {
+		// given
+		restore := test.SetEnvVarAndRestore(t, "WATCH_NAMESPACE", test.HostOperatorNs)
+		defer restore()
+		config := NewToolchainConfigObjWithReset(t, testconfig.Notifications().Secret().Ref("notification-secret"))
+		cl := test.NewFakeClient(t, config, notificationSecret, tlsSecret)
+
+		// when
+		_, secrets, err := LoadLatest(cl, &toolchainv1alpha1.ToolchainConfig{})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]map[string]string{
+			"notification-secret": {"mailgunAPIKey": "abc123"},
+		}, secrets)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		// given
+		cl := test.NewFakeClient(t, notificationSecret)
+		cl.MockGet = func(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			return fmt.Errorf("get error")
+		}
+
+		// when
+		secrets, err := LoadReferencedSecrets(cl, test.HostOperatorNs, []string{"notification-secret"})
+
+		// then
+		require.EqualError(t, err, "get error")
+		assert.Nil(t, secrets)
+	})
+}
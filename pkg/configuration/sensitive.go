@@ -0,0 +1,56 @@
+package configuration
+
+import "encoding/json"
+
+// Redacted the value which replaces a sensitive value when it is printed, marshalled or logged
+const Redacted = "[REDACTED]"
+
+// Sensitive a string value which must not be disclosed, such as the value of a key in a secret.
+// It redacts itself when it is formatted, marshalled to JSON or logged, so the actual value can only be obtained via Value().
+// An empty value is not redacted, so that it is visible that the value is missing.
+type Sensitive string
+
+func (s Sensitive) redacted() string {
+	if s == "" {
+		return ""
+	}
+	return Redacted
+}
+
+// Value returns the actual value
+func (s Sensitive) Value() string {
+	return string(s)
+}
+
+// String returns the redacted value, including with the `%s` and `%v` verbs
+func (s Sensitive) String() string {
+	return s.redacted()
+}
+
+// GoString returns the redacted value with the `%#v` verb
+func (s Sensitive) GoString() string {
+	return s.redacted()
+}
+
+// MarshalJSON marshals the redacted value
+func (s Sensitive) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.redacted())
+}
+
+// MarshalLog returns the redacted value (implements the logr.Marshaler interface)
+func (s Sensitive) MarshalLog() interface{} {
+	return s.redacted()
+}
+
+// ToSensitive returns a copy of the given secrets (indexed by secret name) in which all the values are Sensitive
+func ToSensitive(secrets map[string]map[string]string) map[string]map[string]Sensitive {
+	result := make(map[string]map[string]Sensitive, len(secrets))
+	for name, data := range secrets {
+		sensitiveData := make(map[string]Sensitive, len(data))
+		for k, v := range data {
+			sensitiveData[k] = Sensitive(v)
+		}
+		result[name] = sensitiveData
+	}
+	return result
+}
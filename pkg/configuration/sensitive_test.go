@@ -0,0 +1,71 @@
+package configuration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensitive(t *testing.T) {
+	// given
+	secret := Sensitive("s3cr3t")
+
+	t.Run("value", func(t *testing.T) {
+		assert.Equal(t, "s3cr3t", secret.Value())
+	})
+
+	t.Run("format", func(t *testing.T) {
+		for _, format := range []string{"%s", "%v", "%+v", "%#v"} {
+			assert.Equal(t, Redacted, fmt.Sprintf(format, secret))
+		}
+		assert.NotContains(t, fmt.Sprintf("%v", map[string]Sensitive{"token": secret}), "s3cr3t")
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, Sensitive("").String())
+		data, err := json.Marshal(Sensitive(""))
+		require.NoError(t, err)
+		assert.Equal(t, `""`, string(data))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		// when
+		data, err := json.Marshal(map[string]map[string]Sensitive{"github": {"token": secret}})
+
+		// then
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"github":{"token":"[REDACTED]"}}`, string(data))
+	})
+
+	t.Run("log", func(t *testing.T) {
+		// given
+		buf := &bytes.Buffer{}
+		logger := funcr.New(func(prefix, args string) {
+			buf.WriteString(args)
+		}, funcr.Options{})
+
+		// when
+		logger.Info("loaded secret", "token", secret)
+
+		// then
+		assert.Contains(t, buf.String(), `"token"="[REDACTED]"`)
+		assert.NotContains(t, buf.String(), "s3cr3t")
+	})
+}
+
+func TestToSensitive(t *testing.T) {
+	// when
+	secrets := ToSensitive(map[string]map[string]string{
+		"github": {"token": "s3cr3t"},
+	})
+
+	// then
+	assert.Equal(t, map[string]map[string]Sensitive{
+		"github": {"token": "s3cr3t"},
+	}, secrets)
+}
@@ -0,0 +1,220 @@
+package configuration
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// maxSectionDepth the depth of the paths in the spec which are reported as changed sections,
+// eg: `host.tiers` for a ToolchainConfig or `toolchainCluster.healthCheckPeriod` for a MemberOperatorConfig
+const maxSectionDepth = 2
+
+// ConfigChange contains the snapshots of the configuration object and of the secrets before and after an update of the cache,
+// along with the sections of the spec that changed.
+// The Old config is nil when the cache was empty before the update.
+type ConfigChange struct {
+	Old        runtime.Object
+	OldSecrets map[string]map[string]string
+	New        runtime.Object
+	NewSecrets map[string]map[string]string
+	Diff       ConfigDiff
+}
+
+// ConfigDiff the differences between two versions of the configuration
+type ConfigDiff struct {
+	// ChangedSections the sorted paths of the sections of the spec which were added, removed or modified,
+	// with the fields separated by dots (eg: `host.tiers` or `toolchainCluster.healthCheckPeriod`).
+	// Only the first levels of the spec are reported, so a change deeper in the spec is reported via its ancestor.
+	ChangedSections []string
+	// SecretsChanged `true` if the content of the secrets changed
+	SecretsChanged bool
+}
+
+// IsEmpty returns `true` if neither the spec nor the secrets changed
+func (d ConfigDiff) IsEmpty() bool {
+	return len(d.ChangedSections) == 0 && !d.SecretsChanged
+}
+
+// SectionChanged returns `true` if the section with the given path (eg: `toolchainCluster` or `toolchainCluster.healthCheckPeriod`)
+// or one of its subsections changed
+func (d ConfigDiff) SectionChanged(section string) bool {
+	for _, s := range d.ChangedSections {
+		if s == section || strings.HasPrefix(s, section+".") || strings.HasPrefix(section, s+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Listener a function called with the old and new snapshots of the configuration each time the cache is updated with changes.
+// Listeners are called synchronously after the cache was updated, so they should not block.
+type Listener func(change ConfigChange)
+
+type subscriptions struct {
+	sync.RWMutex
+	nextID    int
+	listeners map[int]Listener
+}
+
+func (s *subscriptions) add(listener Listener) func() {
+	s.Lock()
+	defer s.Unlock()
+	if s.listeners == nil {
+		s.listeners = map[int]Listener{}
+	}
+	id := s.nextID
+	s.nextID++
+	s.listeners[id] = listener
+	return func() {
+		s.Lock()
+		defer s.Unlock()
+		delete(s.listeners, id)
+	}
+}
+
+func (s *subscriptions) notify(change ConfigChange) {
+	s.RLock()
+	ids := make([]int, 0, len(s.listeners))
+	for id := range s.listeners {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	listeners := make([]Listener, 0, len(ids))
+	for _, id := range ids {
+		listeners = append(listeners, s.listeners[id])
+	}
+	s.RUnlock()
+
+	// listeners are notified in the order of their subscription, and each receives its own copy of the snapshots
+	for _, listener := range listeners {
+		listener(change.deepCopy())
+	}
+}
+
+func (c ConfigChange) deepCopy() ConfigChange {
+	result := ConfigChange{
+		OldSecrets: CopyOf(c.OldSecrets),
+		NewSecrets: CopyOf(c.NewSecrets),
+		Diff: ConfigDiff{
+			ChangedSections: append([]string{}, c.Diff.ChangedSections...),
+			SecretsChanged:  c.Diff.SecretsChanged,
+		},
+	}
+	if c.Old != nil {
+		result.Old = c.Old.DeepCopyObject()
+	}
+	if c.New != nil {
+		result.New = c.New.DeepCopyObject()
+	}
+	return result
+}
+
+// Subscribe registers the given listener, which is called each time the configuration in the cache changes,
+// ie, when UpdateConfig or LoadLatest store a config object or secrets that differ from the ones in the cache.
+// It returns a func to unsubscribe the listener.
+func Subscribe(listener Listener) (unsubscribe func()) {
+	return configCache.subscriptions.add(listener)
+}
+
+// SubscribeChannel registers the given channel, which receives the changes of the configuration in the cache.
+// The changes are sent without blocking, so a change is dropped (and logged) when the channel is full,
+// hence the channel should be buffered.
+// It returns a func to unsubscribe the channel.
+func SubscribeChannel(ch chan<- ConfigChange) (unsubscribe func()) {
+	return Subscribe(channelListener(ch))
+}
+
+func channelListener(ch chan<- ConfigChange) Listener {
+	return func(change ConfigChange) {
+		select {
+		case ch <- change:
+		default:
+			cacheLog.Info("dropping configuration change notification since the channel is full", "changedSections", change.Diff.ChangedSections)
+		}
+	}
+}
+
+// Diff returns the differences between the given old and new config objects and secrets.
+// The config objects can be nil (eg, when there was no config before).
+func Diff(oldConfig runtime.Object, oldSecrets map[string]map[string]string, newConfig runtime.Object, newSecrets map[string]map[string]string) (ConfigDiff, error) {
+	oldSpec, err := specOf(oldConfig)
+	if err != nil {
+		return ConfigDiff{}, err
+	}
+	newSpec, err := specOf(newConfig)
+	if err != nil {
+		return ConfigDiff{}, err
+	}
+	sections := diffSections("", oldSpec, newSpec, 1)
+	sort.Strings(sections)
+	return ConfigDiff{
+		ChangedSections: sections,
+		SecretsChanged:  !reflect.DeepEqual(nilIfEmpty(oldSecrets), nilIfEmpty(newSecrets)),
+	}, nil
+}
+
+func specOf(config runtime.Object) (map[string]interface{}, error) {
+	if config == nil || reflect.ValueOf(config).IsNil() {
+		return nil, nil
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(config)
+	if err != nil {
+		return nil, err
+	}
+	spec, _ := obj["spec"].(map[string]interface{})
+	return pruneEmpty(spec), nil
+}
+
+// pruneEmpty removes the empty sections from the given values, since the sections without `omitempty` are always set
+func pruneEmpty(values map[string]interface{}) map[string]interface{} {
+	for k, v := range values {
+		if m, ok := v.(map[string]interface{}); ok {
+			v = pruneEmpty(m)
+			values[k] = v
+		}
+		if v == nil || (reflect.ValueOf(v).Kind() == reflect.Map && reflect.ValueOf(v).Len() == 0) {
+			delete(values, k)
+		}
+	}
+	return values
+}
+
+// diffSections returns the paths of the fields which differ in the given maps, up to the maxSectionDepth
+func diffSections(prefix string, oldValues, newValues map[string]interface{}, depth int) []string {
+	keys := map[string]bool{}
+	for k := range oldValues {
+		keys[k] = true
+	}
+	for k := range newValues {
+		keys[k] = true
+	}
+	var sections []string
+	for k := range keys {
+		oldValue, newValue := oldValues[k], newValues[k]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		path := prefix + k
+		oldMap, oldIsMap := oldValue.(map[string]interface{})
+		newMap, newIsMap := newValue.(map[string]interface{})
+		if depth < maxSectionDepth && (oldIsMap || oldValue == nil) && (newIsMap || newValue == nil) {
+			if subSections := diffSections(path+".", oldMap, newMap, depth+1); len(subSections) > 0 {
+				sections = append(sections, subSections...)
+				continue
+			}
+		}
+		sections = append(sections, path)
+	}
+	return sections
+}
+
+func nilIfEmpty(secrets map[string]map[string]string) map[string]map[string]string {
+	if len(secrets) == 0 {
+		return nil
+	}
+	return secrets
+}
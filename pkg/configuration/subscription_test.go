@@ -0,0 +1,159 @@
+package configuration
+
+import (
+	"testing"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/test"
+	testconfig "github.com/codeready-toolchain/toolchain-common/pkg/test/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribe(t *testing.T) {
+	// given
+	t.Cleanup(ResetCache)
+	var changes []ConfigChange
+	unsubscribe := Subscribe(func(change ConfigChange) {
+		changes = append(changes, change)
+	})
+	config := NewToolchainConfigObjWithReset(t, testconfig.AutomaticApproval().Enabled(true))
+
+	t.Run("first config", func(t *testing.T) {
+		// when
+		UpdateConfig(config, nil)
+
+		// then
+		require.Len(t, changes, 1)
+		assert.Nil(t, changes[0].Old)
+		assert.Equal(t, config, changes[0].New)
+		assert.Equal(t, []string{"host.automaticApproval"}, changes[0].Diff.ChangedSections)
+		assert.False(t, changes[0].Diff.SecretsChanged)
+	})
+
+	t.Run("same config", func(t *testing.T) {
+		// when
+		UpdateConfig(config.DeepCopy(), map[string]map[string]string{})
+
+		// then
+		require.Len(t, changes, 1) // no new notification
+	})
+
+	t.Run("changed config and secrets", func(t *testing.T) {
+		// given
+		newConfig := config.DeepCopy()
+		testconfig.AutomaticApproval().Enabled(false).Apply(newConfig)
+		testconfig.Notifications().Secret().Ref("notifications").Apply(newConfig)
+		testconfig.Members().Default(testconfig.NewMemberOperatorConfigObj(testconfig.ToolchainCluster().HealthCheckPeriod("20s")).Spec).Apply(newConfig)
+		secrets := map[string]map[string]string{
+			"notifications": {"mailgunAPIKey": "abc"},
+		}
+
+		// when
+		UpdateConfig(newConfig, secrets)
+
+		// then
+		require.Len(t, changes, 2)
+		assert.Equal(t, config, changes[1].Old)
+		assert.Empty(t, changes[1].OldSecrets)
+		assert.Equal(t, newConfig, changes[1].New)
+		assert.Equal(t, secrets, changes[1].NewSecrets)
+		assert.Equal(t, []string{"host.automaticApproval", "host.notifications", "members.default"}, changes[1].Diff.ChangedSections)
+		assert.True(t, changes[1].Diff.SecretsChanged)
+		assert.True(t, changes[1].Diff.SectionChanged("host"))
+		assert.True(t, changes[1].Diff.SectionChanged("members.default.toolchainCluster"))
+		assert.False(t, changes[1].Diff.SectionChanged("host.tiers"))
+		assert.False(t, changes[1].Diff.SectionChanged("member"))
+
+		t.Run("snapshots are copies", func(t *testing.T) {
+			// when
+			changes[1].NewSecrets["notifications"]["mailgunAPIKey"] = "changed"
+
+			// then
+			_, cachedSecrets := GetCachedConfig()
+			assert.Equal(t, "abc", cachedSecrets["notifications"]["mailgunAPIKey"])
+		})
+	})
+
+	t.Run("unsubscribed", func(t *testing.T) {
+		// given
+		unsubscribe()
+
+		// when
+		UpdateConfig(config, nil)
+
+		// then
+		require.Len(t, changes, 2)
+	})
+}
+
+func TestSubscribeChannel(t *testing.T) {
+	// given
+	restore := test.SetEnvVarAndRestore(t, "WATCH_NAMESPACE", test.MemberOperatorNs)
+	defer restore()
+	t.Cleanup(ResetCache)
+	ch := make(chan ConfigChange, 1)
+	unsubscribe := SubscribeChannel(ch)
+	defer unsubscribe()
+	config := NewMemberOperatorConfigWithReset(t, testconfig.ToolchainCluster().HealthCheckPeriod("20s"))
+	cl := test.NewFakeClient(t, config)
+
+	t.Run("load latest", func(t *testing.T) {
+		// when
+		_, _, err := LoadLatest(cl, &toolchainv1alpha1.MemberOperatorConfig{})
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, ch, 1)
+		change := <-ch
+		assert.Equal(t, []string{"toolchainCluster.healthCheckPeriod"}, change.Diff.ChangedSections)
+	})
+
+	t.Run("channel is full", func(t *testing.T) {
+		// given
+		UpdateConfig(testconfig.NewMemberOperatorConfigObj(testconfig.ToolchainCluster().HealthCheckTimeout("5s")), nil)
+
+		// when
+		UpdateConfig(testconfig.NewMemberOperatorConfigObj(testconfig.ToolchainCluster().HealthCheckTimeout("6s")), nil)
+
+		// then
+		require.Len(t, ch, 1)
+		change := <-ch
+		// the second change was dropped
+		assert.Equal(t, []string{"toolchainCluster.healthCheckPeriod", "toolchainCluster.healthCheckTimeout"}, change.Diff.ChangedSections)
+	})
+}
+
+func TestDiff(t *testing.T) {
+	t.Run("no config", func(t *testing.T) {
+		// when
+		diff, err := Diff(nil, nil, nil, nil)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, diff.IsEmpty())
+	})
+
+	t.Run("typed nil config", func(t *testing.T) {
+		// given
+		var config *toolchainv1alpha1.MemberOperatorConfig
+
+		// when
+		diff, err := Diff(config, nil, testconfig.NewMemberOperatorConfigObj(testconfig.MemberEnvironment("dev")), nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"environment"}, diff.ChangedSections)
+	})
+
+	t.Run("removed section", func(t *testing.T) {
+		// when
+		diff, err := Diff(testconfig.NewMemberOperatorConfigObj(testconfig.Che().Namespace("che")), nil,
+			testconfig.NewMemberOperatorConfigObj(), nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"che.namespace"}, diff.ChangedSections)
+	})
+}
@@ -0,0 +1,87 @@
+package configuration
+
+import (
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// InvalidConfigurationReason the reason of the condition set on a configuration resource which did not pass the validation
+const InvalidConfigurationReason = "InvalidConfiguration"
+
+// ValidateDuration returns an error if the given value is set but cannot be parsed as a duration
+func ValidateDuration(path *field.Path, value *string) field.ErrorList {
+	if value == nil {
+		return nil
+	}
+	if _, err := time.ParseDuration(*value); err != nil {
+		return field.ErrorList{field.Invalid(path, *value, "value is not a valid duration")}
+	}
+	return nil
+}
+
+// ValidateNonNegativeInt returns an error if the given value is set and is negative
+func ValidateNonNegativeInt(path *field.Path, value *int) field.ErrorList {
+	if value == nil || *value >= 0 {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(path, *value, "value must not be negative")}
+}
+
+// ValidateQuantity returns an error if the given value is set but cannot be parsed as a resource quantity (eg, `50Mi`)
+func ValidateQuantity(path *field.Path, value *string) field.ErrorList {
+	if value == nil {
+		return nil
+	}
+	if _, err := resource.ParseQuantity(*value); err != nil {
+		return field.ErrorList{field.Invalid(path, *value, "value is not a valid quantity")}
+	}
+	return nil
+}
+
+// ValidateSecretKey returns an error if the key in the given `keyField` of the secret config at the given path is set,
+// but the secret is not referenced in its `ref` field, or if the referenced secret or the key in that secret
+// does not exist in the given secrets (indexed by secret name)
+func ValidateSecretKey[V string | Sensitive](secretPath *field.Path, secretRef *string, keyField string, key *string, secrets map[string]map[string]V) field.ErrorList {
+	if key == nil || *key == "" {
+		return nil
+	}
+	refPath := secretPath.Child("ref")
+	if secretRef == nil || *secretRef == "" {
+		return field.ErrorList{field.Required(refPath, "a secret must be referenced when a key is set")}
+	}
+	secret, found := secrets[*secretRef]
+	if !found {
+		return field.ErrorList{field.NotFound(refPath, *secretRef)}
+	}
+	if _, found := secret[*key]; !found {
+		return field.ErrorList{field.NotFound(secretPath.Child(keyField), *key)}
+	}
+	return nil
+}
+
+// NewInvalidError returns an `Invalid` API error for the configuration resource with the given kind and name
+// which contains all the given validation errors, or `nil` if there is no validation error.
+// This is the error that a validating webhook is expected to return to reject the resource.
+func NewInvalidError(kind schema.GroupKind, name string, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(kind, name, errs)
+}
+
+// NewInvalidConfigurationCondition returns a condition of the given type with the InvalidConfigurationReason reason
+// and a message listing all the given validation errors
+func NewInvalidConfigurationCondition(conditionType toolchainv1alpha1.ConditionType, errs field.ErrorList) toolchainv1alpha1.Condition {
+	return toolchainv1alpha1.Condition{
+		Type:    conditionType,
+		Status:  corev1.ConditionFalse,
+		Reason:  InvalidConfigurationReason,
+		Message: errs.ToAggregate().Error(),
+	}
+}
@@ -0,0 +1,111 @@
+package configuration
+
+import (
+	"testing"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidateDuration(t *testing.T) {
+	path := field.NewPath("spec", "period")
+
+	assert.Empty(t, ValidateDuration(path, nil))
+	assert.Empty(t, ValidateDuration(path, ptr.To("1m30s")))
+	errs := ValidateDuration(path, ptr.To("10 seconds"))
+	require.Len(t, errs, 1)
+	assert.Equal(t, `spec.period: Invalid value: "10 seconds": value is not a valid duration`, errs[0].Error())
+}
+
+func TestValidateNonNegativeInt(t *testing.T) {
+	path := field.NewPath("spec", "replicas")
+
+	assert.Empty(t, ValidateNonNegativeInt(path, nil))
+	assert.Empty(t, ValidateNonNegativeInt(path, ptr.To(0)))
+	errs := ValidateNonNegativeInt(path, ptr.To(-1))
+	require.Len(t, errs, 1)
+	assert.Equal(t, `spec.replicas: Invalid value: -1: value must not be negative`, errs[0].Error())
+}
+
+func TestValidateQuantity(t *testing.T) {
+	path := field.NewPath("spec", "memory")
+
+	assert.Empty(t, ValidateQuantity(path, nil))
+	assert.Empty(t, ValidateQuantity(path, ptr.To("50Mi")))
+	errs := ValidateQuantity(path, ptr.To("5GiB"))
+	require.Len(t, errs, 1)
+	assert.Equal(t, `spec.memory: Invalid value: "5GiB": value is not a valid quantity`, errs[0].Error())
+}
+
+func TestValidateSecretKey(t *testing.T) {
+	// given
+	path := field.NewPath("spec", "secret")
+	secrets := map[string]map[string]string{
+		"github": {
+			"token": "abc",
+		},
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		assert.Empty(t, ValidateSecretKey(path, nil, "tokenKey", nil, secrets))
+		assert.Empty(t, ValidateSecretKey(path, ptr.To("github"), "tokenKey", ptr.To("token"), secrets))
+	})
+
+	t.Run("missing ref", func(t *testing.T) {
+		errs := ValidateSecretKey(path, nil, "tokenKey", ptr.To("token"), secrets)
+		require.Len(t, errs, 1)
+		assert.Equal(t, `spec.secret.ref: Required value: a secret must be referenced when a key is set`, errs[0].Error())
+	})
+
+	t.Run("unknown secret", func(t *testing.T) {
+		errs := ValidateSecretKey(path, ptr.To("gitlab"), "tokenKey", ptr.To("token"), secrets)
+		require.Len(t, errs, 1)
+		assert.Equal(t, `spec.secret.ref: Not found: "gitlab"`, errs[0].Error())
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		errs := ValidateSecretKey(path, ptr.To("github"), "tokenKey", ptr.To("access-token"), secrets)
+		require.Len(t, errs, 1)
+		assert.Equal(t, `spec.secret.tokenKey: Not found: "access-token"`, errs[0].Error())
+	})
+}
+
+func TestNewInvalidError(t *testing.T) {
+	// given
+	kind := toolchainv1alpha1.GroupVersion.WithKind("ToolchainConfig").GroupKind()
+
+	t.Run("no error", func(t *testing.T) {
+		assert.NoError(t, NewInvalidError(kind, "config", field.ErrorList{}))
+	})
+
+	t.Run("with errors", func(t *testing.T) {
+		// when
+		err := NewInvalidError(kind, "config", ValidateDuration(field.NewPath("spec", "period"), ptr.To("1 day")))
+
+		// then
+		require.Error(t, err)
+		assert.True(t, apierrors.IsInvalid(err))
+		assert.Equal(t, `ToolchainConfig.toolchain.dev.openshift.com "config" is invalid: spec.period: Invalid value: "1 day": value is not a valid duration`, err.Error())
+	})
+}
+
+func TestNewInvalidConfigurationCondition(t *testing.T) {
+	// given
+	errs := append(ValidateDuration(field.NewPath("spec", "period"), ptr.To("1 day")),
+		ValidateNonNegativeInt(field.NewPath("spec", "replicas"), ptr.To(-2))...)
+
+	// when
+	cond := NewInvalidConfigurationCondition(toolchainv1alpha1.ConditionReady, errs)
+
+	// then
+	assert.Equal(t, toolchainv1alpha1.ConditionReady, cond.Type)
+	assert.Equal(t, corev1.ConditionFalse, cond.Status)
+	assert.Equal(t, InvalidConfigurationReason, cond.Reason)
+	assert.Equal(t, `[spec.period: Invalid value: "1 day": value is not a valid duration, spec.replicas: Invalid value: -2: value must not be negative]`, cond.Message)
+}
@@ -2,11 +2,23 @@ package hash
 
 import (
 	"encoding/json"
+	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 
 	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
 )
 
+const (
+	// HashVersion1 the original hash algorithm, which covers the namespace and cluster resources template refs.
+	// Hashes computed with this version have no prefix.
+	HashVersion1 = 1
+	// HashVersion2 the hash algorithm which covers the space roles template refs and the parameters of the tier too.
+	// Hashes computed with this version are prefixed with `v2-`.
+	HashVersion2 = 2
+)
+
 // TemplateTierHashLabelKey returns the label key to specify the version of the templates of the given tier
 func TemplateTierHashLabelKey(tierName string) string {
 	return toolchainv1alpha1.LabelKeyPrefix + tierName + "-tier-hash"
@@ -36,7 +48,93 @@ func ComputeHashForNSTemplateSetSpec(s toolchainv1alpha1.NSTemplateSetSpec) (str
 	return computeHash(refs)
 }
 
+// ComputeHashForNSTemplateTierWithVersion computes the hash of the given tier with the given version of the algorithm.
+// With HashVersion1, the result is the same as with ComputeHashForNSTemplateTier. With HashVersion2, the hash also
+// covers the `.spec.spaceRoles[].templateRef` and the `.spec.parameters`, and it is prefixed with `v2-`
+func ComputeHashForNSTemplateTierWithVersion(tier *toolchainv1alpha1.NSTemplateTier, version int) (string, error) {
+	switch version {
+	case HashVersion1:
+		return ComputeHashForNSTemplateTier(tier)
+	case HashVersion2:
+		refs := []string{}
+		for _, ns := range tier.Spec.Namespaces {
+			refs = append(refs, ns.TemplateRef)
+		}
+		if tier.Spec.ClusterResources != nil {
+			refs = append(refs, tier.Spec.ClusterResources.TemplateRef)
+		}
+		sort.Strings(refs)
+		spaceRoles := make(map[string]string, len(tier.Spec.SpaceRoles))
+		for role, spaceRole := range tier.Spec.SpaceRoles {
+			spaceRoles[role] = spaceRole.TemplateRef
+		}
+		params := make(map[string]string, len(tier.Spec.Parameters))
+		for _, p := range tier.Spec.Parameters {
+			params[p.Name] = p.Value
+		}
+		// maps are marshalled with sorted keys, so the hash is predictive too
+		m, err := json.Marshal(templateRefsV2{Refs: refs, SpaceRoles: spaceRoles, Parameters: params})
+		if err != nil {
+			return "", err
+		}
+		return versionPrefix(HashVersion2) + Encode(m), nil
+	default:
+		return "", fmt.Errorf("unsupported hash version: %d", version)
+	}
+}
+
+// HashVersion returns the version of the algorithm that was used to compute the given hash
+func HashVersion(h string) int {
+	if strings.HasPrefix(h, "v") {
+		if i := strings.Index(h, "-"); i > 1 {
+			if version, err := strconv.Atoi(h[1:i]); err == nil {
+				return version
+			}
+		}
+	}
+	return HashVersion1
+}
+
+// TierHashLabelMatches returns `true` if the given hash (typically the value of the label with the TemplateTierHashLabelKey key)
+// matches the hash of the given tier, computed with the same version of the algorithm as the given hash.
+// This allows to accept both the hashes that were computed before and after a new version of the algorithm was rolled out.
+func TierHashLabelMatches(tmplTier *toolchainv1alpha1.NSTemplateTier, h string) bool {
+	if h == "" {
+		return false
+	}
+	tierHash, err := ComputeHashForNSTemplateTierWithVersion(tmplTier, HashVersion(h))
+	if err != nil {
+		return false
+	}
+	return tierHash == h
+}
+
+func versionPrefix(version int) string {
+	return fmt.Sprintf("v%d-", version)
+}
+
+// TierHashMatches returns `true` if the namespace and cluster resources template refs in the given NSTemplateSet spec
+// match the ones of the given tier. It is the same as TierHashMatchesWithVersion with HashVersion1
 func TierHashMatches(tmplTier *toolchainv1alpha1.NSTemplateTier, nsTmplSetSpec toolchainv1alpha1.NSTemplateSetSpec) bool {
+	return TierHashMatchesWithVersion(tmplTier, nsTmplSetSpec, HashVersion1)
+}
+
+// TierHashMatchesWithVersion returns `true` if the template refs in the given NSTemplateSet spec match the ones of the given tier.
+// With HashVersion2, the space roles template refs of the NSTemplateSet must also be part of the space roles of the tier
+// (an NSTemplateSet only contains the space roles which have users, so the tier may have more).
+// The parameters of the tier are not taken into account since they are not part of the NSTemplateSet spec.
+func TierHashMatchesWithVersion(tmplTier *toolchainv1alpha1.NSTemplateTier, nsTmplSetSpec toolchainv1alpha1.NSTemplateSetSpec, version int) bool {
+	if version >= HashVersion2 {
+		spaceRoleRefs := make(map[string]bool, len(tmplTier.Spec.SpaceRoles))
+		for _, spaceRole := range tmplTier.Spec.SpaceRoles {
+			spaceRoleRefs[spaceRole.TemplateRef] = true
+		}
+		for _, spaceRole := range nsTmplSetSpec.SpaceRoles {
+			if !spaceRoleRefs[spaceRole.TemplateRef] {
+				return false
+			}
+		}
+	}
 	tierHash, err := ComputeHashForNSTemplateTier(tmplTier)
 	if err != nil {
 		return false
@@ -53,6 +151,12 @@ type templateRefs struct {
 	Refs []string `json:"refs"`
 }
 
+type templateRefsV2 struct {
+	Refs       []string          `json:"refs"`
+	SpaceRoles map[string]string `json:"spaceRoles"`
+	Parameters map[string]string `json:"parameters"`
+}
+
 func computeHash(refs []string) (string, error) {
 	// sort the refs to make sure we have a predictive hash!
 	sort.Strings(refs)
@@ -161,3 +161,189 @@ func TestTierHashMatches(t *testing.T) {
 	})
 
 }
+
+func TestComputeHashForNSTemplateTierWithVersion(t *testing.T) {
+	// given
+	tier := &toolchainv1alpha1.NSTemplateTier{
+		Spec: toolchainv1alpha1.NSTemplateTierSpec{
+			Namespaces: []toolchainv1alpha1.NSTemplateTierNamespace{
+				{
+					TemplateRef: "base1ns-dev-aeb78eb-aeb78eb",
+				},
+			},
+			ClusterResources: &toolchainv1alpha1.NSTemplateTierClusterResources{
+				TemplateRef: "base1ns-clusterresources-e0e1f34-e0e1f34",
+			},
+			SpaceRoles: map[string]toolchainv1alpha1.NSTemplateTierSpaceRole{
+				"viewer": {
+					TemplateRef: "base1ns-viewer-e0e1f34-e0e1f34",
+				},
+				"admin": {
+					TemplateRef: "base1ns-admin-e0e1f34-e0e1f34",
+				},
+			},
+			Parameters: []toolchainv1alpha1.Parameter{
+				{
+					Name:  "IDLER_TIMEOUT_SECONDS",
+					Value: "43200",
+				},
+			},
+		},
+	}
+
+	t.Run("version 1", func(t *testing.T) {
+		// when
+		h, err := hash.ComputeHashForNSTemplateTierWithVersion(tier, hash.HashVersion1)
+		// then
+		require.NoError(t, err)
+		expected, err := hash.ComputeHashForNSTemplateTier(tier)
+		require.NoError(t, err)
+		assert.Equal(t, expected, h)
+		assert.Equal(t, hash.HashVersion1, hash.HashVersion(h))
+	})
+
+	t.Run("version 2", func(t *testing.T) {
+		// when
+		h, err := hash.ComputeHashForNSTemplateTierWithVersion(tier, hash.HashVersion2)
+		// then
+		require.NoError(t, err)
+		// verify hash
+		md5hash := md5.New() // nolint:gosec
+		_, _ = md5hash.Write([]byte(`{"refs":["base1ns-clusterresources-e0e1f34-e0e1f34","base1ns-dev-aeb78eb-aeb78eb"],"spaceRoles":{"admin":"base1ns-admin-e0e1f34-e0e1f34","viewer":"base1ns-viewer-e0e1f34-e0e1f34"},"parameters":{"IDLER_TIMEOUT_SECONDS":"43200"}}`))
+		expected := "v2-" + hex.EncodeToString(md5hash.Sum(nil))
+		assert.Equal(t, expected, h)
+		assert.Equal(t, hash.HashVersion2, hash.HashVersion(h))
+
+		t.Run("changes with space roles", func(t *testing.T) {
+			// given
+			other := tier.DeepCopy()
+			other.Spec.SpaceRoles["admin"] = toolchainv1alpha1.NSTemplateTierSpaceRole{TemplateRef: "base1ns-admin-1234567-1234567"}
+			// when
+			otherHash, err := hash.ComputeHashForNSTemplateTierWithVersion(other, hash.HashVersion2)
+			// then
+			require.NoError(t, err)
+			assert.NotEqual(t, h, otherHash)
+		})
+
+		t.Run("changes with parameters", func(t *testing.T) {
+			// given
+			other := tier.DeepCopy()
+			other.Spec.Parameters[0].Value = "3600"
+			// when
+			otherHash, err := hash.ComputeHashForNSTemplateTierWithVersion(other, hash.HashVersion2)
+			// then
+			require.NoError(t, err)
+			assert.NotEqual(t, h, otherHash)
+		})
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		// when
+		_, err := hash.ComputeHashForNSTemplateTierWithVersion(tier, 3)
+		// then
+		require.EqualError(t, err, "unsupported hash version: 3")
+	})
+}
+
+func TestHashVersion(t *testing.T) {
+	for h, expected := range map[string]int{
+		"":                                    hash.HashVersion1,
+		"1ba3d8f4ae1fc8fa8fc1ac5d9d2b3d6b":    hash.HashVersion1,
+		"v2-1ba3d8f4ae1fc8fa8fc1ac5d9d2b3d6b": hash.HashVersion2,
+		"v12-abcd":                            12,
+		"vx-abcd":                             hash.HashVersion1,
+	} {
+		t.Run(h, func(t *testing.T) {
+			assert.Equal(t, expected, hash.HashVersion(h))
+		})
+	}
+}
+
+func TestTierHashLabelMatches(t *testing.T) {
+	// given
+	tier := &toolchainv1alpha1.NSTemplateTier{
+		Spec: toolchainv1alpha1.NSTemplateTierSpec{
+			Namespaces: []toolchainv1alpha1.NSTemplateTierNamespace{
+				{
+					TemplateRef: "base1ns-dev-aeb78eb-aeb78eb",
+				},
+			},
+			SpaceRoles: map[string]toolchainv1alpha1.NSTemplateTierSpaceRole{
+				"admin": {
+					TemplateRef: "base1ns-admin-e0e1f34-e0e1f34",
+				},
+			},
+		},
+	}
+	v1Hash, err := hash.ComputeHashForNSTemplateTierWithVersion(tier, hash.HashVersion1)
+	require.NoError(t, err)
+	v2Hash, err := hash.ComputeHashForNSTemplateTierWithVersion(tier, hash.HashVersion2)
+	require.NoError(t, err)
+
+	t.Run("should match both versions", func(t *testing.T) {
+		assert.True(t, hash.TierHashLabelMatches(tier, v1Hash))
+		assert.True(t, hash.TierHashLabelMatches(tier, v2Hash))
+	})
+
+	t.Run("should not match after space roles changed", func(t *testing.T) {
+		// given
+		updated := tier.DeepCopy()
+		updated.Spec.SpaceRoles["admin"] = toolchainv1alpha1.NSTemplateTierSpaceRole{TemplateRef: "base1ns-admin-1234567-1234567"}
+		// then
+		assert.True(t, hash.TierHashLabelMatches(updated, v1Hash)) // version 1 does not cover the space roles
+		assert.False(t, hash.TierHashLabelMatches(updated, v2Hash))
+	})
+
+	t.Run("should not match empty or unsupported hash", func(t *testing.T) {
+		assert.False(t, hash.TierHashLabelMatches(tier, ""))
+		assert.False(t, hash.TierHashLabelMatches(tier, "v3-"+v1Hash))
+	})
+}
+
+func TestTierHashMatchesWithVersion(t *testing.T) {
+	// given
+	tier := &toolchainv1alpha1.NSTemplateTier{
+		Spec: toolchainv1alpha1.NSTemplateTierSpec{
+			Namespaces: []toolchainv1alpha1.NSTemplateTierNamespace{
+				{
+					TemplateRef: "base1ns-dev-aeb78eb-aeb78eb",
+				},
+			},
+			SpaceRoles: map[string]toolchainv1alpha1.NSTemplateTierSpaceRole{
+				"admin": {
+					TemplateRef: "base1ns-admin-e0e1f34-e0e1f34",
+				},
+				"viewer": {
+					TemplateRef: "base1ns-viewer-e0e1f34-e0e1f34",
+				},
+			},
+		},
+	}
+	s := toolchainv1alpha1.NSTemplateSetSpec{
+		Namespaces: []toolchainv1alpha1.NSTemplateSetNamespace{
+			{
+				TemplateRef: "base1ns-dev-aeb78eb-aeb78eb",
+			},
+		},
+		SpaceRoles: []toolchainv1alpha1.NSTemplateSetSpaceRole{
+			{
+				TemplateRef: "base1ns-admin-e0e1f34-e0e1f34",
+				Usernames:   []string{"john"},
+			},
+		},
+	}
+
+	t.Run("should match", func(t *testing.T) {
+		assert.True(t, hash.TierHashMatchesWithVersion(tier, s, hash.HashVersion1))
+		assert.True(t, hash.TierHashMatchesWithVersion(tier, s, hash.HashVersion2))
+	})
+
+	t.Run("should not match on space roles with version 2 only", func(t *testing.T) {
+		// given
+		outdated := s.DeepCopy()
+		outdated.SpaceRoles[0].TemplateRef = "base1ns-admin-1234567-1234567"
+		// then
+		assert.True(t, hash.TierHashMatchesWithVersion(tier, *outdated, hash.HashVersion1))
+		assert.False(t, hash.TierHashMatchesWithVersion(tier, *outdated, hash.HashVersion2))
+	})
+}
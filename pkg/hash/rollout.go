@@ -0,0 +1,135 @@
+package hash
+
+import (
+	"context"
+	"sort"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/pkg/errors"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClusterRolloutStatus the number of resources using a given tier in a member cluster, and how many of them are outdated
+type ClusterRolloutStatus struct {
+	Total    int `json:"total"`
+	Outdated int `json:"outdated"`
+}
+
+// UpToDate returns the number of resources which are already using the latest version of the tier
+func (s ClusterRolloutStatus) UpToDate() int {
+	return s.Total - s.Outdated
+}
+
+// TierRolloutStatus the rollout status of an NSTemplateTier, ie, how many Spaces and NSTemplateSets are still
+// using an outdated version of the tier, indexed by member cluster name.
+// Spaces which are not provisioned on any cluster yet are indexed by the empty string.
+type TierRolloutStatus struct {
+	TierName string `json:"tierName"`
+	// Hash the latest version of the hash of the tier
+	Hash           string                          `json:"hash"`
+	Spaces         map[string]ClusterRolloutStatus `json:"spaces"`
+	NSTemplateSets map[string]ClusterRolloutStatus `json:"nsTemplateSets"`
+}
+
+// OutdatedSpaces returns the total number of outdated Spaces across all clusters
+func (s TierRolloutStatus) OutdatedSpaces() int {
+	return sumOutdated(s.Spaces)
+}
+
+// OutdatedNSTemplateSets returns the total number of outdated NSTemplateSets across all clusters
+func (s TierRolloutStatus) OutdatedNSTemplateSets() int {
+	return sumOutdated(s.NSTemplateSets)
+}
+
+// Clusters returns the sorted names of all the clusters in which there are Spaces or NSTemplateSets using the tier
+func (s TierRolloutStatus) Clusters() []string {
+	clusters := map[string]bool{}
+	for c := range s.Spaces {
+		clusters[c] = true
+	}
+	for c := range s.NSTemplateSets {
+		clusters[c] = true
+	}
+	names := make([]string, 0, len(clusters))
+	for c := range clusters {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sumOutdated(statuses map[string]ClusterRolloutStatus) int {
+	outdated := 0
+	for _, s := range statuses {
+		outdated += s.Outdated
+	}
+	return outdated
+}
+
+// NewTierRolloutStatus computes the rollout status of the given tier, based on the given Spaces (from the host cluster)
+// and NSTemplateSets (indexed by member cluster name). Resources which do not use the given tier are ignored.
+//
+// A Space is outdated when its tier hash label does not match the hash of the tier (computed with the same version
+// of the algorithm as the label value), and an NSTemplateSet is outdated when its template refs do not match the ones of the tier.
+func NewTierRolloutStatus(tier *toolchainv1alpha1.NSTemplateTier, spaces []toolchainv1alpha1.Space, nsTemplateSetsByCluster map[string][]toolchainv1alpha1.NSTemplateSet) (*TierRolloutStatus, error) {
+	tierHash, err := ComputeHashForNSTemplateTierWithVersion(tier, HashVersion2)
+	if err != nil {
+		return nil, err
+	}
+	status := &TierRolloutStatus{
+		TierName:       tier.Name,
+		Hash:           tierHash,
+		Spaces:         map[string]ClusterRolloutStatus{},
+		NSTemplateSets: map[string]ClusterRolloutStatus{},
+	}
+
+	hashLabelKey := TemplateTierHashLabelKey(tier.Name)
+	for _, space := range spaces {
+		if space.Spec.TierName != tier.Name {
+			continue
+		}
+		cluster := space.Status.TargetCluster
+		if cluster == "" {
+			cluster = space.Spec.TargetCluster
+		}
+		clusterStatus := status.Spaces[cluster]
+		clusterStatus.Total++
+		if !TierHashLabelMatches(tier, space.Labels[hashLabelKey]) {
+			clusterStatus.Outdated++
+		}
+		status.Spaces[cluster] = clusterStatus
+	}
+
+	for cluster, nsTemplateSets := range nsTemplateSetsByCluster {
+		for _, nsTemplateSet := range nsTemplateSets {
+			if nsTemplateSet.Spec.TierName != tier.Name {
+				continue
+			}
+			clusterStatus := status.NSTemplateSets[cluster]
+			clusterStatus.Total++
+			if !TierHashMatchesWithVersion(tier, nsTemplateSet.Spec, HashVersion2) {
+				clusterStatus.Outdated++
+			}
+			status.NSTemplateSets[cluster] = clusterStatus
+		}
+	}
+	return status, nil
+}
+
+// GetTierRolloutStatus lists the Spaces in the namespace of the given tier via the host client, and the NSTemplateSets
+// via each of the given member clients (indexed by member cluster name), and computes the rollout status of the tier
+func GetTierRolloutStatus(ctx context.Context, hostClient runtimeclient.Reader, memberClients map[string]runtimeclient.Reader, tier *toolchainv1alpha1.NSTemplateTier) (*TierRolloutStatus, error) {
+	spaces := &toolchainv1alpha1.SpaceList{}
+	if err := hostClient.List(ctx, spaces, runtimeclient.InNamespace(tier.Namespace)); err != nil {
+		return nil, errors.Wrapf(err, "unable to list the Spaces in namespace '%s'", tier.Namespace)
+	}
+	nsTemplateSetsByCluster := make(map[string][]toolchainv1alpha1.NSTemplateSet, len(memberClients))
+	for cluster, memberClient := range memberClients {
+		nsTemplateSets := &toolchainv1alpha1.NSTemplateSetList{}
+		if err := memberClient.List(ctx, nsTemplateSets); err != nil {
+			return nil, errors.Wrapf(err, "unable to list the NSTemplateSets in cluster '%s'", cluster)
+		}
+		nsTemplateSetsByCluster[cluster] = nsTemplateSets.Items
+	}
+	return NewTierRolloutStatus(tier, spaces.Items, nsTemplateSetsByCluster)
+}
@@ -0,0 +1,134 @@
+package hash_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/hash"
+	"github.com/codeready-toolchain/toolchain-common/pkg/test"
+	"github.com/codeready-toolchain/toolchain-common/pkg/test/nstemplateset"
+	spacetest "github.com/codeready-toolchain/toolchain-common/pkg/test/space"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestNewTierRolloutStatus(t *testing.T) {
+	// given
+	tier := newNSTemplateTier("base1ns", "base1ns-dev-aeb78eb-aeb78eb")
+	previousTier := newNSTemplateTier("base1ns", "base1ns-dev-1234567-1234567")
+	otherTier := newNSTemplateTier("advanced", "advanced-dev-aeb78eb-aeb78eb")
+	tier.Spec.SpaceRoles = map[string]toolchainv1alpha1.NSTemplateTierSpaceRole{
+		"admin": {TemplateRef: "base1ns-admin-aeb78eb-aeb78eb"},
+	}
+	previousTier.Spec.SpaceRoles = map[string]toolchainv1alpha1.NSTemplateTierSpaceRole{
+		"admin": {TemplateRef: "base1ns-admin-1234567-1234567"},
+	}
+	// only the space roles differ
+	previousRolesTier := tier.DeepCopy()
+	previousRolesTier.Spec.SpaceRoles = previousTier.Spec.SpaceRoles
+
+	spaces := []toolchainv1alpha1.Space{
+		*spacetest.NewSpace(test.HostOperatorNs, "up-to-date", spacetest.WithTierNameAndHashLabelFor(tier), spacetest.WithStatusTargetCluster("member-1")),
+		*spacetest.NewSpace(test.HostOperatorNs, "outdated", spacetest.WithTierNameAndHashLabelFor(previousTier), spacetest.WithStatusTargetCluster("member-1")),
+		*spacetest.NewSpace(test.HostOperatorNs, "up-to-date-v2", spacetest.WithTierName(tier.Name), spacetest.WithVersionedTierHashLabelFor(tier, hash.HashVersion2), spacetest.WithStatusTargetCluster("member-1")),
+		*spacetest.NewSpace(test.HostOperatorNs, "outdated-roles-v2", spacetest.WithTierName(tier.Name), spacetest.WithVersionedTierHashLabelFor(previousRolesTier, hash.HashVersion2), spacetest.WithStatusTargetCluster("member-1")),
+		*spacetest.NewSpace(test.HostOperatorNs, "no-label", spacetest.WithTierName(tier.Name), spacetest.WithStatusTargetCluster("member-2")),
+		*spacetest.NewSpace(test.HostOperatorNs, "not-provisioned", spacetest.WithTierName(tier.Name), spacetest.WithSpecTargetCluster("member-2")),
+		*spacetest.NewSpace(test.HostOperatorNs, "other-tier", spacetest.WithTierNameAndHashLabelFor(otherTier), spacetest.WithStatusTargetCluster("member-1")),
+	}
+	nsTemplateSets := map[string][]toolchainv1alpha1.NSTemplateSet{
+		"member-1": {
+			*nstemplateset.NewNSTemplateSet("up-to-date", nstemplateset.WithReferencesFor(tier)),
+			*nstemplateset.NewNSTemplateSet("outdated", nstemplateset.WithReferencesFor(previousTier)),
+			*nstemplateset.NewNSTemplateSet("outdated-roles", nstemplateset.WithReferencesFor(previousRolesTier, nstemplateset.WithSpaceRole("admin", "john"))),
+			*nstemplateset.NewNSTemplateSet("other-tier", nstemplateset.WithReferencesFor(otherTier)),
+		},
+		"member-2": {
+			*nstemplateset.NewNSTemplateSet("no-label", nstemplateset.WithReferencesFor(tier)),
+		},
+	}
+
+	// when
+	status, err := hash.NewTierRolloutStatus(tier, spaces, nsTemplateSets)
+
+	// then
+	require.NoError(t, err)
+	expectedHash, err := hash.ComputeHashForNSTemplateTierWithVersion(tier, hash.HashVersion2)
+	require.NoError(t, err)
+	assert.Equal(t, "base1ns", status.TierName)
+	assert.Equal(t, expectedHash, status.Hash)
+	assert.Equal(t, map[string]hash.ClusterRolloutStatus{
+		"member-1": {Total: 4, Outdated: 2},
+		"member-2": {Total: 2, Outdated: 2},
+	}, status.Spaces)
+	assert.Equal(t, map[string]hash.ClusterRolloutStatus{
+		"member-1": {Total: 3, Outdated: 2},
+		"member-2": {Total: 1, Outdated: 0},
+	}, status.NSTemplateSets)
+	assert.Equal(t, 4, status.OutdatedSpaces())
+	assert.Equal(t, 2, status.OutdatedNSTemplateSets())
+	assert.Equal(t, 2, status.Spaces["member-1"].UpToDate())
+	assert.Equal(t, []string{"member-1", "member-2"}, status.Clusters())
+}
+
+func TestGetTierRolloutStatus(t *testing.T) {
+	// given
+	tier := newNSTemplateTier("base1ns", "base1ns-dev-aeb78eb-aeb78eb")
+	previousTier := newNSTemplateTier("base1ns", "base1ns-dev-1234567-1234567")
+	hostClient := test.NewFakeClient(t,
+		spacetest.NewSpace(test.HostOperatorNs, "up-to-date", spacetest.WithTierNameAndHashLabelFor(tier), spacetest.WithStatusTargetCluster(test.MemberClusterName)),
+		spacetest.NewSpace(test.HostOperatorNs, "outdated", spacetest.WithTierNameAndHashLabelFor(previousTier), spacetest.WithStatusTargetCluster(test.MemberClusterName)),
+	)
+	memberClient := test.NewFakeClient(t,
+		nstemplateset.NewNSTemplateSet("up-to-date", nstemplateset.WithReferencesFor(tier)),
+		nstemplateset.NewNSTemplateSet("outdated", nstemplateset.WithReferencesFor(previousTier)),
+	)
+
+	t.Run("ok", func(t *testing.T) {
+		// when
+		status, err := hash.GetTierRolloutStatus(context.TODO(), hostClient, map[string]runtimeclient.Reader{test.MemberClusterName: memberClient}, tier)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]hash.ClusterRolloutStatus{test.MemberClusterName: {Total: 2, Outdated: 1}}, status.Spaces)
+		assert.Equal(t, map[string]hash.ClusterRolloutStatus{test.MemberClusterName: {Total: 2, Outdated: 1}}, status.NSTemplateSets)
+	})
+
+	t.Run("failed to list NSTemplateSets", func(t *testing.T) {
+		// given
+		failingClient := test.NewFakeClient(t)
+		failingClient.MockList = func(_ context.Context, _ runtimeclient.ObjectList, _ ...runtimeclient.ListOption) error {
+			return fmt.Errorf("mock error")
+		}
+
+		// when
+		_, err := hash.GetTierRolloutStatus(context.TODO(), hostClient, map[string]runtimeclient.Reader{test.MemberClusterName: failingClient}, tier)
+
+		// then
+		require.EqualError(t, err, "unable to list the NSTemplateSets in cluster 'member-cluster': mock error")
+	})
+}
+
+func newNSTemplateTier(name, devTemplateRef string) *toolchainv1alpha1.NSTemplateTier {
+	return &toolchainv1alpha1.NSTemplateTier{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: test.HostOperatorNs,
+			Name:      name,
+		},
+		Spec: toolchainv1alpha1.NSTemplateTierSpec{
+			Namespaces: []toolchainv1alpha1.NSTemplateTierNamespace{
+				{
+					TemplateRef: devTemplateRef,
+				},
+			},
+			ClusterResources: &toolchainv1alpha1.NSTemplateTierClusterResources{
+				TemplateRef: name + "-clusterresources-e0e1f34-e0e1f34",
+			},
+		},
+	}
+}
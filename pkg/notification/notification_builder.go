@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"net/mail"
 	"strconv"
+	"strings"
 
-	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/hash"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -27,6 +30,22 @@ type Builder interface {
 	WithKeysAndValues(keysAndValues map[string]string) Builder
 	WithUserContext(userSignup *toolchainv1alpha1.UserSignup) Builder
 	WithUserTierContext(userTier *toolchainv1alpha1.UserTier) Builder
+	// WithTo adds recipients to the one given to Create. Each address may be a comma-separated list.
+	WithTo(addresses ...string) Builder
+	// WithCC adds the recipients which receive a carbon copy of the notification. Each address may be a comma-separated list.
+	WithCC(addresses ...string) Builder
+	// WithBCC adds the recipients which receive a blind carbon copy of the notification. Each address may be a comma-separated list.
+	WithBCC(addresses ...string) Builder
+	// WithReplyTo adds the addresses to which the replies should be sent. Each address may be a comma-separated list.
+	WithReplyTo(addresses ...string) Builder
+	// WithIdempotencyKey derives the name of the notification from the given key (eg, the UID of the UserSignup along with the
+	// notification type and the generation), instead of generating a random name. If a notification with the same name
+	// already exists (eg, when retrying after a failed status update), then Create returns it instead of a duplicate.
+	WithIdempotencyKey(key string) Builder
+	// Create creates the notification for the given recipient, which may be empty if recipients were added with WithTo.
+	// The given recipient is stored as is in the Recipient field of the notification, unless recipients were added with
+	// WithTo, WithCC or WithBCC, in which case the recipients are stored as a normalised, comma-separated list.
+	// The addresses are validated one by one, and de-duplicated so that an address is only used once, in the To, CC and BCC order.
 	Create(ctx context.Context, recipient string) (*toolchainv1alpha1.Notification, error)
 }
 
@@ -42,12 +61,26 @@ type notificationBuilderImpl struct {
 	client    client.Client
 	namespace string
 	options   []Option
+	to        []string
+	cc        []string
+	bcc       []string
+	replyTo   []string
+	// idempotencyKey the key from which the name of the notification is derived, if not empty
+	idempotencyKey string
 }
 
 func (b *notificationBuilderImpl) Create(ctx context.Context, recipient string) (*toolchainv1alpha1.Notification, error) {
-
-	if list, err := mail.ParseAddressList(recipient); err != nil || len(list) == 0 {
-		return nil, errors.Wrap(err, fmt.Sprintf("The specified recipient [%s] is not a valid email address", recipient))
+	to := b.to
+	if recipient != "" || len(b.to) == 0 {
+		to = append([]string{recipient}, b.to...)
+	}
+	recipients, err := parseRecipients(to, b.cc, b.bcc, b.replyTo)
+	if err != nil {
+		return nil, err
+	}
+	if len(b.to) > 0 || len(b.cc) > 0 || len(b.bcc) > 0 {
+		// the recipients are combined into a single, normalised list
+		recipient = formatAddresses(recipients.To)
 	}
 
 	notification := &toolchainv1alpha1.Notification{
@@ -61,6 +94,10 @@ func (b *notificationBuilderImpl) Create(ctx context.Context, recipient string)
 		},
 	}
 
+	setAddressesAnnotation(notification, CCAnnotationKey, recipients.CC)
+	setAddressesAnnotation(notification, BCCAnnotationKey, recipients.BCC)
+	setAddressesAnnotation(notification, ReplyToAnnotationKey, recipients.ReplyTo)
+
 	for _, opt := range b.options {
 		err := opt(notification)
 		if err != nil {
@@ -68,11 +105,55 @@ func (b *notificationBuilderImpl) Create(ctx context.Context, recipient string)
 		}
 	}
 
+	if b.idempotencyKey != "" {
+		return b.createIdempotent(ctx, notification)
+	}
+
 	generateName(notification)
 
 	return notification, b.client.Create(ctx, notification)
 }
 
+// createIdempotent creates the given notification with a name derived from the idempotency key (unless it was set with WithName),
+// or returns the existing notification with the same name
+func (b *notificationBuilderImpl) createIdempotent(ctx context.Context, notification *toolchainv1alpha1.Notification) (*toolchainv1alpha1.Notification, error) {
+	if notification.Name == "" {
+		notification.Name = idempotentName(notification, b.idempotencyKey)
+	}
+	err := b.client.Create(ctx, notification)
+	if err == nil {
+		return notification, nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return notification, err
+	}
+	existing := &toolchainv1alpha1.Notification{}
+	if err := b.client.Get(ctx, client.ObjectKeyFromObject(notification), existing); err != nil {
+		return notification, errors.Wrapf(err, "unable to get the existing notification '%s'", notification.Name)
+	}
+	return existing, nil
+}
+
+// maxNamePrefixLength the maximum length of the prefix of the names derived from an idempotency key, so that the names
+// (with the hash of the key) are valid label values as well
+const maxNamePrefixLength = 30
+
+// idempotentName returns the name of the notification derived from the given idempotency key, eg, `johnsmith-deactivated-<hash of the key>`
+func idempotentName(notification *toolchainv1alpha1.Notification, idempotencyKey string) string {
+	notificationType, found := notification.Labels[toolchainv1alpha1.NotificationTypeLabelKey]
+	if !found || notificationType == "" {
+		notificationType = "untyped"
+	}
+	prefix := notificationType
+	if username := notification.Spec.Context["UserName"]; username != "" {
+		prefix = username + "-" + notificationType
+	}
+	if len(prefix) > maxNamePrefixLength {
+		prefix = prefix[:maxNamePrefixLength]
+	}
+	return strings.ToLower(strings.TrimSuffix(prefix, "-")) + "-" + hash.EncodeString(idempotencyKey)
+}
+
 func generateName(notification *toolchainv1alpha1.Notification) {
 	if notification.ObjectMeta.Name == "" {
 		if username, found := notification.Spec.Context["UserName"]; found && username != "" {
@@ -88,6 +169,16 @@ func generateName(notification *toolchainv1alpha1.Notification) {
 	}
 }
 
+func setAddressesAnnotation(notification *toolchainv1alpha1.Notification, key string, addresses []*mail.Address) {
+	if len(addresses) == 0 {
+		return
+	}
+	if notification.Annotations == nil {
+		notification.Annotations = map[string]string{}
+	}
+	notification.Annotations[key] = formatAddresses(addresses)
+}
+
 func (b *notificationBuilderImpl) WithName(name string) Builder {
 	b.options = append(b.options, func(n *toolchainv1alpha1.Notification) error {
 		n.ObjectMeta.Name = name
@@ -170,3 +261,28 @@ func (b *notificationBuilderImpl) WithUserTierContext(userTier *toolchainv1alpha
 	})
 	return b
 }
+
+func (b *notificationBuilderImpl) WithTo(addresses ...string) Builder {
+	b.to = append(b.to, addresses...)
+	return b
+}
+
+func (b *notificationBuilderImpl) WithCC(addresses ...string) Builder {
+	b.cc = append(b.cc, addresses...)
+	return b
+}
+
+func (b *notificationBuilderImpl) WithBCC(addresses ...string) Builder {
+	b.bcc = append(b.bcc, addresses...)
+	return b
+}
+
+func (b *notificationBuilderImpl) WithReplyTo(addresses ...string) Builder {
+	b.replyTo = append(b.replyTo, addresses...)
+	return b
+}
+
+func (b *notificationBuilderImpl) WithIdempotencyKey(key string) Builder {
+	b.idempotencyKey = key
+	return b
+}
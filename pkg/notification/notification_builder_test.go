@@ -2,6 +2,7 @@ package notification
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -13,6 +14,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestNotificationBuilder(t *testing.T) {
@@ -217,4 +220,224 @@ func TestNotificationBuilder(t *testing.T) {
 		assert.Equal(t, "TestNotificationType", notification.Labels[toolchainv1alpha1.NotificationTypeLabelKey])
 		assert.False(t, strings.HasPrefix(notification.Name, "-"))
 	})
+
+	t.Run("with multiple recipients", func(t *testing.T) {
+		t.Run("success with to, cc, bcc and reply-to", func(t *testing.T) {
+			// when
+			notification, err := NewNotificationBuilder(client, test.HostOperatorNs).
+				WithTo("Alice <alice@example.com>", "bob@example.com, carol@example.com").
+				WithCC("dave@example.com").
+				WithBCC("Eve <eve@example.com>").
+				WithReplyTo("support@example.com").
+				Create(context.TODO(), "admin@example.com")
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, `admin@example.com, "Alice" <alice@example.com>, bob@example.com, carol@example.com`, notification.Spec.Recipient)
+			assert.Equal(t, map[string]string{
+				CCAnnotationKey:      "dave@example.com",
+				BCCAnnotationKey:     `"Eve" <eve@example.com>`,
+				ReplyToAnnotationKey: "support@example.com",
+			}, notification.Annotations)
+		})
+
+		t.Run("success with recipients only added with WithTo", func(t *testing.T) {
+			// when
+			notification, err := NewNotificationBuilder(client, test.HostOperatorNs).
+				WithTo("alice@example.com", "bob@example.com").
+				Create(context.TODO(), "")
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, "alice@example.com, bob@example.com", notification.Spec.Recipient)
+			assert.Empty(t, notification.Annotations)
+		})
+
+		t.Run("recipient normalised when only cc is added", func(t *testing.T) {
+			// when
+			notification, err := NewNotificationBuilder(client, test.HostOperatorNs).
+				WithCC("dave@example.com").
+				Create(context.TODO(), "Alice <alice@example.com>,bob@example.com")
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, `"Alice" <alice@example.com>, bob@example.com`, notification.Spec.Recipient)
+			assert.Equal(t, map[string]string{
+				CCAnnotationKey: "dave@example.com",
+			}, notification.Annotations)
+		})
+
+		t.Run("duplicate addresses are removed", func(t *testing.T) {
+			// when
+			notification, err := NewNotificationBuilder(client, test.HostOperatorNs).
+				WithTo("alice@example.com", "Alice <ALICE@example.com>", "bob@example.com").
+				WithCC("bob@example.com", "carol@example.com", "Carol <carol@example.com>").
+				WithBCC("alice@example.com", "carol@example.com").
+				WithReplyTo("alice@example.com", "alice@example.com").
+				Create(context.TODO(), "")
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, "alice@example.com, bob@example.com", notification.Spec.Recipient)
+			assert.Equal(t, "carol@example.com", notification.Annotations[CCAnnotationKey])
+			assert.NotContains(t, notification.Annotations, BCCAnnotationKey) // all the BCC addresses are already recipients
+			assert.Equal(t, "alice@example.com", notification.Annotations[ReplyToAnnotationKey])
+		})
+
+		t.Run("fail without any recipient", func(t *testing.T) {
+			// when
+			_, err := NewNotificationBuilder(client, test.HostOperatorNs).
+				WithCC("alice@example.com").
+				Create(context.TODO(), "")
+
+			// then
+			require.EqualError(t, err, "The specified recipient [] is not a valid email address: mail: no address")
+		})
+
+		t.Run("fail with invalid addresses", func(t *testing.T) {
+			// when
+			_, err := NewNotificationBuilder(client, test.HostOperatorNs).
+				WithTo("alice@example.com", "bob").
+				WithCC("carol@example.com").
+				WithBCC("dave@").
+				WithReplyTo("<eve").
+				Create(context.TODO(), "admin@example.com")
+
+			// then
+			require.EqualError(t, err, "["+
+				"The specified recipient [bob] is not a valid email address: mail: missing '@' or angle-addr, "+
+				"The specified BCC address [dave@] is not a valid email address: mail: missing '@' or angle-addr, "+
+				"The specified reply-to address [<eve] is not a valid email address: mail: missing @ in addr-spec]")
+		})
+	})
+}
+
+func TestNotificationBuilderWithIdempotencyKey(t *testing.T) {
+	// given
+	userSignup := testusersignup.NewUserSignup()
+	userSignup.Status.CompliantUsername = "jsmith"
+	key := fmt.Sprintf("%s-%s-%d", userSignup.UID, toolchainv1alpha1.NotificationTypeDeactivated, userSignup.Generation)
+	newBuilder := func(cl runtimeclient.Client) Builder {
+		return NewNotificationBuilder(cl, test.HostOperatorNs).
+			WithNotificationType(toolchainv1alpha1.NotificationTypeDeactivated).
+			WithUserContext(userSignup).
+			WithIdempotencyKey(key)
+	}
+
+	t.Run("name derived from the key", func(t *testing.T) {
+		// given
+		cl := test.NewFakeClient(t)
+
+		// when
+		notification, err := newBuilder(cl).Create(context.TODO(), "foo@acme.com")
+
+		// then
+		require.NoError(t, err)
+		assert.Regexp(t, "^jsmith-deactivated-[0-9a-f]{32}$", notification.Name)
+		assert.Empty(t, notification.GenerateName)
+
+		t.Run("same name with the same key", func(t *testing.T) {
+			// when
+			other, err := newBuilder(test.NewFakeClient(t)).Create(context.TODO(), "foo@acme.com")
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, notification.Name, other.Name)
+		})
+
+		t.Run("different name with a different key", func(t *testing.T) {
+			// when
+			other, err := newBuilder(test.NewFakeClient(t)).
+				WithIdempotencyKey(key+"-1").
+				Create(context.TODO(), "foo@acme.com")
+
+			// then
+			require.NoError(t, err)
+			assert.NotEqual(t, notification.Name, other.Name)
+		})
+
+		t.Run("retry returns the existing notification", func(t *testing.T) {
+			// when
+			retried, err := newBuilder(cl).
+				WithSubjectAndContent("changed", "changed").
+				Create(context.TODO(), "foo@acme.com")
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, notification.Name, retried.Name)
+			assert.Empty(t, retried.Spec.Subject) // the existing notification is returned as is
+			notifications := &toolchainv1alpha1.NotificationList{}
+			require.NoError(t, cl.List(context.TODO(), notifications, runtimeclient.InNamespace(test.HostOperatorNs)))
+			assert.Len(t, notifications.Items, 1)
+		})
+	})
+
+	t.Run("name without username nor type", func(t *testing.T) {
+		// when
+		notification, err := NewNotificationBuilder(test.NewFakeClient(t), test.HostOperatorNs).
+			WithIdempotencyKey(key).
+			Create(context.TODO(), "foo@acme.com")
+
+		// then
+		require.NoError(t, err)
+		assert.Regexp(t, "^untyped-[0-9a-f]{32}$", notification.Name)
+	})
+
+	t.Run("name set with WithName", func(t *testing.T) {
+		// given
+		cl := test.NewFakeClient(t)
+		_, err := newBuilder(cl).WithName("custom").Create(context.TODO(), "foo@acme.com")
+		require.NoError(t, err)
+
+		// when
+		notification, err := newBuilder(cl).WithName("custom").Create(context.TODO(), "foo@acme.com")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "custom", notification.Name)
+	})
+
+	t.Run("already exists without idempotency key", func(t *testing.T) {
+		// given
+		cl := test.NewFakeClient(t)
+		_, err := NewNotificationBuilder(cl, test.HostOperatorNs).WithName("custom").Create(context.TODO(), "foo@acme.com")
+		require.NoError(t, err)
+
+		// when
+		_, err = NewNotificationBuilder(cl, test.HostOperatorNs).WithName("custom").Create(context.TODO(), "foo@acme.com")
+
+		// then
+		require.True(t, apierrors.IsAlreadyExists(err))
+	})
+
+	t.Run("fail to get the existing notification", func(t *testing.T) {
+		// given
+		cl := test.NewFakeClient(t)
+		cl.MockCreate = func(ctx context.Context, obj runtimeclient.Object, opts ...runtimeclient.CreateOption) error {
+			return apierrors.NewAlreadyExists(schema.GroupResource{Group: "toolchain.dev.openshift.com", Resource: "notifications"}, obj.GetName())
+		}
+		cl.MockGet = func(ctx context.Context, key runtimeclient.ObjectKey, obj runtimeclient.Object, opts ...runtimeclient.GetOption) error {
+			return fmt.Errorf("mock error")
+		}
+
+		// when
+		notification, err := newBuilder(cl).Create(context.TODO(), "foo@acme.com")
+
+		// then
+		require.EqualError(t, err, fmt.Sprintf("unable to get the existing notification '%s': mock error", notification.Name))
+	})
+
+	t.Run("fail to create", func(t *testing.T) {
+		// given
+		cl := test.NewFakeClient(t)
+		cl.MockCreate = func(ctx context.Context, obj runtimeclient.Object, opts ...runtimeclient.CreateOption) error {
+			return fmt.Errorf("mock error")
+		}
+
+		// when
+		_, err := newBuilder(cl).Create(context.TODO(), "foo@acme.com")
+
+		// then
+		require.EqualError(t, err, "mock error")
+	})
 }
@@ -0,0 +1,129 @@
+package notification
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+
+	"github.com/pkg/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// The annotations which contain the additional recipients of a notification, since the NotificationSpec only has a Recipient field.
+// Each annotation contains a comma-separated list of RFC 5322 addresses, eg: `John Smith <jsmith@example.com>, jdoe@example.com`
+const (
+	CCAnnotationKey = toolchainv1alpha1.LabelKeyPrefix + "notification-cc"
+	// BCCAnnotationKey the blind carbon copy addresses are hidden from the other recipients of the email only:
+	// they are readable by anyone who can read the Notification resource
+	BCCAnnotationKey     = toolchainv1alpha1.LabelKeyPrefix + "notification-bcc"
+	ReplyToAnnotationKey = toolchainv1alpha1.LabelKeyPrefix + "notification-reply-to"
+)
+
+// Recipients the addresses of a notification
+type Recipients struct {
+	To      []*mail.Address
+	CC      []*mail.Address
+	BCC     []*mail.Address
+	ReplyTo []*mail.Address
+}
+
+// GetRecipients returns the addresses of the given notification, ie, the addresses of its Recipient field along with
+// the addresses of its CC, BCC and reply-to annotations
+func GetRecipients(notification *toolchainv1alpha1.Notification) (Recipients, error) {
+	var recipients Recipients
+	var err error
+	if recipients.To, err = parseAddresses(notification.Spec.Recipient); err != nil {
+		return Recipients{}, errors.Wrapf(err, "invalid recipient in the notification '%s'", notification.Name)
+	}
+	for _, a := range []struct {
+		key       string
+		addresses *[]*mail.Address
+	}{
+		{key: CCAnnotationKey, addresses: &recipients.CC},
+		{key: BCCAnnotationKey, addresses: &recipients.BCC},
+		{key: ReplyToAnnotationKey, addresses: &recipients.ReplyTo},
+	} {
+		value, found := notification.Annotations[a.key]
+		if !found {
+			continue
+		}
+		if *a.addresses, err = parseAddresses(value); err != nil {
+			return Recipients{}, errors.Wrapf(err, "invalid '%s' annotation in the notification '%s'", a.key, notification.Name)
+		}
+	}
+	return recipients, nil
+}
+
+// addressKind the kind of address, used in the validation errors
+type addressKind string
+
+const (
+	toAddress      addressKind = "recipient"
+	ccAddress      addressKind = "CC address"
+	bccAddress     addressKind = "BCC address"
+	replyToAddress addressKind = "reply-to address"
+)
+
+// parseRecipients validates the given addresses, each of which may be a comma-separated list, and returns them
+// de-duplicated: an address is kept only where it first appears (in the To, CC then BCC order).
+// The reply-to addresses are de-duplicated separately, since they are not recipients.
+// All the invalid addresses are reported in the returned error.
+func parseRecipients(to, cc, bcc, replyTo []string) (Recipients, error) {
+	var errs []error
+	parse := func(kind addressKind, values []string, seen map[string]bool) []*mail.Address {
+		var result []*mail.Address
+		for _, value := range values {
+			addresses, err := parseAddresses(value)
+			if err != nil {
+				errs = append(errs, errors.Wrap(err, fmt.Sprintf("The specified %s [%s] is not a valid email address", kind, value)))
+				continue
+			}
+			for _, address := range addresses {
+				key := strings.ToLower(address.Address)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				result = append(result, address)
+			}
+		}
+		return result
+	}
+	seen := map[string]bool{}
+	recipients := Recipients{
+		To:      parse(toAddress, to, seen),
+		CC:      parse(ccAddress, cc, seen),
+		BCC:     parse(bccAddress, bcc, seen),
+		ReplyTo: parse(replyToAddress, replyTo, map[string]bool{}),
+	}
+	if len(errs) > 0 {
+		return Recipients{}, utilerrors.NewAggregate(errs)
+	}
+	return recipients, nil
+}
+
+func parseAddresses(value string) ([]*mail.Address, error) {
+	addresses, err := mail.ParseAddressList(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("mail: no address")
+	}
+	return addresses, nil
+}
+
+// formatAddresses returns the given addresses as a comma-separated list, without the angle brackets around the addresses without name
+func formatAddresses(addresses []*mail.Address) string {
+	formatted := make([]string, len(addresses))
+	for i, address := range addresses {
+		if address.Name == "" {
+			formatted[i] = address.Address
+			continue
+		}
+		formatted[i] = address.String()
+	}
+	return strings.Join(formatted, ", ")
+}
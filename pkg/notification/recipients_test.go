@@ -0,0 +1,94 @@
+package notification
+
+import (
+	"net/mail"
+	"testing"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetRecipients(t *testing.T) {
+	t.Run("with all the recipients", func(t *testing.T) {
+		// given
+		notification := &toolchainv1alpha1.Notification{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "admin-notification",
+				Annotations: map[string]string{
+					CCAnnotationKey:      "bob@example.com",
+					BCCAnnotationKey:     `"Carol" <carol@example.com>, dave@example.com`,
+					ReplyToAnnotationKey: "support@example.com",
+				},
+			},
+			Spec: toolchainv1alpha1.NotificationSpec{
+				Recipient: "Alice <alice@example.com>",
+			},
+		}
+
+		// when
+		recipients, err := GetRecipients(notification)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Recipients{
+			To:      []*mail.Address{{Name: "Alice", Address: "alice@example.com"}},
+			CC:      []*mail.Address{{Address: "bob@example.com"}},
+			BCC:     []*mail.Address{{Name: "Carol", Address: "carol@example.com"}, {Address: "dave@example.com"}},
+			ReplyTo: []*mail.Address{{Address: "support@example.com"}},
+		}, recipients)
+	})
+
+	t.Run("without annotations", func(t *testing.T) {
+		// given
+		notification := &toolchainv1alpha1.Notification{
+			Spec: toolchainv1alpha1.NotificationSpec{
+				Recipient: "alice@example.com, bob@example.com",
+			},
+		}
+
+		// when
+		recipients, err := GetRecipients(notification)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Recipients{
+			To: []*mail.Address{{Address: "alice@example.com"}, {Address: "bob@example.com"}},
+		}, recipients)
+	})
+
+	t.Run("invalid recipient", func(t *testing.T) {
+		// given
+		notification := &toolchainv1alpha1.Notification{
+			ObjectMeta: metav1.ObjectMeta{Name: "admin-notification"},
+			Spec:       toolchainv1alpha1.NotificationSpec{},
+		}
+
+		// when
+		_, err := GetRecipients(notification)
+
+		// then
+		require.EqualError(t, err, "invalid recipient in the notification 'admin-notification': mail: no address")
+	})
+
+	t.Run("invalid annotation", func(t *testing.T) {
+		// given
+		notification := &toolchainv1alpha1.Notification{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "admin-notification",
+				Annotations: map[string]string{CCAnnotationKey: "bob"},
+			},
+			Spec: toolchainv1alpha1.NotificationSpec{
+				Recipient: "alice@example.com",
+			},
+		}
+
+		// when
+		_, err := GetRecipients(notification)
+
+		// then
+		require.EqualError(t, err, "invalid 'toolchain.dev.openshift.com/notification-cc' annotation in the notification 'admin-notification': mail: missing '@' or angle-addr")
+	})
+}
@@ -0,0 +1,323 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"text/template/parse"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+
+	"github.com/pkg/errors"
+)
+
+// The files of a notification template, within the directory named after the template
+const (
+	// SubjectFileName the template of the subject of the notification
+	SubjectFileName = "subject.txt"
+	// HTMLContentFileName the template of the HTML content of the notification
+	HTMLContentFileName = "notification.html"
+	// TextContentFileName the template of the plain text content of the notification
+	TextContentFileName = "notification.txt"
+)
+
+// TemplateSet the notification templates loaded from a directory, in which each sub-directory contains the templates
+// of the notification with the same name (eg, `userdeactivated/subject.txt` and `userdeactivated/notification.html`).
+// A notification template has a subject, along with an HTML content, a plain text content, or both.
+type TemplateSet struct {
+	templates map[string]*Template
+}
+
+// Template the templates of the subject and the contents of a notification
+type Template struct {
+	Name    string
+	subject *texttemplate.Template
+	html    *htmltemplate.Template
+	text    *texttemplate.Template
+	keys    []string
+}
+
+// RenderedNotification the subject and the contents of a notification rendered with its context
+type RenderedNotification struct {
+	Recipients Recipients
+	Subject    string
+	// HTML the HTML content, empty if the template only has a plain text content
+	HTML string
+	// Text the plain text content, empty if the template only has an HTML content
+	Text string
+}
+
+// LoadTemplateSetFromDir loads the notification templates from the given directory
+func LoadTemplateSetFromDir(dir string) (*TemplateSet, error) {
+	return LoadTemplateSet(os.DirFS(dir))
+}
+
+// LoadTemplateSet loads the notification templates from the root of the given file system (eg, an `embed.FS` or an `os.DirFS`)
+func LoadTemplateSet(fsys fs.FS) (*TemplateSet, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the notification templates")
+	}
+	set := &TemplateSet{
+		templates: map[string]*Template{},
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		tmpl, err := loadTemplate(fsys, entry.Name())
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to load the notification template '%s'", entry.Name())
+		}
+		set.templates[tmpl.Name] = tmpl
+	}
+	return set, nil
+}
+
+func loadTemplate(fsys fs.FS, name string) (*Template, error) {
+	tmpl := &Template{Name: name}
+	subject, err := fs.ReadFile(fsys, path.Join(name, SubjectFileName))
+	if err != nil {
+		return nil, err
+	}
+	// the subject is a single line, even if the file ends with a new line
+	if tmpl.subject, err = texttemplate.New(SubjectFileName).Option("missingkey=error").Parse(strings.TrimSpace(string(subject))); err != nil {
+		return nil, err
+	}
+	trees := []*parse.Tree{tmpl.subject.Tree}
+
+	html, err := readOptionalFile(fsys, path.Join(name, HTMLContentFileName))
+	if err != nil {
+		return nil, err
+	}
+	if html != nil {
+		if tmpl.html, err = htmltemplate.New(HTMLContentFileName).Option("missingkey=error").Parse(string(html)); err != nil {
+			return nil, err
+		}
+		trees = append(trees, tmpl.html.Tree)
+	}
+	text, err := readOptionalFile(fsys, path.Join(name, TextContentFileName))
+	if err != nil {
+		return nil, err
+	}
+	if text != nil {
+		if tmpl.text, err = texttemplate.New(TextContentFileName).Option("missingkey=error").Parse(string(text)); err != nil {
+			return nil, err
+		}
+		trees = append(trees, tmpl.text.Tree)
+	}
+	if tmpl.html == nil && tmpl.text == nil {
+		return nil, fmt.Errorf("missing content, expected a '%s' or a '%s' file", HTMLContentFileName, TextContentFileName)
+	}
+	tmpl.keys = referencedKeys(trees...)
+	return tmpl, nil
+}
+
+func readOptionalFile(fsys fs.FS, name string) ([]byte, error) {
+	content, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return content, err
+}
+
+// Names returns the sorted names of the templates of the set
+func (s *TemplateSet) Names() []string {
+	names := make([]string, 0, len(s.templates))
+	for name := range s.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the template with the given name
+func (s *TemplateSet) Get(name string) (*Template, bool) {
+	tmpl, found := s.templates[name]
+	return tmpl, found
+}
+
+// Render renders the template of the given notification (ie, the template named after its `Spec.Template`) with its context.
+// If the notification has no template, then its `Spec.Subject` and `Spec.Content` (considered as HTML) are rendered instead.
+func (s *TemplateSet) Render(notification *toolchainv1alpha1.Notification) (*RenderedNotification, error) {
+	recipients, err := GetRecipients(notification)
+	if err != nil {
+		return nil, err
+	}
+	var tmpl *Template
+	if notification.Spec.Template != "" {
+		var found bool
+		if tmpl, found = s.Get(notification.Spec.Template); !found {
+			return nil, fmt.Errorf("the notification template '%s' does not exist", notification.Spec.Template)
+		}
+	} else if tmpl, err = newInlineTemplate(notification.Spec.Subject, notification.Spec.Content); err != nil {
+		return nil, errors.Wrap(err, "unable to parse the subject and content of the notification")
+	}
+	rendered, err := tmpl.Render(notification.Spec.Context)
+	if err != nil {
+		if tmpl.Name != "" {
+			return nil, errors.Wrapf(err, "unable to render the notification template '%s'", tmpl.Name)
+		}
+		return nil, err
+	}
+	rendered.Recipients = recipients
+	return rendered, nil
+}
+
+func newInlineTemplate(subject, content string) (*Template, error) {
+	tmpl := &Template{}
+	var err error
+	if tmpl.subject, err = texttemplate.New("subject").Option("missingkey=error").Parse(subject); err != nil {
+		return nil, err
+	}
+	if tmpl.html, err = htmltemplate.New("content").Option("missingkey=error").Parse(content); err != nil {
+		return nil, err
+	}
+	tmpl.keys = referencedKeys(tmpl.subject.Tree, tmpl.html.Tree)
+	return tmpl, nil
+}
+
+// Keys returns the sorted keys of the context referenced by the template
+func (t *Template) Keys() []string {
+	return t.keys
+}
+
+// Render renders the subject and the contents of the template with the given context.
+// It returns an error listing the keys referenced by the template which are missing in the context, if any.
+func (t *Template) Render(context map[string]string) (*RenderedNotification, error) {
+	var missing []string
+	for _, key := range t.keys {
+		if _, found := context[key]; !found {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("the context of the notification is missing the keys referenced by the template: %s", strings.Join(missing, ", "))
+	}
+	rendered := &RenderedNotification{}
+	buf := &bytes.Buffer{}
+	if err := t.subject.Execute(buf, context); err != nil {
+		return nil, errors.Wrap(err, "unable to render the subject")
+	}
+	rendered.Subject = buf.String()
+	if t.html != nil {
+		buf.Reset()
+		if err := t.html.Execute(buf, context); err != nil {
+			return nil, errors.Wrap(err, "unable to render the HTML content")
+		}
+		rendered.HTML = buf.String()
+	}
+	if t.text != nil {
+		buf.Reset()
+		if err := t.text.Execute(buf, context); err != nil {
+			return nil, errors.Wrap(err, "unable to render the text content")
+		}
+		rendered.Text = buf.String()
+	}
+	return rendered, nil
+}
+
+// Preview returns the rendered notification as a plain text email, ie, the addresses and the subject as headers,
+// followed by the plain text content and the HTML content
+func (r *RenderedNotification) Preview() string {
+	buf := &strings.Builder{}
+	for _, header := range []struct {
+		name  string
+		value string
+	}{
+		{name: "To", value: formatAddresses(r.Recipients.To)},
+		{name: "Cc", value: formatAddresses(r.Recipients.CC)},
+		{name: "Bcc", value: formatAddresses(r.Recipients.BCC)},
+		{name: "Reply-To", value: formatAddresses(r.Recipients.ReplyTo)},
+		{name: "Subject", value: r.Subject},
+	} {
+		if header.value != "" {
+			fmt.Fprintf(buf, "%s: %s\n", header.name, header.value)
+		}
+	}
+	for _, content := range []struct {
+		contentType string
+		value       string
+	}{
+		{contentType: "text/plain", value: r.Text},
+		{contentType: "text/html", value: r.HTML},
+	} {
+		if content.value != "" {
+			fmt.Fprintf(buf, "\n--- %s ---\n%s", content.contentType, content.value)
+			if !strings.HasSuffix(content.value, "\n") {
+				buf.WriteString("\n")
+			}
+		}
+	}
+	return buf.String()
+}
+
+// referencedKeys returns the sorted keys of the context referenced by the given templates, eg, `FirstName` for `{{.FirstName}}`
+func referencedKeys(trees ...*parse.Tree) []string {
+	keys := map[string]bool{}
+	for _, tree := range trees {
+		if tree != nil {
+			collectKeys(tree.Root, keys)
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+func collectKeys(node parse.Node, keys map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectKeys(child, keys)
+		}
+	case *parse.ActionNode:
+		collectKeys(n.Pipe, keys)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectKeys(cmd, keys)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectKeys(arg, keys)
+		}
+	case *parse.ChainNode:
+		collectKeys(n.Node, keys)
+	case *parse.FieldNode:
+		keys[n.Ident[0]] = true
+	case *parse.VariableNode:
+		// `$.Key` refers to the context, whatever the dot
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			keys[n.Ident[1]] = true
+		}
+	case *parse.IfNode:
+		collectKeys(n.Pipe, keys)
+		collectKeys(n.List, keys)
+		collectKeys(n.ElseList, keys)
+	case *parse.RangeNode:
+		// the dot is not the context within the body of a range
+		collectKeys(n.Pipe, keys)
+		collectKeys(n.ElseList, keys)
+	case *parse.WithNode:
+		// the dot is not the context within the body of a with
+		collectKeys(n.Pipe, keys)
+		collectKeys(n.ElseList, keys)
+	case *parse.TemplateNode:
+		collectKeys(n.Pipe, keys)
+	}
+}
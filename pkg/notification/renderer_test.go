@@ -0,0 +1,205 @@
+package notification
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/test"
+	testusersignup "github.com/codeready-toolchain/toolchain-common/pkg/test/usersignup"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// run `go test ./pkg/notification/... -update` to regenerate the golden files after changing the templates
+var update = flag.Bool("update", false, "update the golden files of the rendered notifications")
+
+func TestRenderGoldenFiles(t *testing.T) {
+	// given
+	templates, err := LoadTemplateSetFromDir(filepath.Join("testdata", "templates"))
+	require.NoError(t, err)
+	userSignup := testusersignup.NewUserSignup()
+	userSignup.Status.CompliantUsername = "foo"
+	userTier := &toolchainv1alpha1.UserTier{
+		Spec: toolchainv1alpha1.UserTierSpec{
+			DeactivationTimeoutDays: 30,
+		},
+	}
+	notificationTypes := map[string]string{
+		toolchainv1alpha1.NotificationTypeProvisioned:  "userprovisioned",
+		toolchainv1alpha1.NotificationTypeDeactivating: "userdeactivating",
+		toolchainv1alpha1.NotificationTypeDeactivated:  "userdeactivated",
+		toolchainv1alpha1.NotificationTypeIdled:        "idlertriggered",
+	}
+	// all the templates are covered
+	require.ElementsMatch(t, templates.Names(), values(notificationTypes))
+
+	for notificationType, templateName := range notificationTypes {
+		t.Run(notificationType, func(t *testing.T) {
+			// given
+			notification, err := NewNotificationBuilder(test.NewFakeClient(t), test.HostOperatorNs).
+				WithNotificationType(notificationType).
+				WithTemplate(templateName).
+				WithUserContext(userSignup).
+				WithUserTierContext(userTier).
+				WithKeysAndValues(map[string]string{
+					"Namespace":   "foo-dev",
+					"IdledReason": "the pod 'foo-<script>' has been running for too long",
+				}).
+				WithCC("admin@example.com").
+				Create(context.TODO(), userSignup.Spec.IdentityClaims.Email)
+			require.NoError(t, err)
+
+			// when
+			rendered, err := templates.Render(notification)
+
+			// then
+			require.NoError(t, err)
+			assertGoldenFile(t, filepath.Join("testdata", "golden", notificationType+".golden"), rendered.Preview())
+		})
+	}
+}
+
+func assertGoldenFile(t *testing.T, path, actual string) {
+	if *update {
+		require.NoError(t, os.WriteFile(path, []byte(actual), 0600))
+	}
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err, "run the tests with the -update flag to create the golden file")
+	assert.Equal(t, string(expected), actual)
+}
+
+func values(m map[string]string) []string {
+	result := make([]string, 0, len(m))
+	for _, v := range m {
+		result = append(result, v)
+	}
+	return result
+}
+
+func TestLoadTemplateSet(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		// given
+		fsys := fstest.MapFS{
+			"welcome/subject.txt":       {Data: []byte("Welcome {{.FirstName}}\n")},
+			"welcome/notification.html": {Data: []byte(`{{if .Company}}<b>{{.Company}}</b>{{end}}{{range .Items}}{{.Ignored}}{{end}}{{with .Team}}{{.Ignored}}{{else}}{{$.Fallback}}{{end}}`)},
+			"goodbye/subject.txt":       {Data: []byte("Goodbye")},
+			"goodbye/notification.txt":  {Data: []byte("Bye {{.FirstName}}")},
+			".hidden/subject.txt":       {Data: []byte("{{")},
+			"README.md":                 {Data: []byte("not a template")},
+		}
+
+		// when
+		templates, err := LoadTemplateSet(fsys)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"goodbye", "welcome"}, templates.Names())
+		welcome, found := templates.Get("welcome")
+		require.True(t, found)
+		assert.Equal(t, []string{"Company", "Fallback", "FirstName", "Items", "Team"}, welcome.Keys())
+	})
+
+	t.Run("missing subject", func(t *testing.T) {
+		// given
+		fsys := fstest.MapFS{
+			"welcome/notification.html": {Data: []byte("Welcome")},
+		}
+
+		// when
+		_, err := LoadTemplateSet(fsys)
+
+		// then
+		require.EqualError(t, err, "unable to load the notification template 'welcome': open welcome/subject.txt: file does not exist")
+	})
+
+	t.Run("missing content", func(t *testing.T) {
+		// given
+		fsys := fstest.MapFS{
+			"welcome/subject.txt": {Data: []byte("Welcome")},
+		}
+
+		// when
+		_, err := LoadTemplateSet(fsys)
+
+		// then
+		require.EqualError(t, err, "unable to load the notification template 'welcome': missing content, expected a 'notification.html' or a 'notification.txt' file")
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		// given
+		fsys := fstest.MapFS{
+			"welcome/subject.txt":       {Data: []byte("Welcome")},
+			"welcome/notification.html": {Data: []byte("{{.FirstName")},
+		}
+
+		// when
+		_, err := LoadTemplateSet(fsys)
+
+		// then
+		require.ErrorContains(t, err, "unable to load the notification template 'welcome': template: notification.html:1: unclosed action")
+	})
+}
+
+func TestRender(t *testing.T) {
+	// given
+	templates, err := LoadTemplateSet(fstest.MapFS{
+		"welcome/subject.txt":       {Data: []byte("Welcome {{.FirstName}}")},
+		"welcome/notification.html": {Data: []byte("<b>{{.FirstName}} {{.LastName}}</b>")},
+	})
+	require.NoError(t, err)
+	newNotification := func(template, subject, content string, context map[string]string) *toolchainv1alpha1.Notification {
+		return &toolchainv1alpha1.Notification{
+			Spec: toolchainv1alpha1.NotificationSpec{
+				Recipient: "foo@acme.com",
+				Template:  template,
+				Subject:   subject,
+				Content:   content,
+				Context:   context,
+			},
+		}
+	}
+
+	t.Run("with template", func(t *testing.T) {
+		// when
+		rendered, err := templates.Render(newNotification("welcome", "", "", map[string]string{"FirstName": "John", "LastName": "<Smith>"}))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "Welcome John", rendered.Subject)
+		assert.Equal(t, "<b>John &lt;Smith&gt;</b>", rendered.HTML)
+		assert.Empty(t, rendered.Text)
+		assert.Equal(t, "To: foo@acme.com\nSubject: Welcome John\n\n--- text/html ---\n<b>John &lt;Smith&gt;</b>\n", rendered.Preview())
+	})
+
+	t.Run("with subject and content", func(t *testing.T) {
+		// when
+		rendered, err := templates.Render(newNotification("", "Hello {{.FirstName}}", "<p>{{.FirstName}}</p>", map[string]string{"FirstName": "John"}))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "Hello John", rendered.Subject)
+		assert.Equal(t, "<p>John</p>", rendered.HTML)
+	})
+
+	t.Run("missing keys in the context", func(t *testing.T) {
+		// when
+		_, err := templates.Render(newNotification("welcome", "", "", map[string]string{"Company": "ACME"}))
+
+		// then
+		require.EqualError(t, err, "unable to render the notification template 'welcome': the context of the notification is missing the keys referenced by the template: FirstName, LastName")
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		// when
+		_, err := templates.Render(newNotification("unknown", "", "", nil))
+
+		// then
+		require.EqualError(t, err, "the notification template 'unknown' does not exist")
+	})
+}
@@ -0,0 +1,231 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+
+	"k8s.io/utils/clock"
+)
+
+const (
+	// ComponentCheckTimedOutReason the reason of the condition of a component whose check didn't complete in time
+	ComponentCheckTimedOutReason = "ComponentCheckTimedOut"
+
+	// DefaultCheckTimeout the default duration after which a check of a StatusAggregator is considered as failed
+	DefaultCheckTimeout = 30 * time.Second
+)
+
+// Severity how the failure of a component affects the overall status
+type Severity string
+
+const (
+	// SeverityCritical the overall status is not ready when the component is not ready
+	SeverityCritical Severity = "critical"
+	// SeverityWarning the component is reported as failing, but the overall status remains ready
+	SeverityWarning Severity = "warning"
+)
+
+// CheckFunc checks the status of a component and returns its conditions, eg, a func calling GetDeploymentStatusConditions.
+// The check should stop when the given context is done.
+type CheckFunc func(ctx context.Context) []toolchainv1alpha1.Condition
+
+// ComponentStatus the result of the check of a component
+type ComponentStatus struct {
+	Name       string
+	Severity   Severity
+	Conditions []toolchainv1alpha1.Condition
+	// Err why the component is not ready, nil if it is ready
+	Err error
+}
+
+// IsReady returns `true` if the component is ready
+func (s ComponentStatus) IsReady() bool {
+	return s.Err == nil
+}
+
+// AggregatedStatus the results of the checks of all the components, along with the overall Ready condition
+type AggregatedStatus struct {
+	// Components the statuses of the components, in the order of their registration
+	Components []ComponentStatus
+	// Ready the overall Ready condition, which is false if any critical component is not ready
+	Ready toolchainv1alpha1.Condition
+}
+
+// Component returns the status of the component with the given name
+func (s *AggregatedStatus) Component(name string) (ComponentStatus, bool) {
+	for _, c := range s.Components {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return ComponentStatus{}, false
+}
+
+// Failing returns the names of the components which are not ready with the given severity
+func (s *AggregatedStatus) Failing(severity Severity) []string {
+	var names []string
+	for _, c := range s.Components {
+		if !c.IsReady() && c.Severity == severity {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+type componentCheck struct {
+	name     string
+	severity Severity
+	check    CheckFunc
+	timeout  time.Duration
+	// selfRecorded true if the check records its result in the metrics by itself
+	selfRecorded bool
+}
+
+// StatusAggregator runs the checks registered by the components and combines their results into a single Ready condition
+type StatusAggregator struct {
+	timeout time.Duration
+	checks  []componentCheck
+	metrics *Metrics
+	clock   clock.PassiveClock
+}
+
+// StatusAggregatorOption an option to configure a StatusAggregator
+type StatusAggregatorOption func(*StatusAggregator)
+
+// WithDefaultCheckTimeout sets the timeout of the checks which are registered without a specific timeout
+func WithDefaultCheckTimeout(timeout time.Duration) StatusAggregatorOption {
+	return func(a *StatusAggregator) {
+		a.timeout = timeout
+	}
+}
+
+// WithMetrics records the result of each check in the given metrics, with the name of the check as the component,
+// except for the checks registered with WithSelfRecordedMetrics
+func WithMetrics(metrics *Metrics) StatusAggregatorOption {
+	return func(a *StatusAggregator) {
+		a.metrics = metrics
+	}
+}
+
+// WithClock sets the clock used to set the timestamps of the overall Ready condition and of the conditions of the checks which time out
+func WithClock(clk clock.PassiveClock) StatusAggregatorOption {
+	return func(a *StatusAggregator) {
+		a.clock = clk
+	}
+}
+
+// CheckOption an option to configure a check registered in a StatusAggregator
+type CheckOption func(*componentCheck)
+
+// WithCheckTimeout sets the timeout of the check
+func WithCheckTimeout(timeout time.Duration) CheckOption {
+	return func(c *componentCheck) {
+		c.timeout = timeout
+	}
+}
+
+// WithSelfRecordedMetrics marks the check as recording its result in the metrics by itself (eg, a check calling
+// GetDeploymentStatusConditionsWithAttributes with metrics), so that the StatusAggregator doesn't record it a second time
+func WithSelfRecordedMetrics() CheckOption {
+	return func(c *componentCheck) {
+		c.selfRecorded = true
+	}
+}
+
+// NewStatusAggregator returns a new StatusAggregator without any check
+func NewStatusAggregator(options ...StatusAggregatorOption) *StatusAggregator {
+	a := &StatusAggregator{
+		timeout: DefaultCheckTimeout,
+	}
+	for _, apply := range options {
+		apply(a)
+	}
+	return a
+}
+
+// Register registers the check of the component with the given name and severity.
+// Registering a check with the name of an already registered check replaces it.
+func (a *StatusAggregator) Register(name string, severity Severity, check CheckFunc, options ...CheckOption) {
+	c := componentCheck{
+		name:     name,
+		severity: severity,
+		check:    check,
+		timeout:  a.timeout,
+	}
+	for _, apply := range options {
+		apply(&c)
+	}
+	for i := range a.checks {
+		if a.checks[i].name == name {
+			a.checks[i] = c
+			return
+		}
+	}
+	a.checks = append(a.checks, c)
+}
+
+// Run runs all the checks concurrently, each one with its timeout, and returns their results along with the overall Ready condition.
+// A component is not ready if its conditions don't contain a true Ready condition, or if its check times out.
+func (a *StatusAggregator) Run(ctx context.Context) *AggregatedStatus {
+	results := make([]chan ComponentStatus, len(a.checks))
+	for i, c := range a.checks {
+		results[i] = make(chan ComponentStatus, 1)
+		go func(c componentCheck, result chan<- ComponentStatus) {
+			result <- a.runCheck(ctx, c)
+		}(c, results[i])
+	}
+	status := &AggregatedStatus{
+		Components: make([]ComponentStatus, len(a.checks)),
+	}
+	for i := range a.checks {
+		status.Components[i] = <-results[i]
+		if !a.checks[i].selfRecorded {
+			a.metrics.Record(status.Components[i].Name, status.Components[i].Conditions)
+		}
+	}
+	status.Ready = a.overallReadyCondition(status)
+	return status
+}
+
+func (a *StatusAggregator) runCheck(ctx context.Context, c componentCheck) ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	conditions := make(chan []toolchainv1alpha1.Condition, 1) // buffered, so that a check which ignores the context doesn't leak
+	go func() {
+		conditions <- c.check(ctx)
+	}()
+	status := ComponentStatus{
+		Name:     c.name,
+		Severity: c.severity,
+	}
+	select {
+	case status.Conditions = <-conditions:
+		status.Err = ValidateComponentConditionReady(status.Conditions...)
+	case <-ctx.Done():
+		status.Err = fmt.Errorf("the check did not complete within %s", c.timeout)
+		status.Conditions = []toolchainv1alpha1.Condition{*NewComponentErrorConditionWithClock(a.clock, ComponentCheckTimedOutReason, status.Err.Error())}
+	}
+	return status
+}
+
+func (a *StatusAggregator) overallReadyCondition(status *AggregatedStatus) toolchainv1alpha1.Condition {
+	var failures []string
+	for _, c := range status.Components {
+		if !c.IsReady() {
+			failures = append(failures, fmt.Sprintf("%s (%s): %s", c.Name, c.Severity, c.Err.Error()))
+		}
+	}
+	if critical := status.Failing(SeverityCritical); len(critical) > 0 {
+		return *NewComponentErrorConditionWithClock(a.clock, toolchainv1alpha1.ToolchainStatusComponentsNotReadyReason,
+			fmt.Sprintf("components not ready: %s. %s", strings.Join(critical, ", "), strings.Join(failures, "; ")))
+	}
+	ready := NewComponentReadyConditionWithClock(a.clock, toolchainv1alpha1.ToolchainStatusAllComponentsReadyReason)
+	if len(failures) > 0 {
+		ready.Message = fmt.Sprintf("components with warnings: %s. %s", strings.Join(status.Failing(SeverityWarning), ", "), strings.Join(failures, "; "))
+	}
+	return *ready
+}
@@ -0,0 +1,176 @@
+package status
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestStatusAggregator(t *testing.T) {
+	ready := func(context.Context) []toolchainv1alpha1.Condition {
+		return []toolchainv1alpha1.Condition{*NewComponentReadyCondition(toolchainv1alpha1.ToolchainStatusDeploymentReadyReason)}
+	}
+	notReady := func(msg string) CheckFunc {
+		return func(context.Context) []toolchainv1alpha1.Condition {
+			return []toolchainv1alpha1.Condition{*NewComponentErrorCondition(toolchainv1alpha1.ToolchainStatusDeploymentNotReadyReason, msg)}
+		}
+	}
+
+	t.Run("all components ready", func(t *testing.T) {
+		// given
+		aggregator := NewStatusAggregator()
+		aggregator.Register("deployment", SeverityCritical, ready)
+		aggregator.Register("revisionCheck", SeverityWarning, ready)
+
+		// when
+		status := aggregator.Run(context.TODO())
+
+		// then
+		test.AssertConditionsMatchAndRecentTimestamps(t, []toolchainv1alpha1.Condition{status.Ready}, toolchainv1alpha1.Condition{
+			Type:   toolchainv1alpha1.ConditionReady,
+			Status: corev1.ConditionTrue,
+			Reason: toolchainv1alpha1.ToolchainStatusAllComponentsReadyReason,
+		})
+		require.Len(t, status.Components, 2)
+		assert.Equal(t, "deployment", status.Components[0].Name)
+		assert.Equal(t, "revisionCheck", status.Components[1].Name)
+		assert.True(t, status.Components[0].IsReady())
+		assert.True(t, status.Components[1].IsReady())
+	})
+
+	t.Run("warning component not ready", func(t *testing.T) {
+		// given
+		aggregator := NewStatusAggregator()
+		aggregator.Register("deployment", SeverityCritical, ready)
+		aggregator.Register("revisionCheck", SeverityWarning, notReady("deployment version is not up to date"))
+
+		// when
+		status := aggregator.Run(context.TODO())
+
+		// then
+		test.AssertConditionsMatchAndRecentTimestamps(t, []toolchainv1alpha1.Condition{status.Ready}, toolchainv1alpha1.Condition{
+			Type:    toolchainv1alpha1.ConditionReady,
+			Status:  corev1.ConditionTrue,
+			Reason:  toolchainv1alpha1.ToolchainStatusAllComponentsReadyReason,
+			Message: "components with warnings: revisionCheck. revisionCheck (warning): deployment version is not up to date",
+		})
+		assert.Equal(t, []string{"revisionCheck"}, status.Failing(SeverityWarning))
+		assert.Empty(t, status.Failing(SeverityCritical))
+	})
+
+	t.Run("critical components not ready", func(t *testing.T) {
+		// given
+		aggregator := NewStatusAggregator()
+		aggregator.Register("deployment", SeverityCritical, notReady("deployment has unready status conditions: Available"))
+		aggregator.Register("toolchainCluster", SeverityCritical, func(context.Context) []toolchainv1alpha1.Condition {
+			return nil // no ready condition
+		})
+		aggregator.Register("revisionCheck", SeverityWarning, notReady("deployment version is not up to date"))
+
+		// when
+		status := aggregator.Run(context.TODO())
+
+		// then
+		test.AssertConditionsMatchAndRecentTimestamps(t, []toolchainv1alpha1.Condition{status.Ready}, toolchainv1alpha1.Condition{
+			Type:   toolchainv1alpha1.ConditionReady,
+			Status: corev1.ConditionFalse,
+			Reason: toolchainv1alpha1.ToolchainStatusComponentsNotReadyReason,
+			Message: "components not ready: deployment, toolchainCluster. " +
+				"deployment (critical): deployment has unready status conditions: Available; " +
+				"toolchainCluster (critical): a ready condition was not found; " +
+				"revisionCheck (warning): deployment version is not up to date",
+		})
+		deployment, found := status.Component("deployment")
+		require.True(t, found)
+		test.AssertConditionsMatchAndRecentTimestamps(t, deployment.Conditions, toolchainv1alpha1.Condition{
+			Type:    toolchainv1alpha1.ConditionReady,
+			Status:  corev1.ConditionFalse,
+			Reason:  toolchainv1alpha1.ToolchainStatusDeploymentNotReadyReason,
+			Message: "deployment has unready status conditions: Available",
+		})
+		_, found = status.Component("unknown")
+		assert.False(t, found)
+	})
+
+	t.Run("check timed out", func(t *testing.T) {
+		// given
+		aggregator := NewStatusAggregator(WithDefaultCheckTimeout(time.Hour))
+		aggregator.Register("deployment", SeverityCritical, ready)
+		aggregator.Register("memberStatus", SeverityCritical, func(ctx context.Context) []toolchainv1alpha1.Condition {
+			<-ctx.Done()
+			return nil
+		}, WithCheckTimeout(10*time.Millisecond))
+		blocked := make(chan struct{})
+		defer close(blocked)
+		aggregator.Register("revisionCheck", SeverityWarning, func(context.Context) []toolchainv1alpha1.Condition {
+			<-blocked // ignores the context
+			return nil
+		}, WithCheckTimeout(10*time.Millisecond))
+
+		// when
+		status := aggregator.Run(context.TODO())
+
+		// then
+		assert.Equal(t, corev1.ConditionFalse, status.Ready.Status)
+		assert.Equal(t, "components not ready: memberStatus. "+
+			"memberStatus (critical): the check did not complete within 10ms; "+
+			"revisionCheck (warning): the check did not complete within 10ms", status.Ready.Message)
+		memberStatus, found := status.Component("memberStatus")
+		require.True(t, found)
+		test.AssertConditionsMatchAndRecentTimestamps(t, memberStatus.Conditions, toolchainv1alpha1.Condition{
+			Type:    toolchainv1alpha1.ConditionReady,
+			Status:  corev1.ConditionFalse,
+			Reason:  ComponentCheckTimedOutReason,
+			Message: "the check did not complete within 10ms",
+		})
+	})
+
+	t.Run("register replaces a check with the same name", func(t *testing.T) {
+		// given
+		aggregator := NewStatusAggregator()
+		aggregator.Register("deployment", SeverityCritical, notReady("not ready"))
+
+		// when
+		aggregator.Register("deployment", SeverityCritical, ready)
+
+		// then
+		status := aggregator.Run(context.TODO())
+		require.Len(t, status.Components, 1)
+		assert.Equal(t, corev1.ConditionTrue, status.Ready.Status)
+	})
+
+	t.Run("no components", func(t *testing.T) {
+		// when
+		status := NewStatusAggregator().Run(context.TODO())
+
+		// then
+		assert.Empty(t, status.Components)
+		assert.Equal(t, corev1.ConditionTrue, status.Ready.Status)
+	})
+
+	t.Run("with clock", func(t *testing.T) {
+		// given
+		now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		aggregator := NewStatusAggregator(WithClock(clocktesting.NewFakePassiveClock(now)))
+		aggregator.Register("memberStatus", SeverityCritical, func(ctx context.Context) []toolchainv1alpha1.Condition {
+			<-ctx.Done()
+			return nil
+		}, WithCheckTimeout(10*time.Millisecond))
+
+		// when
+		status := aggregator.Run(context.TODO())
+
+		// then
+		assert.Equal(t, metav1.NewTime(now), status.Ready.LastTransitionTime)
+		assert.Equal(t, metav1.NewTime(now), status.Components[0].Conditions[0].LastTransitionTime)
+	})
+}
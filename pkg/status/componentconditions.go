@@ -8,10 +8,17 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
 )
 
 func NewComponentReadyCondition(reason string) *toolchainv1alpha1.Condition {
-	currentTime := metav1.Now()
+	return NewComponentReadyConditionWithClock(nil, reason)
+}
+
+// NewComponentReadyConditionWithClock same as NewComponentReadyCondition, with the timestamps set to the time of the given clock
+// (or of the real clock if nil)
+func NewComponentReadyConditionWithClock(clk clock.PassiveClock, reason string) *toolchainv1alpha1.Condition {
+	currentTime := metav1.NewTime(clockOrDefault(clk).Now())
 	return &toolchainv1alpha1.Condition{
 		Type:               toolchainv1alpha1.ConditionReady,
 		Status:             corev1.ConditionTrue,
@@ -22,7 +29,13 @@ func NewComponentReadyCondition(reason string) *toolchainv1alpha1.Condition {
 }
 
 func NewComponentErrorCondition(reason, msg string) *toolchainv1alpha1.Condition {
-	currentTime := metav1.Now()
+	return NewComponentErrorConditionWithClock(nil, reason, msg)
+}
+
+// NewComponentErrorConditionWithClock same as NewComponentErrorCondition, with the timestamps set to the time of the given clock
+// (or of the real clock if nil)
+func NewComponentErrorConditionWithClock(clk clock.PassiveClock, reason, msg string) *toolchainv1alpha1.Condition {
+	currentTime := metav1.NewTime(clockOrDefault(clk).Now())
 	return &toolchainv1alpha1.Condition{
 		Type:               toolchainv1alpha1.ConditionReady,
 		Status:             corev1.ConditionFalse,
@@ -44,3 +57,11 @@ func ValidateComponentConditionReady(conditions ...toolchainv1alpha1.Condition)
 
 	return nil
 }
+
+// clockOrDefault returns the given clock, or the real clock if nil
+func clockOrDefault(clk clock.PassiveClock) clock.PassiveClock {
+	if clk == nil {
+		return clock.RealClock{}
+	}
+	return clk
+}
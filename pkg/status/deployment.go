@@ -11,6 +11,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -22,15 +23,33 @@ const (
 	ErrMsgDeploymentConditionNotReady = "deployment has unready status conditions"
 )
 
+// CheckAttributes the attributes of the deployment and workload checks
+type CheckAttributes struct {
+	// Metrics the metrics in which the result of the check is recorded, with the name of the deployment or workload
+	// as the component, if not nil
+	Metrics *Metrics
+	// Clock the clock used to set the timestamps of the conditions, the real clock if nil
+	Clock clock.PassiveClock
+}
+
 // GetDeploymentStatusConditions looks up a deployment with the given name within the given namespace and checks its status
 // and finally returns a condition summarizing the status
 func GetDeploymentStatusConditions(ctx context.Context, client client.Client, name, namespace string) []toolchainv1alpha1.Condition {
+	return GetDeploymentStatusConditionsWithAttributes(ctx, client, name, namespace, CheckAttributes{})
+}
+
+// GetDeploymentStatusConditionsWithAttributes same as GetDeploymentStatusConditions, with the given attributes
+func GetDeploymentStatusConditionsWithAttributes(ctx context.Context, client client.Client, name, namespace string, attrs CheckAttributes) []toolchainv1alpha1.Condition {
+	return attrs.Metrics.Record(name, getDeploymentStatusConditions(ctx, client, name, namespace, attrs.Clock))
+}
+
+func getDeploymentStatusConditions(ctx context.Context, client client.Client, name, namespace string, clk clock.PassiveClock) []toolchainv1alpha1.Condition {
 	deploymentName := types.NamespacedName{Namespace: namespace, Name: name}
 	deployment := &appsv1.Deployment{}
 	err := client.Get(ctx, deploymentName, deployment)
 	if err != nil {
 		err = errs.Wrap(err, ErrMsgCannotGetDeployment)
-		errCondition := NewComponentErrorCondition(toolchainv1alpha1.ToolchainStatusDeploymentNotFoundReason, err.Error())
+		errCondition := NewComponentErrorConditionWithClock(clk, toolchainv1alpha1.ToolchainStatusDeploymentNotFoundReason, err.Error())
 		return []toolchainv1alpha1.Condition{*errCondition}
 	}
 
@@ -39,13 +58,13 @@ func GetDeploymentStatusConditions(ctx context.Context, client client.Client, na
 		if (condition.Type == appsv1.DeploymentAvailable || condition.Type == appsv1.DeploymentProgressing) && condition.Status != corev1.ConditionTrue {
 			// there is a condition that is not ready, return it
 			err := fmt.Errorf("%s: %s", ErrMsgDeploymentConditionNotReady, condition.Type)
-			errCondition := NewComponentErrorCondition(toolchainv1alpha1.ToolchainStatusDeploymentNotReadyReason, err.Error())
+			errCondition := NewComponentErrorConditionWithClock(clk, toolchainv1alpha1.ToolchainStatusDeploymentNotReadyReason, err.Error())
 			return []toolchainv1alpha1.Condition{*errCondition}
 		}
 	}
 
 	// no problems with the deployment, return a ready condition
-	deploymentReadyCondition := NewComponentReadyCondition(toolchainv1alpha1.ToolchainStatusDeploymentReadyReason)
+	deploymentReadyCondition := NewComponentReadyConditionWithClock(clk, toolchainv1alpha1.ToolchainStatusDeploymentReadyReason)
 	return []toolchainv1alpha1.Condition{*deploymentReadyCondition}
 }
 
@@ -3,15 +3,18 @@ package status
 import (
 	"context"
 	"testing"
+	"time"
 
 	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
 	"github.com/codeready-toolchain/toolchain-common/pkg/test"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
 )
 
 func TestGetDeploymentStatusConditions(t *testing.T) {
@@ -78,6 +81,20 @@ func TestGetDeploymentStatusConditions(t *testing.T) {
 			test.AssertConditionsMatchAndRecentTimestamps(t, conditions, expected)
 		})
 	})
+
+	t.Run("with clock", func(t *testing.T) {
+		// given
+		clock := clocktesting.NewFakePassiveClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+		fakeClient := test.NewFakeClient(t, fakeDeploymentReady())
+
+		// when
+		conditions := GetDeploymentStatusConditionsWithAttributes(context.TODO(), fakeClient, "test-deployment", test.HostOperatorNs, CheckAttributes{Clock: clock})
+
+		// then
+		require.Len(t, conditions, 1)
+		assert.Equal(t, metav1.NewTime(clock.Now()), conditions[0].LastTransitionTime)
+		assert.Equal(t, metav1.NewTime(clock.Now()), *conditions[0].LastUpdatedTime)
+	})
 }
 
 func fakeDeploymentNotAvailable() *appsv1.Deployment {
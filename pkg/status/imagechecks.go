@@ -0,0 +1,126 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/client"
+
+	errs "github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrMsgDeploymentImageIsNotUpToDate means that the image running in the deployment is not the one which its tag points to in the registry
+const ErrMsgDeploymentImageIsNotUpToDate = "deployment image is not up to date with latest image digest"
+
+// DeployedImage the image of a deployment, along with the digest of the image which is running
+type DeployedImage struct {
+	// Image the tagged image reference, eg, `quay.io/codeready-toolchain/host-operator:latest`
+	Image string
+	// DeployedDigest the digest of the running image, eg, as returned by GetDeployedImageDigest
+	DeployedDigest string
+}
+
+// CheckDeployedImageIsUpToDate verifies if the digest of the running image matches the digest which the tag of the image
+// currently points to in the registry, using the OCI distribution API. As opposed to CheckDeployedVersionIsUpToDate,
+// this check is not affected by the skipped builds or by the commits batched in a single image.
+// The same threshold as for the commits applies, starting from the time when the latest digest was first seen,
+// and the returned conditions have the same reasons (ie, the GitHub error reason when the registry can't be queried).
+func (m *VersionCheckManager) CheckDeployedImageIsUpToDate(ctx context.Context, isProd bool, alreadyExistingConditions []toolchainv1alpha1.Condition, image DeployedImage) *toolchainv1alpha1.Condition {
+	cond := m.checkDeployedImageIsUpToDate(ctx, isProd, alreadyExistingConditions, image)
+	// the component is the name of the image, without the registry, the organization and the tag
+	name := image.Image
+	if ref, err := client.ParseImageReference(image.Image); err == nil {
+		name = path.Base(ref.Repository)
+	}
+	m.Metrics.Record(versionCheckComponent(name), []toolchainv1alpha1.Condition{*cond})
+	return cond
+}
+
+func (m *VersionCheckManager) checkDeployedImageIsUpToDate(ctx context.Context, isProd bool, alreadyExistingConditions []toolchainv1alpha1.Condition, image DeployedImage) *toolchainv1alpha1.Condition {
+	if !isProd {
+		cond := NewComponentReadyConditionWithClock(m.Clock, toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckDisabledReason)
+		cond.Message = "is not running in prod environment"
+		return cond
+	}
+	ref, err := client.ParseImageReference(image.Image)
+	if err != nil {
+		return NewComponentErrorConditionWithClock(m.Clock, toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckOperatorErrorReason, err.Error())
+	}
+	if cond, throttled := m.throttle(ref.String(), alreadyExistingConditions); throttled {
+		return cond
+	}
+	latestDigest, err := m.imageDigestSource(ctx).Digest(ctx, ref)
+	if err != nil {
+		return NewComponentErrorConditionWithClock(m.Clock, toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckGitHubErrorReason, err.Error())
+	}
+	// registries don't return when a tag was updated, so the threshold starts when the latest digest is first seen
+	firstSeen := m.commitTimestamp(ref.String(), client.Commit{SHA: latestDigest})
+	expectedDeploymentTime := firstSeen.Add(DeploymentThreshold)
+	if latestDigest != image.DeployedDigest && clockOrDefault(m.Clock).Now().After(expectedDeploymentTime) {
+		err := fmt.Errorf("%s. deployed image digest %s, latest image digest %s, expected deployment timestamp: %s", ErrMsgDeploymentImageIsNotUpToDate, image.DeployedDigest, latestDigest, expectedDeploymentTime.Format(time.RFC3339))
+		return NewComponentErrorConditionWithClock(m.Clock, toolchainv1alpha1.ToolchainStatusDeploymentNotUpToDateReason, err.Error())
+	}
+	return NewComponentReadyConditionWithClock(m.Clock, toolchainv1alpha1.ToolchainStatusDeploymentUpToDateReason)
+}
+
+func (m *VersionCheckManager) imageDigestSource(ctx context.Context) client.ImageDigestSource {
+	if m.GetImageDigestSourceFunc != nil {
+		return m.GetImageDigestSourceFunc(ctx)
+	}
+	return &client.RegistryClient{}
+}
+
+// GetDeployedImageDigest returns the image and the digest of the image running in the given container of the pods of
+// the deployment with the given name within the given namespace. When several versions are running (eg, during a rollout),
+// then the digest of the most recent pod is returned.
+func GetDeployedImageDigest(ctx context.Context, cl runtimeclient.Client, name, namespace, containerName string) (DeployedImage, error) {
+	deployment := &appsv1.Deployment{}
+	if err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, deployment); err != nil {
+		return DeployedImage{}, errs.Wrap(err, ErrMsgCannotGetDeployment)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return DeployedImage{}, errs.Wrapf(err, "invalid selector in the '%s' deployment", name)
+	}
+	pods := &corev1.PodList{}
+	if err := cl.List(ctx, pods, runtimeclient.InNamespace(namespace), runtimeclient.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return DeployedImage{}, errs.Wrapf(err, "unable to list the pods of the '%s' deployment", name)
+	}
+	var result DeployedImage
+	var latest time.Time
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != containerName || status.ImageID == "" {
+				continue
+			}
+			if result.DeployedDigest == "" || pod.CreationTimestamp.After(latest) {
+				result = DeployedImage{
+					Image:          containerImage(deployment, containerName),
+					DeployedDigest: client.DigestFromImageID(status.ImageID),
+				}
+				latest = pod.CreationTimestamp.Time
+			}
+		}
+	}
+	if result.DeployedDigest == "" {
+		return DeployedImage{}, fmt.Errorf("no running '%s' container in the pods of the '%s' deployment", containerName, name)
+	}
+	return result, nil
+}
+
+func containerImage(deployment *appsv1.Deployment, containerName string) string {
+	for _, c := range deployment.Spec.Template.Spec.Containers {
+		if c.Name == containerName {
+			return c.Image
+		}
+	}
+	return ""
+}
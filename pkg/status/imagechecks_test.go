@@ -0,0 +1,208 @@
+package status
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/client"
+	"github.com/codeready-toolchain/toolchain-common/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckDeployedImageIsUpToDate(t *testing.T) {
+	// given
+	registry := test.NewFakeRegistry(t)
+	registry.SetDigest("codeready-toolchain/host-operator", "latest", "sha256:1234")
+	newVersionCheckManager := func() *VersionCheckManager {
+		return &VersionCheckManager{
+			GetImageDigestSourceFunc: func(context.Context) client.ImageDigestSource {
+				return &client.RegistryClient{PlainHTTP: true}
+			},
+		}
+	}
+	image := DeployedImage{
+		Image:          registry.Host() + "/codeready-toolchain/host-operator:latest",
+		DeployedDigest: "sha256:1234",
+	}
+
+	t.Run("revision check disabled when is not running in prod", func(t *testing.T) {
+		// given
+		expected := toolchainv1alpha1.Condition{
+			Type:    toolchainv1alpha1.ConditionReady,
+			Status:  corev1.ConditionTrue,
+			Reason:  toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckDisabledReason,
+			Message: "is not running in prod environment",
+		}
+
+		// when
+		cond := newVersionCheckManager().CheckDeployedImageIsUpToDate(context.TODO(), false, []toolchainv1alpha1.Condition{}, image)
+
+		// then
+		test.AssertConditionsMatchAndRecentTimestamps(t, []toolchainv1alpha1.Condition{*cond}, expected)
+	})
+
+	t.Run("deployed image is up to date", func(t *testing.T) {
+		// given
+		expected := toolchainv1alpha1.Condition{
+			Type:   toolchainv1alpha1.ConditionReady,
+			Status: corev1.ConditionTrue,
+			Reason: toolchainv1alpha1.ToolchainStatusDeploymentUpToDateReason,
+		}
+
+		// when
+		cond := newVersionCheckManager().CheckDeployedImageIsUpToDate(context.TODO(), true, []toolchainv1alpha1.Condition{}, image)
+
+		// then
+		test.AssertConditionsMatchAndRecentTimestamps(t, []toolchainv1alpha1.Condition{*cond}, expected)
+	})
+
+	t.Run("deployed image is not up to date", func(t *testing.T) {
+		// given
+		outdated := image
+		outdated.DeployedDigest = "sha256:0000"
+
+		t.Run("but we are still within the given 30 minutes threshold", func(t *testing.T) {
+			// given
+			expected := toolchainv1alpha1.Condition{
+				Type:   toolchainv1alpha1.ConditionReady,
+				Status: corev1.ConditionTrue,
+				Reason: toolchainv1alpha1.ToolchainStatusDeploymentUpToDateReason,
+			}
+
+			// when
+			cond := newVersionCheckManager().CheckDeployedImageIsUpToDate(context.TODO(), true, []toolchainv1alpha1.Condition{}, outdated)
+
+			// then
+			test.AssertConditionsMatchAndRecentTimestamps(t, []toolchainv1alpha1.Condition{*cond}, expected)
+		})
+
+		t.Run("30 minutes threshold expired, deployment is not up to date", func(t *testing.T) {
+			// given
+			versionCheckMgr := newVersionCheckManager()
+			firstSeen := time.Now().Add(-31 * time.Minute)
+			ref, err := client.ParseImageReference(image.Image)
+			require.NoError(t, err)
+			versionCheckMgr.firstSeenCommits = map[string]client.Commit{
+				ref.String(): {SHA: "sha256:1234", Timestamp: firstSeen},
+			}
+			expected := toolchainv1alpha1.Condition{
+				Type:    toolchainv1alpha1.ConditionReady,
+				Status:  corev1.ConditionFalse,
+				Reason:  toolchainv1alpha1.ToolchainStatusDeploymentNotUpToDateReason,
+				Message: "deployment image is not up to date with latest image digest. deployed image digest sha256:0000, latest image digest sha256:1234, expected deployment timestamp: " + firstSeen.Add(DeploymentThreshold).Format(time.RFC3339),
+			}
+
+			// when
+			cond := versionCheckMgr.CheckDeployedImageIsUpToDate(context.TODO(), true, []toolchainv1alpha1.Condition{}, outdated)
+
+			// then
+			test.AssertConditionsMatchAndRecentTimestamps(t, []toolchainv1alpha1.Condition{*cond}, expected)
+		})
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Run("unknown tag", func(t *testing.T) {
+			// given
+			unknown := image
+			unknown.Image = registry.Host() + "/codeready-toolchain/host-operator:unknown"
+			expected := toolchainv1alpha1.Condition{
+				Type:    toolchainv1alpha1.ConditionReady,
+				Status:  corev1.ConditionFalse,
+				Reason:  toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckGitHubErrorReason,
+				Message: "invalid response code from the image registry. resp.Response.StatusCode: 404, image: " + unknown.Image,
+			}
+
+			// when
+			cond := newVersionCheckManager().CheckDeployedImageIsUpToDate(context.TODO(), true, []toolchainv1alpha1.Condition{}, unknown)
+
+			// then
+			test.AssertConditionsMatchAndRecentTimestamps(t, []toolchainv1alpha1.Condition{*cond}, expected)
+		})
+
+		t.Run("image referenced by digest", func(t *testing.T) {
+			// given
+			byDigest := image
+			byDigest.Image = registry.Host() + "/codeready-toolchain/host-operator@sha256:1234"
+			expected := toolchainv1alpha1.Condition{
+				Type:    toolchainv1alpha1.ConditionReady,
+				Status:  corev1.ConditionFalse,
+				Reason:  toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckOperatorErrorReason,
+				Message: "image reference '" + byDigest.Image + "' must use a tag rather than a digest",
+			}
+
+			// when
+			cond := newVersionCheckManager().CheckDeployedImageIsUpToDate(context.TODO(), true, []toolchainv1alpha1.Condition{}, byDigest)
+
+			// then
+			test.AssertConditionsMatchAndRecentTimestamps(t, []toolchainv1alpha1.Condition{*cond}, expected)
+		})
+	})
+}
+
+func TestGetDeployedImageDigest(t *testing.T) {
+	// given
+	deployment := newFakeDeployment("host-operator", test.HostOperatorNs)
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"name": "host-operator"}}
+	deployment.Spec.Template.Spec.Containers = []corev1.Container{
+		{Name: "manager", Image: "quay.io/codeready-toolchain/host-operator:latest"},
+	}
+	newPod := func(name string, created time.Time, labels map[string]string, imageID string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         test.HostOperatorNs,
+				Labels:            labels,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "manager", ImageID: imageID},
+				},
+			},
+		}
+	}
+	selected := map[string]string{"name": "host-operator"}
+
+	t.Run("digest of the most recent pod", func(t *testing.T) {
+		// given
+		cl := test.NewFakeClient(t, deployment,
+			newPod("old", time.Now().Add(-time.Hour), selected, "quay.io/codeready-toolchain/host-operator@sha256:0000"),
+			newPod("new", time.Now().Add(-time.Minute), selected, "quay.io/codeready-toolchain/host-operator@sha256:1234"),
+			newPod("other", time.Now(), map[string]string{"name": "other"}, "quay.io/codeready-toolchain/other@sha256:5678"))
+
+		// when
+		image, err := GetDeployedImageDigest(context.TODO(), cl, "host-operator", test.HostOperatorNs, "manager")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, DeployedImage{Image: "quay.io/codeready-toolchain/host-operator:latest", DeployedDigest: "sha256:1234"}, image)
+	})
+
+	t.Run("no running container", func(t *testing.T) {
+		// given
+		cl := test.NewFakeClient(t, deployment, newPod("starting", time.Now(), selected, ""))
+
+		// when
+		_, err := GetDeployedImageDigest(context.TODO(), cl, "host-operator", test.HostOperatorNs, "manager")
+
+		// then
+		require.EqualError(t, err, "no running 'manager' container in the pods of the 'host-operator' deployment")
+	})
+
+	t.Run("deployment not found", func(t *testing.T) {
+		// given
+		cl := test.NewFakeClient(t)
+
+		// when
+		_, err := GetDeployedImageDigest(context.TODO(), cl, "host-operator", test.HostOperatorNs, "manager")
+
+		// then
+		require.ErrorContains(t, err, ErrMsgCannotGetDeployment)
+	})
+}
@@ -0,0 +1,90 @@
+package status
+
+import (
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/condition"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	metricsNamespace = "toolchain"
+	metricsSubsystem = "status"
+
+	// ComponentLabel the label of the metrics which contains the name of the checked component
+	ComponentLabel = "component"
+	// ReasonLabel the label of the metrics which contains the reason of the Ready condition of the checked component
+	ReasonLabel = "reason"
+)
+
+// Metrics the metrics recorded by the status checks
+type Metrics struct {
+	// ComponentReady 1 if the component is ready, 0 otherwise, with the reason of its Ready condition.
+	// There is a single series per component, ie, the series with the previous reason is removed when the reason changes.
+	ComponentReady *prometheus.GaugeVec
+	// ChecksTotal the number of checks per component and reason of the resulting Ready condition
+	ChecksTotal *prometheus.CounterVec
+	// GitHubAPIErrorsTotal the number of failed or rate limited calls to the GitHub API (or any other commit source) per component and reason
+	GitHubAPIErrorsTotal *prometheus.CounterVec
+}
+
+// NewMetrics creates the metrics of the status checks and registers them in the given registry (eg, the controller-runtime
+// `metrics.Registry` or a new registry in tests)
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		ComponentReady: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "component_ready",
+			Help:      "Whether the component is ready (1) or not (0), with the reason of its Ready condition",
+		}, []string{ComponentLabel, ReasonLabel}),
+		ChecksTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "checks_total",
+			Help:      "Number of status checks per component and reason of the resulting Ready condition",
+		}, []string{ComponentLabel, ReasonLabel}),
+		GitHubAPIErrorsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "github_api_errors_total",
+			Help:      "Number of failed or rate limited calls to the GitHub API per component and reason",
+		}, []string{ComponentLabel, ReasonLabel}),
+	}
+	for _, c := range []prometheus.Collector{m.ComponentReady, m.ChecksTotal, m.GitHubAPIErrorsTotal} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Record records the result of the check of the given component, based on its Ready condition, and returns the given conditions,
+// eg: `metrics.Record(status.ToolchainClusterComponent, conditions)`.
+// It is a no-op if the metrics are nil.
+func (m *Metrics) Record(component string, conditions []toolchainv1alpha1.Condition) []toolchainv1alpha1.Condition {
+	if m == nil {
+		return conditions
+	}
+	reason := "ReadyConditionNotFound"
+	value := 0.0
+	if c, found := condition.FindConditionByType(conditions, toolchainv1alpha1.ConditionReady); found {
+		reason = c.Reason
+		if c.Status == corev1.ConditionTrue {
+			value = 1
+		}
+	}
+	m.ComponentReady.DeletePartialMatch(prometheus.Labels{ComponentLabel: component})
+	m.ComponentReady.WithLabelValues(component, reason).Set(value)
+	m.ChecksTotal.WithLabelValues(component, reason).Inc()
+	return conditions
+}
+
+// recordGitHubAPIError records a failed or rate limited call to the GitHub API. It is a no-op if the metrics are nil.
+func (m *Metrics) recordGitHubAPIError(component, reason string) {
+	if m == nil {
+		return
+	}
+	m.GitHubAPIErrorsTotal.WithLabelValues(component, reason).Inc()
+}
@@ -0,0 +1,241 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/client"
+	"github.com/codeready-toolchain/toolchain-common/pkg/test"
+	"github.com/codeready-toolchain/toolchain-common/pkg/test/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func TestNewMetrics(t *testing.T) {
+	t.Run("registered in the given registry", func(t *testing.T) {
+		// given
+		registry := prometheus.NewRegistry()
+
+		// when
+		m, err := NewMetrics(registry)
+
+		// then
+		require.NoError(t, err)
+		m.Record("host-operator", []toolchainv1alpha1.Condition{*NewComponentReadyCondition(toolchainv1alpha1.ToolchainStatusDeploymentReadyReason)})
+		families, err := registry.Gather()
+		require.NoError(t, err)
+		var names []string
+		for _, f := range families {
+			names = append(names, f.GetName())
+		}
+		assert.ElementsMatch(t, []string{"toolchain_status_component_ready", "toolchain_status_checks_total"}, names)
+	})
+
+	t.Run("already registered", func(t *testing.T) {
+		// given
+		registry := prometheus.NewRegistry()
+		_, err := NewMetrics(registry)
+		require.NoError(t, err)
+
+		// when
+		_, err = NewMetrics(registry)
+
+		// then
+		require.Error(t, err)
+	})
+}
+
+func TestRecord(t *testing.T) {
+	// given
+	m, err := NewMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	t.Run("not ready", func(t *testing.T) {
+		// when
+		m.Record("host-operator", []toolchainv1alpha1.Condition{*NewComponentErrorCondition(toolchainv1alpha1.ToolchainStatusDeploymentNotReadyReason, "not ready")})
+
+		// then
+		metrics.AssertGaugeVecEquals(t, 0, m.ComponentReady, prometheus.Labels{ComponentLabel: "host-operator", ReasonLabel: toolchainv1alpha1.ToolchainStatusDeploymentNotReadyReason})
+		metrics.AssertCounterVecEquals(t, 1, m.ChecksTotal, prometheus.Labels{ComponentLabel: "host-operator", ReasonLabel: toolchainv1alpha1.ToolchainStatusDeploymentNotReadyReason})
+	})
+
+	t.Run("ready replaces the series with the previous reason", func(t *testing.T) {
+		// when
+		m.Record("host-operator", []toolchainv1alpha1.Condition{*NewComponentReadyCondition(toolchainv1alpha1.ToolchainStatusDeploymentReadyReason)})
+		m.Record("registration-service", []toolchainv1alpha1.Condition{*NewComponentReadyCondition(toolchainv1alpha1.ToolchainStatusDeploymentReadyReason)})
+
+		// then
+		metrics.AssertLabelledMetricNotExists(t, m.ComponentReady, prometheus.Labels{ComponentLabel: "host-operator", ReasonLabel: toolchainv1alpha1.ToolchainStatusDeploymentNotReadyReason})
+		metrics.AssertGaugeVecEquals(t, 1, m.ComponentReady, prometheus.Labels{ComponentLabel: "host-operator", ReasonLabel: toolchainv1alpha1.ToolchainStatusDeploymentReadyReason})
+		metrics.AssertMetricsCount(t, 2, m.ComponentReady)
+		metrics.AssertCounterVecEquals(t, 1, m.ChecksTotal, prometheus.Labels{ComponentLabel: "host-operator", ReasonLabel: toolchainv1alpha1.ToolchainStatusDeploymentNotReadyReason})
+		metrics.AssertCounterVecEquals(t, 1, m.ChecksTotal, prometheus.Labels{ComponentLabel: "host-operator", ReasonLabel: toolchainv1alpha1.ToolchainStatusDeploymentReadyReason})
+	})
+
+	t.Run("no ready condition", func(t *testing.T) {
+		// when
+		m.Record("member-operator", nil)
+
+		// then
+		metrics.AssertGaugeVecEquals(t, 0, m.ComponentReady, prometheus.Labels{ComponentLabel: "member-operator", ReasonLabel: "ReadyConditionNotFound"})
+	})
+
+	t.Run("nil metrics", func(t *testing.T) {
+		// given
+		var nilMetrics *Metrics
+		conditions := []toolchainv1alpha1.Condition{*NewComponentReadyCondition(toolchainv1alpha1.ToolchainStatusDeploymentReadyReason)}
+
+		// when
+		recorded := nilMetrics.Record("host-operator", conditions)
+
+		// then
+		assert.Equal(t, conditions, recorded)
+	})
+}
+
+func TestChecksRecordMetrics(t *testing.T) {
+	t.Run("toolchain cluster", func(t *testing.T) {
+		// given
+		m, err := NewMetrics(prometheus.NewRegistry())
+		require.NoError(t, err)
+		attrs := ToolchainClusterAttributes{
+			GetClusterFunc: newGetHostClusterNotOk(),
+			Period:         10 * time.Second,
+			Timeout:        3 * time.Second,
+			Metrics:        m,
+		}
+
+		// when
+		GetToolchainClusterConditions(log, attrs)
+
+		// then
+		metrics.AssertGaugeVecEquals(t, 0, m.ComponentReady, prometheus.Labels{ComponentLabel: ToolchainClusterComponent, ReasonLabel: toolchainv1alpha1.ToolchainStatusClusterConnectionNotFoundReason})
+	})
+
+	t.Run("deployment", func(t *testing.T) {
+		// given
+		m, err := NewMetrics(prometheus.NewRegistry())
+		require.NoError(t, err)
+		cl := test.NewFakeClient(t, fakeDeploymentReady())
+
+		// when
+		GetDeploymentStatusConditionsWithAttributes(context.TODO(), cl, "test-deployment", test.HostOperatorNs, CheckAttributes{Metrics: m})
+
+		// then
+		metrics.AssertGaugeVecEquals(t, 1, m.ComponentReady, prometheus.Labels{ComponentLabel: "test-deployment", ReasonLabel: toolchainv1alpha1.ToolchainStatusDeploymentReadyReason})
+	})
+
+	t.Run("workload", func(t *testing.T) {
+		// given
+		m, err := NewMetrics(prometheus.NewRegistry())
+		require.NoError(t, err)
+		cl := test.NewFakeClient(t)
+
+		// when
+		GetWorkloadStatusConditionsWithAttributes(context.TODO(), cl, &appsv1.StatefulSet{}, "member-operator", test.MemberOperatorNs, CheckAttributes{Metrics: m})
+
+		// then
+		metrics.AssertGaugeVecEquals(t, 0, m.ComponentReady, prometheus.Labels{ComponentLabel: "member-operator", ReasonLabel: toolchainv1alpha1.ToolchainStatusDeploymentNotFoundReason})
+	})
+
+	t.Run("version check", func(t *testing.T) {
+		// given
+		m, err := NewMetrics(prometheus.NewRegistry())
+		require.NoError(t, err)
+		repo := client.Repository{Org: "codeready-toolchain", Name: "host-operator", Branch: "HEAD", DeployedCommitSHA: "1234abcd"}
+
+		t.Run("up to date", func(t *testing.T) {
+			// given
+			versionCheckMgr := VersionCheckManager{
+				GetCommitSourceFunc: test.MockCommitSource("1234abcd", time.Now().Add(-time.Hour)),
+				Metrics:             m,
+			}
+
+			// when
+			versionCheckMgr.CheckDeployedVersionIsUpToDate(context.TODO(), true, "token", nil, repo)
+
+			// then
+			metrics.AssertGaugeVecEquals(t, 1, m.ComponentReady, prometheus.Labels{ComponentLabel: "host-operator/version", ReasonLabel: toolchainv1alpha1.ToolchainStatusDeploymentUpToDateReason})
+		})
+
+		t.Run("github API errors", func(t *testing.T) {
+			// given
+			for _, err := range []error{fmt.Errorf("github went belly up"), &client.RateLimitError{Until: time.Now().Add(time.Hour)}} {
+				versionCheckMgr := VersionCheckManager{
+					GetCommitSourceFunc: func(context.Context, string) client.CommitSource {
+						return &test.FakeCommitSource{Err: err}
+					},
+					Metrics: m,
+				}
+
+				// when
+				versionCheckMgr.CheckDeployedVersionIsUpToDate(context.TODO(), true, "token", nil, repo)
+			}
+
+			// then
+			metrics.AssertCounterVecEquals(t, 1, m.GitHubAPIErrorsTotal, prometheus.Labels{ComponentLabel: "host-operator/version", ReasonLabel: toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckGitHubErrorReason})
+			metrics.AssertCounterVecEquals(t, 1, m.GitHubAPIErrorsTotal, prometheus.Labels{ComponentLabel: "host-operator/version", ReasonLabel: "RateLimited"})
+			metrics.AssertGaugeVecEquals(t, 0, m.ComponentReady, prometheus.Labels{ComponentLabel: "host-operator/version", ReasonLabel: toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckOperatorErrorReason})
+			metrics.AssertMetricsCount(t, 1, m.ComponentReady)
+		})
+	})
+
+	t.Run("image check", func(t *testing.T) {
+		// given
+		m, err := NewMetrics(prometheus.NewRegistry())
+		require.NoError(t, err)
+		versionCheckMgr := VersionCheckManager{Metrics: m}
+
+		// when
+		versionCheckMgr.CheckDeployedImageIsUpToDate(context.TODO(), false, nil, DeployedImage{Image: "quay.io/codeready-toolchain/member-operator:latest"})
+
+		// then
+		metrics.AssertGaugeVecEquals(t, 1, m.ComponentReady, prometheus.Labels{ComponentLabel: "member-operator/version", ReasonLabel: toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckDisabledReason})
+	})
+
+	t.Run("aggregator", func(t *testing.T) {
+		// given
+		m, err := NewMetrics(prometheus.NewRegistry())
+		require.NoError(t, err)
+		aggregator := NewStatusAggregator(WithMetrics(m))
+		aggregator.Register("host-operator", SeverityCritical, func(context.Context) []toolchainv1alpha1.Condition {
+			return []toolchainv1alpha1.Condition{*NewComponentReadyCondition(toolchainv1alpha1.ToolchainStatusDeploymentReadyReason)}
+		})
+		aggregator.Register("member-status", SeverityCritical, func(ctx context.Context) []toolchainv1alpha1.Condition {
+			<-ctx.Done()
+			return nil
+		}, WithCheckTimeout(time.Millisecond))
+
+		// when
+		aggregator.Run(context.TODO())
+
+		// then
+		metrics.AssertGaugeVecEquals(t, 1, m.ComponentReady, prometheus.Labels{ComponentLabel: "host-operator", ReasonLabel: toolchainv1alpha1.ToolchainStatusDeploymentReadyReason})
+		metrics.AssertGaugeVecEquals(t, 0, m.ComponentReady, prometheus.Labels{ComponentLabel: "member-status", ReasonLabel: ComponentCheckTimedOutReason})
+		metrics.AssertLabelledMetricExists(t, m.ChecksTotal, prometheus.Labels{ComponentLabel: "member-status"})
+	})
+
+	t.Run("aggregator with a check recording its own metrics", func(t *testing.T) {
+		// given
+		m, err := NewMetrics(prometheus.NewRegistry())
+		require.NoError(t, err)
+		cl := test.NewFakeClient(t, fakeDeploymentReady())
+		aggregator := NewStatusAggregator(WithMetrics(m))
+		aggregator.Register("test-deployment", SeverityCritical, func(ctx context.Context) []toolchainv1alpha1.Condition {
+			return GetDeploymentStatusConditionsWithAttributes(ctx, cl, "test-deployment", test.HostOperatorNs, CheckAttributes{Metrics: m})
+		}, WithSelfRecordedMetrics())
+
+		// when
+		aggregator.Run(context.TODO())
+
+		// then
+		metrics.AssertGaugeVecEquals(t, 1, m.ComponentReady, prometheus.Labels{ComponentLabel: "test-deployment", ReasonLabel: toolchainv1alpha1.ToolchainStatusDeploymentReadyReason})
+		metrics.AssertCounterVecEquals(t, 1, m.ChecksTotal, prometheus.Labels{ComponentLabel: "test-deployment", ReasonLabel: toolchainv1alpha1.ToolchainStatusDeploymentReadyReason})
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/go-logr/logr"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
 )
 
 // error messages related to cluster connection
@@ -22,25 +23,37 @@ type ToolchainClusterAttributes struct {
 	GetClusterFunc func() (*cluster.CachedToolchainCluster, bool)
 	Period         time.Duration
 	Timeout        time.Duration
+	// Metrics the metrics recorded by the check, if not nil
+	Metrics *Metrics
+	// Clock the clock used to compute the time since the last probe and to set the timestamps of the conditions, the real clock if nil
+	Clock clock.PassiveClock
 }
 
+// ToolchainClusterComponent the name of the component recorded in the metrics for the cluster connection
+const ToolchainClusterComponent = "toolchainCluster"
+
 // GetToolchainClusterConditions uses the provided ToolchainCluster attributes to determine status conditions
 func GetToolchainClusterConditions(logger logr.Logger, attrs ToolchainClusterAttributes) []toolchainv1alpha1.Condition {
+	return attrs.Metrics.Record(ToolchainClusterComponent, getToolchainClusterConditions(logger, attrs))
+}
+
+func getToolchainClusterConditions(logger logr.Logger, attrs ToolchainClusterAttributes) []toolchainv1alpha1.Condition {
+	clk := clockOrDefault(attrs.Clock)
 	// look up cluster connection status
 	toolchainCluster, ok := attrs.GetClusterFunc()
 	if !ok {
-		return []toolchainv1alpha1.Condition{*NewComponentErrorCondition(toolchainv1alpha1.ToolchainStatusClusterConnectionNotFoundReason, ErrMsgClusterConnectionNotFound)}
+		return []toolchainv1alpha1.Condition{*NewComponentErrorConditionWithClock(clk, toolchainv1alpha1.ToolchainStatusClusterConnectionNotFoundReason, ErrMsgClusterConnectionNotFound)}
 	}
 
 	// check conditions of cluster connection
 	if !cluster.IsReady(toolchainCluster.ClusterStatus) {
 		for _, c := range toolchainCluster.ClusterStatus.Conditions {
 			if c.Type == "Ready" && c.Message != "" {
-				return []toolchainv1alpha1.Condition{*NewComponentErrorCondition(toolchainv1alpha1.ToolchainStatusClusterConnectionNotReadyReason, c.Message)}
+				return []toolchainv1alpha1.Condition{*NewComponentErrorConditionWithClock(clk, toolchainv1alpha1.ToolchainStatusClusterConnectionNotReadyReason, c.Message)}
 			}
 		}
 		genericErrMsg := "the cluster connection is not ready"
-		return []toolchainv1alpha1.Condition{*NewComponentErrorCondition(toolchainv1alpha1.ToolchainStatusClusterConnectionNotReadyReason, genericErrMsg)}
+		return []toolchainv1alpha1.Condition{*NewComponentErrorConditionWithClock(clk, toolchainv1alpha1.ToolchainStatusClusterConnectionNotReadyReason, genericErrMsg)}
 	}
 
 	var lastUpdatedTime metav1.Time
@@ -54,15 +67,15 @@ func GetToolchainClusterConditions(logger logr.Logger, attrs ToolchainClusterAtt
 	}
 	if !foundLastUpdatedTime {
 		lastProbeNotFoundMsg := "the time of the last probe could not be determined"
-		return []toolchainv1alpha1.Condition{*NewComponentErrorCondition(toolchainv1alpha1.ToolchainStatusClusterConnectionNotReadyReason, lastProbeNotFoundMsg)}
+		return []toolchainv1alpha1.Condition{*NewComponentErrorConditionWithClock(clk, toolchainv1alpha1.ToolchainStatusClusterConnectionNotReadyReason, lastProbeNotFoundMsg)}
 	}
 	maxDuration := attrs.Period + attrs.Timeout
 	// check that the last probe time is within limits. It should be less than period + timeout
-	timeSinceLastProbe := time.Since(lastUpdatedTime.Time)
+	timeSinceLastProbe := clk.Since(lastUpdatedTime.Time)
 	if timeSinceLastProbe > maxDuration {
 		err := fmt.Errorf("%s: %s", ErrMsgClusterConnectionLastProbeTimeExceeded, maxDuration.String())
 		logger.Error(err, fmt.Sprintf("the last probe for %s happened before: %s, see: %+v", toolchainCluster.Name, timeSinceLastProbe.String(), toolchainCluster.ClusterStatus))
-		return []toolchainv1alpha1.Condition{*NewComponentErrorCondition(toolchainv1alpha1.ToolchainStatusClusterConnectionLastProbeTimeExceededReason, err.Error())}
+		return []toolchainv1alpha1.Condition{*NewComponentErrorConditionWithClock(clk, toolchainv1alpha1.ToolchainStatusClusterConnectionLastProbeTimeExceededReason, err.Error())}
 	}
-	return []toolchainv1alpha1.Condition{*NewComponentReadyCondition(toolchainv1alpha1.ToolchainStatusClusterConnectionReadyReason)}
+	return []toolchainv1alpha1.Condition{*NewComponentReadyConditionWithClock(clk, toolchainv1alpha1.ToolchainStatusClusterConnectionReadyReason)}
 }
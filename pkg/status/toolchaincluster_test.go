@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
@@ -175,6 +176,49 @@ func TestGetToolchainClusterConditions(t *testing.T) {
 	})
 }
 
+func TestGetToolchainClusterConditionsWithClock(t *testing.T) {
+	// given
+	lastProbe := metav1.NewTime(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	clock := clocktesting.NewFakePassiveClock(lastProbe.Add(13 * time.Second))
+	attrs := ToolchainClusterAttributes{
+		GetClusterFunc: NewFakeGetHostCluster(true, toolchainv1alpha1.ConditionReady, corev1.ConditionTrue, fakeToolchainClusterReason, "", &lastProbe),
+		Period:         10 * time.Second,
+		Timeout:        3 * time.Second,
+		Clock:          clock,
+	}
+
+	t.Run("ready when the last probe happened exactly period + timeout ago", func(t *testing.T) {
+		// when
+		conditions := GetToolchainClusterConditions(log, attrs)
+
+		// then
+		test.AssertConditionsMatch(t, conditions, toolchainv1alpha1.Condition{
+			Type:   toolchainv1alpha1.ConditionReady,
+			Status: corev1.ConditionTrue,
+			Reason: toolchainv1alpha1.ToolchainStatusClusterConnectionReadyReason,
+		})
+		assert.Equal(t, metav1.NewTime(clock.Now()), conditions[0].LastTransitionTime)
+		assert.Equal(t, metav1.NewTime(clock.Now()), *conditions[0].LastUpdatedTime)
+	})
+
+	t.Run("not ready right after period + timeout", func(t *testing.T) {
+		// given
+		clock.SetTime(lastProbe.Add(13*time.Second + time.Nanosecond))
+
+		// when
+		conditions := GetToolchainClusterConditions(log, attrs)
+
+		// then
+		test.AssertConditionsMatch(t, conditions, toolchainv1alpha1.Condition{
+			Type:    toolchainv1alpha1.ConditionReady,
+			Status:  corev1.ConditionFalse,
+			Reason:  toolchainv1alpha1.ToolchainStatusClusterConnectionLastProbeTimeExceededReason,
+			Message: "exceeded the maximum duration since the last probe: 13s",
+		})
+		assert.Equal(t, metav1.NewTime(clock.Now()), conditions[0].LastTransitionTime)
+	})
+}
+
 func newGetHostClusterReady() cluster.GetHostClusterFunc {
 	return NewFakeGetHostCluster(true, toolchainv1alpha1.ConditionReady, corev1.ConditionTrue, fakeToolchainClusterReason, "", &updatetime)
 }
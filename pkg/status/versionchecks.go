@@ -2,92 +2,192 @@ package status
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net/http"
-	"reflect"
+	"sync"
 	"time"
 
 	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
 	"github.com/codeready-toolchain/toolchain-common/pkg/client"
 	"github.com/codeready-toolchain/toolchain-common/pkg/condition"
-	"github.com/google/go-github/v52/github"
-	errs "github.com/pkg/errors"
+
+	"k8s.io/utils/clock"
 )
 
 const (
 	// ErrMsgDeploymentIsNotUpToDate means that deployment version is not aligned with source code version
 	ErrMsgDeploymentIsNotUpToDate = "deployment version is not up to date with latest github commit SHA"
 
+	// ErrMsgDeploymentIsNotUpToDateWithLatestCommit means that deployment version is not aligned with source code version,
+	// when the latest commit is retrieved from another source than GitHub
+	ErrMsgDeploymentIsNotUpToDateWithLatestCommit = "deployment version is not up to date with latest commit SHA"
+
 	// DeploymentThreshold is the threshold after which we can be almost sure the deployment was not updated on the cluster with the latest version/commit,
 	// in this case some issue is preventing the new deployment to happen.
 	DeploymentThreshold = 30 * time.Minute
 )
 
 type VersionCheckManager struct {
+	// GetGithubClientFunc the func used to create the GitHub client when GetCommitSourceFunc is not set
+	//
+	// Deprecated: use GetCommitSourceFunc, which supports other providers than GitHub
 	GetGithubClientFunc client.GetGitHubClientFunc
-	LastGHCallsPerRepo  map[string]time.Time
+	// GetCommitSourceFunc the func used to create the source of the latest commits of the repositories
+	GetCommitSourceFunc client.GetCommitSourceFunc
+	// Metrics the metrics recorded by the checks, if not nil
+	Metrics *Metrics
+	// GetImageDigestSourceFunc the func used to create the source of the latest image digests, a RegistryClient if not set
+	GetImageDigestSourceFunc func(ctx context.Context) client.ImageDigestSource
+	// Clock the clock used to compare the time with the deployment threshold and to set the timestamps of the conditions, the real clock if nil
+	Clock clock.PassiveClock
+	// LastGHCallsPerRepo the time of the last call to the commit source (or the registry) indexed by repo name (or image)
+	//
+	// Deprecated: the calls are tracked by the manager itself, the map must not be accessed while the checks run
+	LastGHCallsPerRepo map[string]time.Time
+	// lock guards the maps of the manager, so that the checks can run concurrently. It is a pointer, so that the manager
+	// can be copied, in which case the copies share the maps and the lock.
+	lock *sync.Mutex
+	// firstSeenCommits the latest commits (or image digests) indexed by repo name (or image), along with the time when they were first seen.
+	// It is used instead of the commit timestamps when the commit source doesn't return them.
+	firstSeenCommits map[string]client.Commit
 }
 
-// CheckDeployedVersionIsUpToDate verifies if there is a match between the latest commit in GitHub for a given repo and branch matches the provided commit SHA.
+// CheckDeployedVersionIsUpToDate verifies if there is a match between the latest commit in the source code repository for a given repo and branch matches the provided commit SHA.
 // There is some preconfigured delay/threshold that we keep in account before returning an `error condition`.
-func (m *VersionCheckManager) CheckDeployedVersionIsUpToDate(ctx context.Context, isProd bool, accessTokenKey string, alreadyExistingConditions []toolchainv1alpha1.Condition, githubRepo client.GitHubRepository) *toolchainv1alpha1.Condition {
+// The access token (eg, the value of the `GitHubSecret` access token key) is passed to the commit source, whatever its provider.
+func (m *VersionCheckManager) CheckDeployedVersionIsUpToDate(ctx context.Context, isProd bool, accessTokenKey string, alreadyExistingConditions []toolchainv1alpha1.Condition, repo client.Repository) *toolchainv1alpha1.Condition {
+	cond := m.checkDeployedVersionIsUpToDate(ctx, isProd, accessTokenKey, alreadyExistingConditions, repo)
+	m.Metrics.Record(versionCheckComponent(repo.Name), []toolchainv1alpha1.Condition{*cond})
+	return cond
+}
+
+func (m *VersionCheckManager) checkDeployedVersionIsUpToDate(ctx context.Context, isProd bool, accessTokenKey string, alreadyExistingConditions []toolchainv1alpha1.Condition, repo client.Repository) *toolchainv1alpha1.Condition {
 	// the first two checks are pretty much the same for all components
 	if !isProd {
-		cond := NewComponentReadyCondition(toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckDisabledReason)
+		cond := NewComponentReadyConditionWithClock(m.Clock, toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckDisabledReason)
 		cond.Message = "is not running in prod environment"
 		return cond
 	}
 	if accessTokenKey == "" {
-		cond := NewComponentReadyCondition(toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckDisabledReason)
+		cond := NewComponentReadyConditionWithClock(m.Clock, toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckDisabledReason)
 		cond.Message = "access token key is not provided"
 		return cond
 	}
 	// we can store the last call per repo name, so it will solve the gaps between calls for host & reg-service which is done form the same controller
-	if m.LastGHCallsPerRepo == nil {
-		m.LastGHCallsPerRepo = map[string]time.Time{}
-	}
-	lastCall, present := m.LastGHCallsPerRepo[githubRepo.Name]
-	if present && !client.CanIssueGitHubRequest(lastCall) {
-		// return existing condition when we cannot make a new GitHub api call due to rate limiting issues.
-		previouslySet, found := condition.FindConditionByType(alreadyExistingConditions, toolchainv1alpha1.ConditionReady)
-		if !found {
-			cond := NewComponentErrorCondition(toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckOperatorErrorReason, "unable to find ConditionReady type in existing conditions. Waiting for next attempt ...")
-			return cond
-		}
-		return &previouslySet
+	if cond, throttled := m.throttle(repo.Name, alreadyExistingConditions); throttled {
+		return cond
 	}
-	m.LastGHCallsPerRepo[githubRepo.Name] = time.Now()
-	githubClient := m.GetGithubClientFunc(ctx, accessTokenKey)
 	// get the latest commit from given repository and branch
-	latestCommit, commitResponse, err := githubClient.Repositories.GetCommit(ctx, githubRepo.Org, githubRepo.Name, githubRepo.Branch, &github.ListOptions{})
-	defer commitResponse.Body.Close()
+	commitSource := m.commitSource(ctx, accessTokenKey)
+	latestCommit, err := commitSource.LatestCommit(ctx, repo)
 	if err != nil {
-		errMsg := err.Error()
-		if ghErr, ok := err.(*github.ErrorResponse); ok { //nolint:errorlint
-			errMsg = ghErr.Message // this strips out the URL called, useful when unit testing since the port changes with each test execution.
+		if cond, rateLimited := m.rateLimitedCondition(err, alreadyExistingConditions); rateLimited {
+			m.Metrics.recordGitHubAPIError(versionCheckComponent(repo.Name), rateLimitedReason)
+			return cond
 		}
-		return NewComponentErrorCondition(toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckGitHubErrorReason, errMsg)
-	}
-	if commitResponse.StatusCode != http.StatusOK {
-		err = errs.New(fmt.Sprintf("invalid response code from github commits API. resp.Response.StatusCode: %d, repoName: %s, repoBranch: %s", commitResponse.Response.StatusCode, githubRepo.Name, githubRepo.Branch))
-		return NewComponentErrorCondition(toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckGitHubErrorReason, err.Error())
-	}
-
-	if reflect.DeepEqual(latestCommit, &github.RepositoryCommit{}) {
-		err = errs.New(fmt.Sprintf("no commits returned. repoName: %s, repoBranch: %s", githubRepo.Name, githubRepo.Branch))
-		return NewComponentErrorCondition(toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckGitHubErrorReason, err.Error())
+		m.Metrics.recordGitHubAPIError(versionCheckComponent(repo.Name), toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckGitHubErrorReason)
+		return NewComponentErrorConditionWithClock(m.Clock, toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckGitHubErrorReason, err.Error())
 	}
 	// check if there is a mismatch between the commit id of the running version and latest commit id from the source code repo (deployed version according to GitHub actions)
 	// we also consider some delay ( time that usually takes the deployment to happen on all our environments)
-	githubCommitTimestamp := latestCommit.Commit.Author.GetDate()
-	expectedDeploymentTime := githubCommitTimestamp.Add(DeploymentThreshold) // let's consider some threshold for the deployment to happen
-	githubCommitSHA := *latestCommit.SHA
-	if githubCommitSHA != githubRepo.DeployedCommitSHA && time.Now().After(expectedDeploymentTime) {
+	commitTimestamp := m.commitTimestamp(repo.Name, *latestCommit)
+	expectedDeploymentTime := commitTimestamp.Add(DeploymentThreshold) // let's consider some threshold for the deployment to happen
+	if latestCommit.SHA != repo.DeployedCommitSHA && clockOrDefault(m.Clock).Now().After(expectedDeploymentTime) {
 		// deployed version is not up-to-date after expected threshold
-		err := fmt.Errorf("%s. deployed commit SHA %s ,github latest SHA %s, expected deployment timestamp: %s", ErrMsgDeploymentIsNotUpToDate, githubRepo.DeployedCommitSHA, githubCommitSHA, expectedDeploymentTime.Format(time.RFC3339))
-		return NewComponentErrorCondition(toolchainv1alpha1.ToolchainStatusDeploymentNotUpToDateReason, err.Error())
+		errMsg, latestSHA := ErrMsgDeploymentIsNotUpToDate, "github latest SHA"
+		if _, fromGitHub := commitSource.(*client.GitHubCommitSource); !fromGitHub {
+			errMsg, latestSHA = ErrMsgDeploymentIsNotUpToDateWithLatestCommit, "latest SHA"
+		}
+		err := fmt.Errorf("%s. deployed commit SHA %s ,%s %s, expected deployment timestamp: %s", errMsg, repo.DeployedCommitSHA, latestSHA, latestCommit.SHA, expectedDeploymentTime.Format(time.RFC3339))
+		return NewComponentErrorConditionWithClock(m.Clock, toolchainv1alpha1.ToolchainStatusDeploymentNotUpToDateReason, err.Error())
 	}
 
 	// no problems with the deployment version, return a ready condition
-	return NewComponentReadyCondition(toolchainv1alpha1.ToolchainStatusDeploymentUpToDateReason)
+	return NewComponentReadyConditionWithClock(m.Clock, toolchainv1alpha1.ToolchainStatusDeploymentUpToDateReason)
+}
+
+// rateLimitedReason the reason recorded in the metrics when a call to the GitHub API is not sent because of the rate limits
+const rateLimitedReason = "RateLimited"
+
+// versionCheckComponent returns the name of the component recorded in the metrics for the version check of the given repository or image
+func versionCheckComponent(name string) string {
+	return name + "/version"
+}
+
+// throttle returns the existing ready condition (or an error condition if there is none) and `true` if the last call
+// for the given key happened less than a minute ago, in order to avoid rate limiting issues.
+// Otherwise, it records the call and returns `false`.
+func (m *VersionCheckManager) throttle(key string, alreadyExistingConditions []toolchainv1alpha1.Condition) (*toolchainv1alpha1.Condition, bool) {
+	lock := m.mutex()
+	lock.Lock()
+	defer lock.Unlock()
+	if m.LastGHCallsPerRepo == nil {
+		m.LastGHCallsPerRepo = map[string]time.Time{}
+	}
+	lastCall, present := m.LastGHCallsPerRepo[key]
+	now := clockOrDefault(m.Clock).Now()
+	if present && !client.CanIssueGitHubRequestAt(lastCall, now) {
+		// return existing condition when we cannot make a new API call due to rate limiting issues.
+		return m.existingReadyCondition(alreadyExistingConditions), true
+	}
+	m.LastGHCallsPerRepo[key] = now
+	return nil, false
+}
+
+// versionCheckManagersLock guards the lazy initialization of the locks of the managers
+var versionCheckManagersLock sync.Mutex
+
+// mutex returns the lock of the manager, which is created if the manager doesn't have one yet
+func (m *VersionCheckManager) mutex() *sync.Mutex {
+	versionCheckManagersLock.Lock()
+	defer versionCheckManagersLock.Unlock()
+	if m.lock == nil {
+		m.lock = &sync.Mutex{}
+	}
+	return m.lock
+}
+
+// rateLimitedCondition returns the existing ready condition (or an error condition if there is none) and `true`
+// if the given error means that the request was not sent because of the rate limits
+func (m *VersionCheckManager) rateLimitedCondition(err error, alreadyExistingConditions []toolchainv1alpha1.Condition) (*toolchainv1alpha1.Condition, bool) {
+	rlErr := &client.RateLimitError{}
+	if !errors.As(err, &rlErr) {
+		return nil, false
+	}
+	return m.existingReadyCondition(alreadyExistingConditions), true
+}
+
+func (m *VersionCheckManager) existingReadyCondition(alreadyExistingConditions []toolchainv1alpha1.Condition) *toolchainv1alpha1.Condition {
+	previouslySet, found := condition.FindConditionByType(alreadyExistingConditions, toolchainv1alpha1.ConditionReady)
+	if !found {
+		return NewComponentErrorConditionWithClock(m.Clock, toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckOperatorErrorReason, "unable to find ConditionReady type in existing conditions. Waiting for next attempt ...")
+	}
+	return &previouslySet
+}
+
+func (m *VersionCheckManager) commitSource(ctx context.Context, accessToken string) client.CommitSource {
+	if m.GetCommitSourceFunc != nil {
+		return m.GetCommitSourceFunc(ctx, accessToken)
+	}
+	return client.NewGitHubCommitSource(m.GetGithubClientFunc(ctx, accessToken))
+}
+
+// commitTimestamp returns the timestamp of the given commit, or the time when it was first seen for the given key
+// if the commit source didn't return it
+func (m *VersionCheckManager) commitTimestamp(key string, commit client.Commit) time.Time {
+	if !commit.Timestamp.IsZero() {
+		return commit.Timestamp
+	}
+	lock := m.mutex()
+	lock.Lock()
+	defer lock.Unlock()
+	if m.firstSeenCommits == nil {
+		m.firstSeenCommits = map[string]client.Commit{}
+	}
+	if firstSeen, found := m.firstSeenCommits[key]; found && firstSeen.SHA == commit.SHA {
+		return firstSeen.Timestamp
+	}
+	now := clockOrDefault(m.Clock).Now()
+	m.firstSeenCommits[key] = client.Commit{SHA: commit.SHA, Timestamp: now}
+	return now
 }
@@ -2,6 +2,7 @@ package status
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"testing"
 	"time"
@@ -11,8 +12,10 @@ import (
 	"github.com/codeready-toolchain/toolchain-common/pkg/test"
 	"github.com/google/go-github/v52/github"
 	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
 )
 
 func TestCheckDeployedVersionIsUpToDate(t *testing.T) {
@@ -229,3 +232,199 @@ func TestCheckDeployedVersionIsUpToDate(t *testing.T) {
 		})
 	})
 }
+
+func TestCheckDeployedVersionIsUpToDateWithCommitSource(t *testing.T) {
+	repo := client.Repository{
+		Org:               toolchainv1alpha1.ProviderLabelValue,
+		Name:              "host-operator",
+		Branch:            "master",
+		DeployedCommitSHA: "1234abcd",
+	}
+
+	t.Run("deployment version is up to date", func(t *testing.T) {
+		// given
+		versionCheckMgr := VersionCheckManager{
+			GetCommitSourceFunc: test.MockCommitSource("1234abcd", time.Now().Add(-time.Hour)),
+		}
+		expected := toolchainv1alpha1.Condition{
+			Type:   toolchainv1alpha1.ConditionReady,
+			Status: corev1.ConditionTrue,
+			Reason: toolchainv1alpha1.ToolchainStatusDeploymentUpToDateReason,
+		}
+
+		// when
+		conditions := versionCheckMgr.CheckDeployedVersionIsUpToDate(context.TODO(), true, "token", []toolchainv1alpha1.Condition{}, repo)
+
+		// then
+		test.AssertConditionsMatchAndRecentTimestamps(t, []toolchainv1alpha1.Condition{*conditions}, expected)
+	})
+
+	t.Run("commit without timestamp", func(t *testing.T) {
+		// given
+		versionCheckMgr := VersionCheckManager{
+			GetCommitSourceFunc: test.MockCommitSource("5678efgh", time.Time{}),
+		}
+
+		t.Run("first seen commit is within the threshold", func(t *testing.T) {
+			// given
+			expected := toolchainv1alpha1.Condition{
+				Type:   toolchainv1alpha1.ConditionReady,
+				Status: corev1.ConditionTrue,
+				Reason: toolchainv1alpha1.ToolchainStatusDeploymentUpToDateReason,
+			}
+
+			// when
+			conditions := versionCheckMgr.CheckDeployedVersionIsUpToDate(context.TODO(), true, "token", []toolchainv1alpha1.Condition{}, repo)
+
+			// then
+			test.AssertConditionsMatchAndRecentTimestamps(t, []toolchainv1alpha1.Condition{*conditions}, expected)
+		})
+
+		t.Run("commit seen for longer than the threshold", func(t *testing.T) {
+			// given
+			firstSeen := time.Now().Add(-31 * time.Minute)
+			versionCheckMgr.firstSeenCommits["host-operator"] = client.Commit{SHA: "5678efgh", Timestamp: firstSeen}
+			versionCheckMgr.LastGHCallsPerRepo = nil
+			expected := toolchainv1alpha1.Condition{
+				Type:    toolchainv1alpha1.ConditionReady,
+				Status:  corev1.ConditionFalse,
+				Reason:  toolchainv1alpha1.ToolchainStatusDeploymentNotUpToDateReason,
+				Message: "deployment version is not up to date with latest commit SHA. deployed commit SHA 1234abcd ,latest SHA 5678efgh, expected deployment timestamp: " + firstSeen.Add(DeploymentThreshold).Format(time.RFC3339),
+			}
+
+			// when
+			conditions := versionCheckMgr.CheckDeployedVersionIsUpToDate(context.TODO(), true, "token", []toolchainv1alpha1.Condition{}, repo)
+
+			// then
+			test.AssertConditionsMatchAndRecentTimestamps(t, []toolchainv1alpha1.Condition{*conditions}, expected)
+		})
+	})
+
+	t.Run("rate limit exceeded", func(t *testing.T) {
+		// given
+		versionCheckMgr := VersionCheckManager{
+			GetCommitSourceFunc: func(context.Context, string) client.CommitSource {
+				return &test.FakeCommitSource{Err: fmt.Errorf("wrapped: %w", &client.RateLimitError{Until: time.Now().Add(time.Hour)})}
+			},
+		}
+
+		t.Run("existing condition is returned", func(t *testing.T) {
+			// given
+			existing := toolchainv1alpha1.Condition{
+				Type:   toolchainv1alpha1.ConditionReady,
+				Status: corev1.ConditionTrue,
+				Reason: toolchainv1alpha1.ToolchainStatusDeploymentUpToDateReason,
+			}
+
+			// when
+			conditions := versionCheckMgr.CheckDeployedVersionIsUpToDate(context.TODO(), true, "token", []toolchainv1alpha1.Condition{existing}, repo)
+
+			// then
+			assert.Equal(t, existing, *conditions)
+		})
+
+		t.Run("no existing condition", func(t *testing.T) {
+			// given
+			versionCheckMgr.LastGHCallsPerRepo = nil
+			expected := toolchainv1alpha1.Condition{
+				Type:    toolchainv1alpha1.ConditionReady,
+				Status:  corev1.ConditionFalse,
+				Reason:  toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckOperatorErrorReason,
+				Message: "unable to find ConditionReady type in existing conditions. Waiting for next attempt ...",
+			}
+
+			// when
+			conditions := versionCheckMgr.CheckDeployedVersionIsUpToDate(context.TODO(), true, "token", []toolchainv1alpha1.Condition{}, repo)
+
+			// then
+			test.AssertConditionsMatchAndRecentTimestamps(t, []toolchainv1alpha1.Condition{*conditions}, expected)
+		})
+	})
+
+	t.Run("error from the commit source", func(t *testing.T) {
+		// given
+		versionCheckMgr := VersionCheckManager{
+			GetCommitSourceFunc: func(context.Context, string) client.CommitSource {
+				return &test.FakeCommitSource{Err: fmt.Errorf("gitlab is unavailable")}
+			},
+		}
+		expected := toolchainv1alpha1.Condition{
+			Type:    toolchainv1alpha1.ConditionReady,
+			Status:  corev1.ConditionFalse,
+			Reason:  toolchainv1alpha1.ToolchainStatusDeploymentRevisionCheckGitHubErrorReason,
+			Message: "gitlab is unavailable",
+		}
+
+		// when
+		conditions := versionCheckMgr.CheckDeployedVersionIsUpToDate(context.TODO(), true, "token", []toolchainv1alpha1.Condition{}, repo)
+
+		// then
+		test.AssertConditionsMatchAndRecentTimestamps(t, []toolchainv1alpha1.Condition{*conditions}, expected)
+	})
+}
+
+func TestCheckDeployedVersionIsUpToDateWithClock(t *testing.T) {
+	// given
+	latestCommitTimestamp := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := clocktesting.NewFakePassiveClock(latestCommitTimestamp.Add(DeploymentThreshold))
+	repo := client.Repository{
+		Org:               toolchainv1alpha1.ProviderLabelValue,
+		Name:              "host-operator",
+		Branch:            "HEAD",
+		DeployedCommitSHA: "5678efgh", // deployed SHA is still at previous commit
+	}
+	newManager := func() *VersionCheckManager {
+		return &VersionCheckManager{
+			GetCommitSourceFunc: test.MockCommitSource("1234abcd", latestCommitTimestamp),
+			Clock:               clock,
+		}
+	}
+
+	t.Run("up to date when the threshold is just reached", func(t *testing.T) {
+		// when
+		cond := newManager().CheckDeployedVersionIsUpToDate(context.TODO(), true, "githubToken", nil, repo)
+
+		// then
+		test.AssertConditionsMatch(t, []toolchainv1alpha1.Condition{*cond}, toolchainv1alpha1.Condition{
+			Type:   toolchainv1alpha1.ConditionReady,
+			Status: corev1.ConditionTrue,
+			Reason: toolchainv1alpha1.ToolchainStatusDeploymentUpToDateReason,
+		})
+		assert.Equal(t, metav1.NewTime(clock.Now()), cond.LastTransitionTime)
+	})
+
+	t.Run("not up to date right after the threshold", func(t *testing.T) {
+		// given
+		clock.SetTime(latestCommitTimestamp.Add(DeploymentThreshold + time.Second))
+
+		// when
+		cond := newManager().CheckDeployedVersionIsUpToDate(context.TODO(), true, "githubToken", nil, repo)
+
+		// then
+		test.AssertConditionsMatch(t, []toolchainv1alpha1.Condition{*cond}, toolchainv1alpha1.Condition{
+			Type:    toolchainv1alpha1.ConditionReady,
+			Status:  corev1.ConditionFalse,
+			Reason:  toolchainv1alpha1.ToolchainStatusDeploymentNotUpToDateReason,
+			Message: "deployment version is not up to date with latest commit SHA. deployed commit SHA 5678efgh ,latest SHA 1234abcd, expected deployment timestamp: 2024-03-01T12:30:00Z",
+		})
+		assert.Equal(t, metav1.NewTime(clock.Now()), cond.LastTransitionTime)
+	})
+
+	t.Run("calls are throttled until the delay is over", func(t *testing.T) {
+		// given
+		clock.SetTime(latestCommitTimestamp.Add(DeploymentThreshold + time.Second))
+		m := newManager()
+		existing := m.CheckDeployedVersionIsUpToDate(context.TODO(), true, "githubToken", nil, repo)
+		m.GetCommitSourceFunc = test.MockCommitSource("5678efgh", latestCommitTimestamp)
+
+		// when
+		clock.SetTime(clock.Now().Add(client.GitHubAPICallDelay))
+		throttled := m.CheckDeployedVersionIsUpToDate(context.TODO(), true, "githubToken", []toolchainv1alpha1.Condition{*existing}, repo)
+		clock.SetTime(clock.Now().Add(time.Nanosecond))
+		called := m.CheckDeployedVersionIsUpToDate(context.TODO(), true, "githubToken", []toolchainv1alpha1.Condition{*existing}, repo)
+
+		// then
+		assert.Equal(t, existing, throttled)
+		assert.Equal(t, toolchainv1alpha1.ToolchainStatusDeploymentUpToDateReason, called.Reason)
+	})
+}
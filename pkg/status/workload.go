@@ -0,0 +1,274 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+
+	errs "github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// progressDeadlineExceededReason the reason of the Progressing condition of a deployment whose rollout is stuck
+const progressDeadlineExceededReason = "ProgressDeadlineExceeded"
+
+// failedContainerReasons the reasons of the waiting containers which won't start without an intervention
+var failedContainerReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+// WorkloadStatus the status of a Deployment, a StatefulSet or a DaemonSet, along with the failures of its pods
+type WorkloadStatus struct {
+	// Kind the kind of the workload, eg, `Deployment`
+	Kind string
+	Name string
+	// Desired the number of pods which should run
+	Desired int32
+	// Updated the number of pods which run the latest version of the pod template
+	Updated int32
+	// Ready the number of ready pods
+	Ready int32
+	// Available the number of pods which have been ready for the minimum number of seconds
+	Available int32
+	// RolloutObserved `false` if the controller hasn't processed the latest version of the spec yet
+	RolloutObserved bool
+	// ProgressDeadlineExceeded `true` if the rollout of a Deployment is stuck
+	ProgressDeadlineExceeded bool
+	// UnreadyConditions the types of the Available and Progressing conditions of a Deployment which are not true
+	UnreadyConditions []string
+	// PodFailures the containers of the pods which are waiting because of a failure
+	PodFailures []PodFailure
+	// checkUpdated `false` if the pods are not updated automatically (eg, with the `OnDelete` update strategy)
+	checkUpdated bool
+}
+
+// PodFailure a container of a pod which is waiting because of a failure, eg, `CrashLoopBackOff` or `ImagePullBackOff`
+type PodFailure struct {
+	Pod       string
+	Container string
+	Reason    string
+	Message   string
+}
+
+func (f PodFailure) String() string {
+	if f.Message == "" {
+		return fmt.Sprintf("pod '%s' container '%s': %s", f.Pod, f.Container, f.Reason)
+	}
+	return fmt.Sprintf("pod '%s' container '%s': %s (%s)", f.Pod, f.Container, f.Reason, f.Message)
+}
+
+// IsReady returns `true` if all the desired pods are ready and available, none of them fails and the rollout is not stuck.
+// A rollout in progress doesn't make the workload unready, as long as the desired pods are ready and available.
+func (s *WorkloadStatus) IsReady() bool {
+	return !s.ProgressDeadlineExceeded &&
+		len(s.PodFailures) == 0 &&
+		s.Ready >= s.Desired &&
+		s.Available >= s.Desired
+}
+
+// RolloutInProgress returns `true` if the controller hasn't processed the latest version of the spec yet,
+// or if some of the desired pods don't run the latest version of the pod template yet
+func (s *WorkloadStatus) RolloutInProgress() bool {
+	return !s.RolloutObserved || (s.checkUpdated && s.Updated < s.Desired)
+}
+
+// Condition returns a condition summarizing the status of the workload. When the workload is not ready, then the message
+// contains the replica counts, the stuck rollout and the failing containers, if any. When the workload is ready
+// but a rollout is in progress, then the message contains the number of updated pods.
+func (s *WorkloadStatus) Condition() *toolchainv1alpha1.Condition {
+	return s.ConditionWithClock(nil)
+}
+
+// ConditionWithClock same as Condition, with the timestamps set with the given clock, the real clock if nil
+func (s *WorkloadStatus) ConditionWithClock(clk clock.PassiveClock) *toolchainv1alpha1.Condition {
+	if s.IsReady() {
+		cond := NewComponentReadyConditionWithClock(clk, toolchainv1alpha1.ToolchainStatusDeploymentReadyReason)
+		if s.RolloutInProgress() {
+			details := []string{fmt.Sprintf("%d/%d updated", s.Updated, s.Desired)}
+			if !s.RolloutObserved {
+				details = append(details, "rollout not observed yet")
+			}
+			cond.Message = fmt.Sprintf("%s '%s' rollout in progress: %s", strings.ToLower(s.Kind), s.Name, strings.Join(details, "; "))
+		}
+		return cond
+	}
+	details := []string{fmt.Sprintf("%d/%d updated, %d/%d ready, %d/%d available", s.Updated, s.Desired, s.Ready, s.Desired, s.Available, s.Desired)}
+	if !s.RolloutObserved {
+		details = append(details, "rollout not observed yet")
+	}
+	if s.ProgressDeadlineExceeded {
+		details = append(details, "progress deadline exceeded")
+	}
+	if len(s.UnreadyConditions) > 0 {
+		details = append(details, fmt.Sprintf("unready status conditions: %s", strings.Join(s.UnreadyConditions, ", ")))
+	}
+	for _, failure := range s.PodFailures {
+		details = append(details, failure.String())
+	}
+	return NewComponentErrorConditionWithClock(clk, toolchainv1alpha1.ToolchainStatusDeploymentNotReadyReason,
+		fmt.Sprintf("%s '%s' is not ready: %s", strings.ToLower(s.Kind), s.Name, strings.Join(details, "; ")))
+}
+
+// GetWorkloadStatusConditions looks up the workload (a Deployment, a StatefulSet or a DaemonSet) with the given name within the given namespace,
+// using the given object (eg, `&appsv1.StatefulSet{}`), evaluates its status and the status of its pods,
+// and finally returns a condition summarizing the status
+func GetWorkloadStatusConditions(ctx context.Context, cl client.Client, workload client.Object, name, namespace string) []toolchainv1alpha1.Condition {
+	return GetWorkloadStatusConditionsWithAttributes(ctx, cl, workload, name, namespace, CheckAttributes{})
+}
+
+// GetWorkloadStatusConditionsWithAttributes same as GetWorkloadStatusConditions, with the given attributes
+func GetWorkloadStatusConditionsWithAttributes(ctx context.Context, cl client.Client, workload client.Object, name, namespace string, attrs CheckAttributes) []toolchainv1alpha1.Condition {
+	return attrs.Metrics.Record(name, getWorkloadStatusConditions(ctx, cl, workload, name, namespace, attrs.Clock))
+}
+
+func getWorkloadStatusConditions(ctx context.Context, cl client.Client, workload client.Object, name, namespace string, clk clock.PassiveClock) []toolchainv1alpha1.Condition {
+	if err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, workload); err != nil {
+		err = errs.Wrapf(err, "unable to get the %s", workloadKind(workload))
+		return []toolchainv1alpha1.Condition{*NewComponentErrorConditionWithClock(clk, toolchainv1alpha1.ToolchainStatusDeploymentNotFoundReason, err.Error())}
+	}
+	status, err := EvaluateWorkload(ctx, cl, workload)
+	if err != nil {
+		return []toolchainv1alpha1.Condition{*NewComponentErrorConditionWithClock(clk, toolchainv1alpha1.ToolchainStatusDeploymentNotReadyReason, err.Error())}
+	}
+	return []toolchainv1alpha1.Condition{*status.ConditionWithClock(clk)}
+}
+
+// EvaluateWorkload returns the status of the given Deployment, StatefulSet or DaemonSet, including the failures of its pods
+func EvaluateWorkload(ctx context.Context, cl client.Client, workload client.Object) (*WorkloadStatus, error) {
+	var status *WorkloadStatus
+	var selector *metav1.LabelSelector
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		status = evaluateDeployment(w)
+		selector = w.Spec.Selector
+	case *appsv1.StatefulSet:
+		status = evaluateStatefulSet(w)
+		selector = w.Spec.Selector
+	case *appsv1.DaemonSet:
+		status = evaluateDaemonSet(w)
+		selector = w.Spec.Selector
+	default:
+		return nil, fmt.Errorf("unsupported workload type %T", workload)
+	}
+	status.Name = workload.GetName()
+	failures, err := podFailures(ctx, cl, workload.GetNamespace(), selector)
+	if err != nil {
+		return nil, errs.Wrapf(err, "unable to list the pods of the %s '%s'", strings.ToLower(status.Kind), status.Name)
+	}
+	status.PodFailures = failures
+	return status, nil
+}
+
+func evaluateDeployment(d *appsv1.Deployment) *WorkloadStatus {
+	status := &WorkloadStatus{
+		Kind:            "Deployment",
+		Desired:         replicas(d.Spec.Replicas),
+		Updated:         d.Status.UpdatedReplicas,
+		Ready:           d.Status.ReadyReplicas,
+		Available:       d.Status.AvailableReplicas,
+		RolloutObserved: d.Status.ObservedGeneration >= d.Generation,
+		checkUpdated:    true,
+	}
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Reason == progressDeadlineExceededReason {
+			status.ProgressDeadlineExceeded = true
+		}
+		if (c.Type == appsv1.DeploymentAvailable || c.Type == appsv1.DeploymentProgressing) && c.Status != corev1.ConditionTrue {
+			status.UnreadyConditions = append(status.UnreadyConditions, string(c.Type))
+		}
+	}
+	return status
+}
+
+func evaluateStatefulSet(s *appsv1.StatefulSet) *WorkloadStatus {
+	return &WorkloadStatus{
+		Kind:            "StatefulSet",
+		Desired:         replicas(s.Spec.Replicas),
+		Updated:         s.Status.UpdatedReplicas,
+		Ready:           s.Status.ReadyReplicas,
+		Available:       s.Status.AvailableReplicas,
+		RolloutObserved: s.Status.ObservedGeneration >= s.Generation,
+		checkUpdated:    s.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType,
+	}
+}
+
+func evaluateDaemonSet(d *appsv1.DaemonSet) *WorkloadStatus {
+	return &WorkloadStatus{
+		Kind:            "DaemonSet",
+		Desired:         d.Status.DesiredNumberScheduled,
+		Updated:         d.Status.UpdatedNumberScheduled,
+		Ready:           d.Status.NumberReady,
+		Available:       d.Status.NumberAvailable,
+		RolloutObserved: d.Status.ObservedGeneration >= d.Generation,
+		checkUpdated:    d.Spec.UpdateStrategy.Type != appsv1.OnDeleteDaemonSetStrategyType,
+	}
+}
+
+// replicas returns the given number of replicas, which defaults to 1
+func replicas(r *int32) int32 {
+	if r == nil {
+		return 1
+	}
+	return *r
+}
+
+// podFailures returns the failing containers of the pods matching the given selector, sorted by pod and container names
+func podFailures(ctx context.Context, cl client.Client, namespace string, selector *metav1.LabelSelector) ([]PodFailure, error) {
+	if selector == nil {
+		return nil, nil
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	pods := &corev1.PodList{}
+	if err := cl.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: s}); err != nil {
+		return nil, err
+	}
+	var failures []PodFailure
+	for _, pod := range pods.Items {
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, cs := range statuses {
+			if cs.State.Waiting == nil || !failedContainerReasons[cs.State.Waiting.Reason] {
+				continue
+			}
+			failures = append(failures, PodFailure{
+				Pod:       pod.Name,
+				Container: cs.Name,
+				Reason:    cs.State.Waiting.Reason,
+				Message:   cs.State.Waiting.Message,
+			})
+		}
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		if failures[i].Pod != failures[j].Pod {
+			return failures[i].Pod < failures[j].Pod
+		}
+		return failures[i].Container < failures[j].Container
+	})
+	return failures, nil
+}
+
+func workloadKind(workload client.Object) string {
+	switch workload.(type) {
+	case *appsv1.StatefulSet:
+		return "statefulset"
+	case *appsv1.DaemonSet:
+		return "daemonset"
+	default:
+		return "deployment"
+	}
+}
@@ -0,0 +1,316 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestGetWorkloadStatusConditions(t *testing.T) {
+	t.Run("deployment", func(t *testing.T) {
+		t.Run("ready", func(t *testing.T) {
+			// given
+			cl := test.NewFakeClient(t, newRolledOutDeployment(), newWorkloadPod("member-operator-1"))
+
+			// when
+			conditions := GetWorkloadStatusConditions(context.TODO(), cl, &appsv1.Deployment{}, "member-operator", test.MemberOperatorNs)
+
+			// then
+			test.AssertConditionsMatchAndRecentTimestamps(t, conditions, toolchainv1alpha1.Condition{
+				Type:   toolchainv1alpha1.ConditionReady,
+				Status: corev1.ConditionTrue,
+				Reason: toolchainv1alpha1.ToolchainStatusDeploymentReadyReason,
+			})
+		})
+
+		t.Run("rollout in progress with enough ready pods", func(t *testing.T) {
+			// given
+			deployment := newRolledOutDeployment()
+			deployment.Status.UpdatedReplicas = 1
+			cl := test.NewFakeClient(t, deployment)
+
+			// when
+			conditions := GetWorkloadStatusConditions(context.TODO(), cl, &appsv1.Deployment{}, "member-operator", test.MemberOperatorNs)
+
+			// then
+			test.AssertConditionsMatchAndRecentTimestamps(t, conditions, toolchainv1alpha1.Condition{
+				Type:    toolchainv1alpha1.ConditionReady,
+				Status:  corev1.ConditionTrue,
+				Reason:  toolchainv1alpha1.ToolchainStatusDeploymentReadyReason,
+				Message: "deployment 'member-operator' rollout in progress: 1/2 updated",
+			})
+		})
+
+		t.Run("rollout in progress", func(t *testing.T) {
+			// given
+			deployment := newRolledOutDeployment()
+			deployment.Status.UpdatedReplicas = 1
+			deployment.Status.ReadyReplicas = 1
+			cl := test.NewFakeClient(t, deployment)
+
+			// when
+			conditions := GetWorkloadStatusConditions(context.TODO(), cl, &appsv1.Deployment{}, "member-operator", test.MemberOperatorNs)
+
+			// then
+			test.AssertConditionsMatchAndRecentTimestamps(t, conditions, toolchainv1alpha1.Condition{
+				Type:    toolchainv1alpha1.ConditionReady,
+				Status:  corev1.ConditionFalse,
+				Reason:  toolchainv1alpha1.ToolchainStatusDeploymentNotReadyReason,
+				Message: "deployment 'member-operator' is not ready: 1/2 updated, 1/2 ready, 2/2 available",
+			})
+		})
+
+		t.Run("rollout not observed yet", func(t *testing.T) {
+			// given
+			deployment := newRolledOutDeployment()
+			deployment.Generation = 3
+			cl := test.NewFakeClient(t, deployment)
+
+			// when
+			conditions := GetWorkloadStatusConditions(context.TODO(), cl, &appsv1.Deployment{}, "member-operator", test.MemberOperatorNs)
+
+			// then
+			test.AssertConditionsMatchAndRecentTimestamps(t, conditions, toolchainv1alpha1.Condition{
+				Type:    toolchainv1alpha1.ConditionReady,
+				Status:  corev1.ConditionTrue,
+				Reason:  toolchainv1alpha1.ToolchainStatusDeploymentReadyReason,
+				Message: "deployment 'member-operator' rollout in progress: 2/2 updated; rollout not observed yet",
+			})
+		})
+
+		t.Run("progress deadline exceeded with failing pods", func(t *testing.T) {
+			// given
+			deployment := newRolledOutDeployment()
+			deployment.Status.UpdatedReplicas = 1
+			deployment.Status.Conditions = []appsv1.DeploymentCondition{
+				DeploymentAvailableCondition(),
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+			}
+			crashing := newWorkloadPod("member-operator-2")
+			crashing.Status.ContainerStatuses[0].State = corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 5m0s restarting failed container"},
+			}
+			pulling := newWorkloadPod("member-operator-3")
+			pulling.Status.InitContainerStatuses = []corev1.ContainerStatus{{
+				Name:  "init",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+			}}
+			starting := newWorkloadPod("member-operator-4")
+			starting.Status.ContainerStatuses[0].State = corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"},
+			}
+			other := newWorkloadPod("other")
+			other.Labels = map[string]string{"name": "other"}
+			other.Status.ContainerStatuses[0].State = corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+			}
+			cl := test.NewFakeClient(t, deployment, newWorkloadPod("member-operator-1"), pulling, crashing, starting, other)
+
+			// when
+			conditions := GetWorkloadStatusConditions(context.TODO(), cl, &appsv1.Deployment{}, "member-operator", test.MemberOperatorNs)
+
+			// then
+			test.AssertConditionsMatchAndRecentTimestamps(t, conditions, toolchainv1alpha1.Condition{
+				Type:   toolchainv1alpha1.ConditionReady,
+				Status: corev1.ConditionFalse,
+				Reason: toolchainv1alpha1.ToolchainStatusDeploymentNotReadyReason,
+				Message: "deployment 'member-operator' is not ready: 1/2 updated, 2/2 ready, 2/2 available; progress deadline exceeded; " +
+					"unready status conditions: Progressing; " +
+					"pod 'member-operator-2' container 'manager': CrashLoopBackOff (back-off 5m0s restarting failed container); " +
+					"pod 'member-operator-3' container 'init': ImagePullBackOff",
+			})
+		})
+
+		t.Run("not found", func(t *testing.T) {
+			// given
+			cl := test.NewFakeClient(t)
+
+			// when
+			conditions := GetWorkloadStatusConditions(context.TODO(), cl, &appsv1.Deployment{}, "member-operator", test.MemberOperatorNs)
+
+			// then
+			test.AssertConditionsMatchAndRecentTimestamps(t, conditions, toolchainv1alpha1.Condition{
+				Type:    toolchainv1alpha1.ConditionReady,
+				Status:  corev1.ConditionFalse,
+				Reason:  toolchainv1alpha1.ToolchainStatusDeploymentNotFoundReason,
+				Message: "unable to get the deployment: deployments.apps \"member-operator\" not found",
+			})
+		})
+
+		t.Run("failed to list pods", func(t *testing.T) {
+			// given
+			cl := test.NewFakeClient(t, newRolledOutDeployment())
+			cl.MockList = func(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+				if _, ok := list.(*corev1.PodList); ok {
+					return fmt.Errorf("mock error")
+				}
+				return cl.Client.List(ctx, list, opts...)
+			}
+
+			// when
+			conditions := GetWorkloadStatusConditions(context.TODO(), cl, &appsv1.Deployment{}, "member-operator", test.MemberOperatorNs)
+
+			// then
+			test.AssertConditionsMatchAndRecentTimestamps(t, conditions, toolchainv1alpha1.Condition{
+				Type:    toolchainv1alpha1.ConditionReady,
+				Status:  corev1.ConditionFalse,
+				Reason:  toolchainv1alpha1.ToolchainStatusDeploymentNotReadyReason,
+				Message: "unable to list the pods of the deployment 'member-operator': mock error",
+			})
+		})
+	})
+
+	t.Run("statefulset", func(t *testing.T) {
+		newStatefulSet := func(strategy appsv1.StatefulSetUpdateStrategyType) *appsv1.StatefulSet {
+			return &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "member-operator", Namespace: test.MemberOperatorNs, Generation: 1},
+				Spec: appsv1.StatefulSetSpec{
+					Replicas:       ptr.To[int32](2),
+					Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"name": "member-operator"}},
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: strategy},
+				},
+				Status: appsv1.StatefulSetStatus{ObservedGeneration: 1, UpdatedReplicas: 1, ReadyReplicas: 2, AvailableReplicas: 2},
+			}
+		}
+
+		t.Run("rolling update in progress", func(t *testing.T) {
+			// given
+			cl := test.NewFakeClient(t, newStatefulSet(appsv1.RollingUpdateStatefulSetStrategyType))
+
+			// when
+			conditions := GetWorkloadStatusConditions(context.TODO(), cl, &appsv1.StatefulSet{}, "member-operator", test.MemberOperatorNs)
+
+			// then
+			test.AssertConditionsMatchAndRecentTimestamps(t, conditions, toolchainv1alpha1.Condition{
+				Type:    toolchainv1alpha1.ConditionReady,
+				Status:  corev1.ConditionTrue,
+				Reason:  toolchainv1alpha1.ToolchainStatusDeploymentReadyReason,
+				Message: "statefulset 'member-operator' rollout in progress: 1/2 updated",
+			})
+		})
+
+		t.Run("ready with OnDelete strategy", func(t *testing.T) {
+			// given
+			cl := test.NewFakeClient(t, newStatefulSet(appsv1.OnDeleteStatefulSetStrategyType))
+
+			// when
+			conditions := GetWorkloadStatusConditions(context.TODO(), cl, &appsv1.StatefulSet{}, "member-operator", test.MemberOperatorNs)
+
+			// then
+			require.NoError(t, ValidateComponentConditionReady(conditions...))
+		})
+
+		t.Run("not found", func(t *testing.T) {
+			// given
+			cl := test.NewFakeClient(t)
+
+			// when
+			conditions := GetWorkloadStatusConditions(context.TODO(), cl, &appsv1.StatefulSet{}, "member-operator", test.MemberOperatorNs)
+
+			// then
+			require.Len(t, conditions, 1)
+			assert.Equal(t, toolchainv1alpha1.ToolchainStatusDeploymentNotFoundReason, conditions[0].Reason)
+			assert.Equal(t, "unable to get the statefulset: statefulsets.apps \"member-operator\" not found", conditions[0].Message)
+		})
+	})
+
+	t.Run("daemonset", func(t *testing.T) {
+		// given
+		daemonSet := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "member-operator", Namespace: test.MemberOperatorNs},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": "member-operator"}},
+			},
+			Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberReady: 2, NumberAvailable: 2},
+		}
+		crashing := newWorkloadPod("member-operator-1")
+		crashing.Status.ContainerStatuses[0].State = corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: "CreateContainerConfigError", Message: "secret \"webhook\" not found"},
+		}
+		cl := test.NewFakeClient(t, daemonSet, crashing)
+
+		// when
+		conditions := GetWorkloadStatusConditions(context.TODO(), cl, &appsv1.DaemonSet{}, "member-operator", test.MemberOperatorNs)
+
+		// then
+		test.AssertConditionsMatchAndRecentTimestamps(t, conditions, toolchainv1alpha1.Condition{
+			Type:    toolchainv1alpha1.ConditionReady,
+			Status:  corev1.ConditionFalse,
+			Reason:  toolchainv1alpha1.ToolchainStatusDeploymentNotReadyReason,
+			Message: "daemonset 'member-operator' is not ready: 3/3 updated, 2/3 ready, 2/3 available; pod 'member-operator-1' container 'manager': CreateContainerConfigError (secret \"webhook\" not found)",
+		})
+	})
+
+	t.Run("with clock", func(t *testing.T) {
+		// given
+		clock := clocktesting.NewFakePassiveClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+		cl := test.NewFakeClient(t, newRolledOutDeployment(), newWorkloadPod("member-operator-1"))
+
+		// when
+		conditions := GetWorkloadStatusConditionsWithAttributes(context.TODO(), cl, &appsv1.Deployment{}, "member-operator", test.MemberOperatorNs, CheckAttributes{Clock: clock})
+
+		// then
+		require.Len(t, conditions, 1)
+		assert.Equal(t, metav1.NewTime(clock.Now()), conditions[0].LastTransitionTime)
+		assert.Equal(t, metav1.NewTime(clock.Now()), *conditions[0].LastUpdatedTime)
+	})
+
+	t.Run("unsupported workload", func(t *testing.T) {
+		// when
+		_, err := EvaluateWorkload(context.TODO(), test.NewFakeClient(t), &corev1.Pod{})
+
+		// then
+		require.EqualError(t, err, "unsupported workload type *v1.Pod")
+	})
+}
+
+func newRolledOutDeployment() *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "member-operator",
+			Namespace:  test.MemberOperatorNs,
+			Generation: 2,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](2),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": "member-operator"}},
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			UpdatedReplicas:    2,
+			ReadyReplicas:      2,
+			AvailableReplicas:  2,
+			Conditions:         []appsv1.DeploymentCondition{DeploymentAvailableCondition(), DeploymentProgressingCondition()},
+		},
+	}
+}
+
+func newWorkloadPod(name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: test.MemberOperatorNs,
+			Labels:    map[string]string{"name": "member-operator"},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "manager",
+				Ready: true,
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}},
+		},
+	}
+}
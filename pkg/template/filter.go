@@ -1,6 +1,13 @@
 package template
 
-import "k8s.io/apimachinery/pkg/runtime"
+import (
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
 
 var (
 	// RetainNamespaces a func to retain only namespaces
@@ -34,3 +41,125 @@ loop:
 	}
 	return result
 }
+
+// RetainKinds returns a func to retain only the objects of the given kinds
+func RetainKinds(kinds ...string) FilterFunc {
+	return func(obj runtime.RawExtension) bool {
+		kind := obj.Object.GetObjectKind().GroupVersionKind().Kind
+		for _, k := range kinds {
+			if k == kind {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RetainGroups returns a func to retain only the objects belonging to the given API groups (use "" for the core group)
+func RetainGroups(groups ...string) FilterFunc {
+	return func(obj runtime.RawExtension) bool {
+		group := obj.Object.GetObjectKind().GroupVersionKind().Group
+		for _, g := range groups {
+			if g == group {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RetainGroupVersionKinds returns a func to retain only the objects with one of the given GVKs.
+// An empty version in a given GVK matches all versions of its group and kind.
+func RetainGroupVersionKinds(gvks ...schema.GroupVersionKind) FilterFunc {
+	return func(obj runtime.RawExtension) bool {
+		objGVK := obj.Object.GetObjectKind().GroupVersionKind()
+		for _, gvk := range gvks {
+			if gvk.Group == objGVK.Group && gvk.Kind == objGVK.Kind && (gvk.Version == "" || gvk.Version == objGVK.Version) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RetainNamespacedObjects returns a func to retain only the namespace-scoped objects, according to the given RESTMapper.
+// Objects whose kind is unknown to the mapper are not retained.
+func RetainNamespacedObjects(mapper meta.RESTMapper) FilterFunc {
+	return func(obj runtime.RawExtension) bool {
+		namespaced, err := isNamespaced(mapper, obj)
+		return err == nil && namespaced
+	}
+}
+
+// RetainClusterScopedObjects returns a func to retain only the cluster-scoped objects, according to the given RESTMapper.
+// Objects whose kind is unknown to the mapper are not retained.
+func RetainClusterScopedObjects(mapper meta.RESTMapper) FilterFunc {
+	return func(obj runtime.RawExtension) bool {
+		namespaced, err := isNamespaced(mapper, obj)
+		return err == nil && !namespaced
+	}
+}
+
+func isNamespaced(mapper meta.RESTMapper, obj runtime.RawExtension) (bool, error) {
+	gvk := obj.Object.GetObjectKind().GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false, err
+	}
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
+
+// RetainMatchingLabels returns a func to retain only the objects whose labels match the given selector
+func RetainMatchingLabels(selector labels.Selector) FilterFunc {
+	return func(obj runtime.RawExtension) bool {
+		objMeta, err := meta.Accessor(obj.Object)
+		return err == nil && selector.Matches(labels.Set(objMeta.GetLabels()))
+	}
+}
+
+// RetainMatchingAnnotations returns a func to retain only the objects whose annotations match the given selector
+func RetainMatchingAnnotations(selector labels.Selector) FilterFunc {
+	return func(obj runtime.RawExtension) bool {
+		objMeta, err := meta.Accessor(obj.Object)
+		return err == nil && selector.Matches(labels.Set(objMeta.GetAnnotations()))
+	}
+}
+
+// RetainMatchingNames returns a func to retain only the objects whose name matches the given pattern
+func RetainMatchingNames(pattern *regexp.Regexp) FilterFunc {
+	return func(obj runtime.RawExtension) bool {
+		objMeta, err := meta.Accessor(obj.Object)
+		return err == nil && pattern.MatchString(objMeta.GetName())
+	}
+}
+
+// And returns a func to retain only the objects matching all the given filters
+func And(filters ...FilterFunc) FilterFunc {
+	return func(obj runtime.RawExtension) bool {
+		for _, filter := range filters {
+			if !filter(obj) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or returns a func to retain only the objects matching at least one of the given filters
+func Or(filters ...FilterFunc) FilterFunc {
+	return func(obj runtime.RawExtension) bool {
+		for _, filter := range filters {
+			if filter(obj) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not returns a func to retain only the objects which do not match the given filter
+func Not(filter FilterFunc) FilterFunc {
+	return func(obj runtime.RawExtension) bool {
+		return !filter(obj)
+	}
+}
@@ -1,14 +1,18 @@
 package template_test
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/codeready-toolchain/toolchain-common/pkg/template"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestFilter(t *testing.T) {
@@ -216,3 +220,103 @@ func TestFilter(t *testing.T) {
 		})
 	})
 }
+
+func TestFilterFuncs(t *testing.T) {
+
+	ns := newUnstructured("v1", "Namespace", "", "john-dev", map[string]string{"toolchain.dev.openshift.com/type": "dev"}, nil)
+	cm := newUnstructured("v1", "ConfigMap", "john-dev", "john-config", nil, map[string]string{"toolchain.dev.openshift.com/owner": "john"})
+	rb := newUnstructured("rbac.authorization.k8s.io/v1", "RoleBinding", "john-dev", "john-edit", map[string]string{"toolchain.dev.openshift.com/type": "dev"}, nil)
+	cr := newUnstructured("rbac.authorization.k8s.io/v1", "ClusterRole", "", "john-cluster-role", nil, nil)
+	unknown := newUnstructured("example.com/v1", "Unknown", "john-dev", "john-unknown", nil, nil)
+	objs := []runtime.RawExtension{{Object: ns}, {Object: cm}, {Object: rb}, {Object: cr}, {Object: unknown}}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}, meta.RESTScopeRoot)
+
+	typeSelector, err := labels.Parse("toolchain.dev.openshift.com/type=dev")
+	require.NoError(t, err)
+	ownerSelector, err := labels.Parse("toolchain.dev.openshift.com/owner")
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		filter   template.FilterFunc
+		expected []runtime.Object
+	}{
+		"by kind": {
+			filter:   template.RetainKinds("ConfigMap", "ClusterRole"),
+			expected: []runtime.Object{cm, cr},
+		},
+		"by group": {
+			filter:   template.RetainGroups("rbac.authorization.k8s.io"),
+			expected: []runtime.Object{rb, cr},
+		},
+		"by core group": {
+			filter:   template.RetainGroups(""),
+			expected: []runtime.Object{ns, cm},
+		},
+		"by gvk": {
+			filter: template.RetainGroupVersionKinds(
+				schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Kind: "RoleBinding"},
+				schema.GroupVersionKind{Version: "v2", Kind: "ConfigMap"}),
+			expected: []runtime.Object{rb},
+		},
+		"namespaced": {
+			filter:   template.RetainNamespacedObjects(mapper),
+			expected: []runtime.Object{cm, rb},
+		},
+		"cluster-scoped": {
+			filter:   template.RetainClusterScopedObjects(mapper),
+			expected: []runtime.Object{ns, cr},
+		},
+		"by labels": {
+			filter:   template.RetainMatchingLabels(typeSelector),
+			expected: []runtime.Object{ns, rb},
+		},
+		"by annotations": {
+			filter:   template.RetainMatchingAnnotations(ownerSelector),
+			expected: []runtime.Object{cm},
+		},
+		"by name": {
+			filter:   template.RetainMatchingNames(regexp.MustCompile(`^john-(dev|edit)$`)),
+			expected: []runtime.Object{ns, rb},
+		},
+		"and": {
+			filter:   template.And(template.RetainNamespacedObjects(mapper), template.RetainMatchingLabels(typeSelector)),
+			expected: []runtime.Object{rb},
+		},
+		"or": {
+			filter:   template.Or(template.RetainKinds("Namespace"), template.RetainMatchingAnnotations(ownerSelector)),
+			expected: []runtime.Object{ns, cm},
+		},
+		"not": {
+			filter:   template.Not(template.RetainGroups("")),
+			expected: []runtime.Object{rb, cr, unknown},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// when
+			result := template.Filter(objs, tc.filter)
+
+			// then
+			actual := make([]runtime.Object, len(result))
+			for i, r := range result {
+				actual[i] = r.Object
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func newUnstructured(apiVersion, kind, namespace, name string, labels, annotations map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetLabels(labels)
+	obj.SetAnnotations(annotations)
+	return obj
+}
@@ -0,0 +1,112 @@
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	templatev1 "github.com/openshift/api/template/v1"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// TemplateEngineAnnotationKey the annotation on a Template which specifies the engine to process it with.
+	// Templates without this annotation are processed as OpenShift Templates.
+	TemplateEngineAnnotationKey = toolchainv1alpha1.LabelKeyPrefix + "template-engine"
+	// GoTemplateContentAnnotationKey the annotation on a Template which contains the Go template to process,
+	// when the engine is GoTemplateEngine
+	GoTemplateContentAnnotationKey = toolchainv1alpha1.LabelKeyPrefix + "go-template"
+
+	// OpenShiftTemplateEngine the engine which processes the objects of an OpenShift Template, with `${PARAM}` substitutions
+	OpenShiftTemplateEngine = "openshift"
+	// GoTemplateEngine the engine which processes the content of the GoTemplateContentAnnotationKey annotation with
+	// `text/template`, using the parameters as data (eg, `{{ .USERNAME }}`). The result must be a list of YAML documents.
+	// Referring to an unknown key is an error, unless it is done with `index` (eg, `{{ index . "TIMEOUT" | default "30" }}`).
+	GoTemplateEngine = "go"
+)
+
+// NewGoTemplate returns an OpenShift Template wrapping the given Go template content, which the Processor handles with the GoTemplateEngine.
+// The given parameters are declared in the Template, so that they can have a default value, be required or be generated.
+func NewGoTemplate(name string, content []byte, parameters ...templatev1.Parameter) *templatev1.Template {
+	tmpl := &templatev1.Template{
+		Parameters: parameters,
+	}
+	tmpl.SetName(name)
+	tmpl.SetAnnotations(map[string]string{
+		TemplateEngineAnnotationKey:    GoTemplateEngine,
+		GoTemplateContentAnnotationKey: string(content),
+	})
+	return tmpl
+}
+
+// IsGoTemplate returns `true` if the given Template must be processed with the GoTemplateEngine
+func IsGoTemplate(tmpl *templatev1.Template) bool {
+	return tmpl.Annotations[TemplateEngineAnnotationKey] == GoTemplateEngine
+}
+
+var goTemplateFuncs = template.FuncMap{
+	// default returns the given default value if the value is empty, eg: `{{ .REPLICAS | default "1" }}`
+	"default": func(defaultValue, value string) string {
+		if value == "" {
+			return defaultValue
+		}
+		return value
+	},
+	// split splits the value with the given separator and trims each element, eg: `{{ range split "," .TEAMS }}`
+	"split": func(sep, value string) []string {
+		if value == "" {
+			return []string{}
+		}
+		elements := strings.Split(value, sep)
+		for i := range elements {
+			elements[i] = strings.TrimSpace(elements[i])
+		}
+		return elements
+	},
+	// quote wraps the value in double quotes, eg: `{{ quote .ENABLED }}`
+	"quote": func(value string) string {
+		return fmt.Sprintf("%q", value)
+	},
+	// indent indents each line of the value with the given number of spaces
+	"indent": func(spaces int, value string) string {
+		pad := strings.Repeat(" ", spaces)
+		return pad + strings.ReplaceAll(value, "\n", "\n"+pad)
+	},
+}
+
+// processGoTemplate renders the Go template of the given Template with the values of its parameters and with the given values,
+// and decodes the resulting objects
+func processGoTemplate(tmpl *templatev1.Template, values map[string]string) ([]runtime.RawExtension, error) {
+	data := make(map[string]string, len(tmpl.Parameters)+len(values))
+	for key, value := range values {
+		data[key] = value
+	}
+	// parameters have been set with the given values already, but also contain the default and generated values
+	for _, param := range tmpl.Parameters {
+		data[param.Name] = param.Value
+	}
+	content, found := tmpl.Annotations[GoTemplateContentAnnotationKey]
+	if !found {
+		return nil, fmt.Errorf("template '%s' has no '%s' annotation", tmpl.Name, GoTemplateContentAnnotationKey)
+	}
+	goTmpl, err := template.New(tmpl.Name).Option("missingkey=error").Funcs(goTemplateFuncs).Parse(content)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse Go template '%s'", tmpl.Name)
+	}
+	buf := &bytes.Buffer{}
+	if err := goTmpl.Execute(buf, data); err != nil {
+		return nil, errors.Wrapf(err, "unable to execute Go template '%s'", tmpl.Name)
+	}
+	objs, err := decodeObjects(buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to decode the objects of Go template '%s'", tmpl.Name)
+	}
+	result := make([]runtime.RawExtension, len(objs))
+	for i := range objs {
+		result[i] = runtime.RawExtension{Object: objs[i]}
+	}
+	return result, nil
+}
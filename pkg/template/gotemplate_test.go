@@ -0,0 +1,166 @@
+package template_test
+
+import (
+	"testing"
+
+	"github.com/codeready-toolchain/toolchain-common/pkg/template"
+	templatev1 "github.com/openshift/api/template/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const goTemplateContent = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: {{ .SPACE_NAME }}-dev
+{{- if eq .ENVIRONMENT "prod" }}
+  labels:
+    toolchain.dev.openshift.com/environment: prod
+{{- end }}
+{{- range split "," .TEAMS }}
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: {{ . }}-edit
+  namespace: {{ $.SPACE_NAME }}-dev
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: edit
+subjects:
+- kind: Group
+  name: {{ . }}
+{{- end }}
+---
+apiVersion: v1
+kind: ResourceQuota
+metadata:
+  name: compute
+  namespace: {{ .SPACE_NAME }}-dev
+spec:
+  hard:
+    limits.memory: {{ .MEMORY_LIMIT | default "1Gi" | quote }}
+`
+
+func TestProcessGoTemplate(t *testing.T) {
+	// given
+	s := addToScheme(t)
+	p := template.NewProcessor(s)
+
+	newTemplate := func() *templatev1.Template {
+		return template.NewGoTemplate("test-dev", []byte(goTemplateContent),
+			templatev1.Parameter{Name: "SPACE_NAME", Required: true},
+			templatev1.Parameter{Name: "ENVIRONMENT", Value: "dev"},
+			templatev1.Parameter{Name: "TEAMS"},
+			templatev1.Parameter{Name: "MEMORY_LIMIT"},
+		)
+	}
+
+	t.Run("with default values", func(t *testing.T) {
+		// when
+		objs, err := p.Process(newTemplate(), map[string]string{
+			"SPACE_NAME": "john",
+		})
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, objs, 2)
+		assert.Equal(t, "Namespace", objs[0].GetObjectKind().GroupVersionKind().Kind)
+		assert.Equal(t, "john-dev", objs[0].GetName())
+		assert.Empty(t, objs[0].GetLabels())
+		assert.Equal(t, "ResourceQuota", objs[1].GetObjectKind().GroupVersionKind().Kind)
+		memory, _, err := unstructured.NestedString(objs[1].(*unstructured.Unstructured).Object, "spec", "hard", "limits.memory")
+		require.NoError(t, err)
+		assert.Equal(t, "1Gi", memory)
+	})
+
+	t.Run("with conditionals and ranges", func(t *testing.T) {
+		// when
+		objs, err := p.Process(newTemplate(), map[string]string{
+			"SPACE_NAME":   "john",
+			"ENVIRONMENT":  "prod",
+			"TEAMS":        "team-a, team-b",
+			"MEMORY_LIMIT": "2Gi",
+		}, template.RetainAllButNamespaces)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, objs, 3)
+		assert.Equal(t, "team-a-edit", objs[0].GetName())
+		assert.Equal(t, "john-dev", objs[0].GetNamespace())
+		assert.Equal(t, "team-b-edit", objs[1].GetName())
+		memory, _, err := unstructured.NestedString(objs[2].(*unstructured.Unstructured).Object, "spec", "hard", "limits.memory")
+		require.NoError(t, err)
+		assert.Equal(t, "2Gi", memory)
+	})
+
+	t.Run("with undeclared value", func(t *testing.T) {
+		// given
+		tmpl := template.NewGoTemplate("test-cm", []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .NAME }}
+`))
+
+		// when
+		objs, err := p.Process(tmpl, map[string]string{"NAME": "config"})
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, objs, 1)
+		assert.Equal(t, "config", objs[0].GetName())
+	})
+
+	t.Run("failures", func(t *testing.T) {
+
+		t.Run("missing required parameter", func(t *testing.T) {
+			// when
+			_, err := p.Process(newTemplate(), map[string]string{})
+
+			// then
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "parameter SPACE_NAME is required and must be specified")
+		})
+
+		t.Run("missing key", func(t *testing.T) {
+			// given
+			tmpl := template.NewGoTemplate("test-cm", []byte(`metadata:
+  name: {{ .UNKNOWN }}`))
+
+			// when
+			_, err := p.Process(tmpl, map[string]string{})
+
+			// then
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `unable to process template: unable to execute Go template 'test-cm'`)
+			assert.Contains(t, err.Error(), `map has no entry for key "UNKNOWN"`)
+		})
+
+		t.Run("invalid template", func(t *testing.T) {
+			// given
+			tmpl := template.NewGoTemplate("test-cm", []byte(`{{ if }}`))
+
+			// when
+			_, err := p.Process(tmpl, map[string]string{})
+
+			// then
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `unable to process template: unable to parse Go template 'test-cm'`)
+		})
+
+		t.Run("invalid objects", func(t *testing.T) {
+			// given
+			tmpl := template.NewGoTemplate("test-cm", []byte(`name: foo`))
+
+			// when
+			_, err := p.Process(tmpl, map[string]string{})
+
+			// then
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `unable to process template: unable to decode the objects of Go template 'test-cm'`)
+		})
+	})
+}
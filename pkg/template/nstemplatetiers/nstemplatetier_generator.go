@@ -1,19 +1,23 @@
 package nstemplatetiers
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
 
 	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/hash"
 	commonTemplate "github.com/codeready-toolchain/toolchain-common/pkg/template"
 	templatev1 "github.com/openshift/api/template/v1"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -28,6 +32,42 @@ type TierGenerator struct {
 	namespace       string
 	scheme          *runtime.Scheme
 	templatesByTier map[string]*tierData
+	config          generateConfiguration
+}
+
+type generateConfiguration struct {
+	reader              runtimeclient.Reader
+	hashVersion         int
+	previousHashVersion int
+}
+
+// GenerateOption an option when generating the tiers
+type GenerateOption func(*generateConfiguration)
+
+// WithReader sets the client used to read the TierTemplates and NSTemplateTiers which already exist in the cluster (default: `nil`).
+// When it is set, the report returned by GenerateTiers contains the previous hash and template refs of each NSTemplateTier
+// and lists only the TierTemplates which did not exist yet. Otherwise, a TierTemplate is reported as new
+// when the EnsureObject function returns `true` for it.
+func WithReader(reader runtimeclient.Reader) GenerateOption {
+	return func(config *generateConfiguration) {
+		config.reader = reader
+	}
+}
+
+// WithHashVersion sets the version of the algorithm used to compute the hashes of the generated NSTemplateTiers in the report
+// (default: `hash.HashVersion1`)
+func WithHashVersion(version int) GenerateOption {
+	return func(config *generateConfiguration) {
+		config.hashVersion = version
+	}
+}
+
+// WithPreviousHashVersion sets the version of the algorithm used to compute the hashes of the existing NSTemplateTiers in the report,
+// eg, when the operators are moving to a new version of the algorithm (default: the version set with WithHashVersion)
+func WithPreviousHashVersion(version int) GenerateOption {
+	return func(config *generateConfiguration) {
+		config.previousHashVersion = version
+	}
 }
 
 type tierData struct {
@@ -49,29 +89,41 @@ type templates struct {
 
 // template: a template's content and its latest git revision
 type template struct {
-	revision string
-	content  []byte
+	revision   string
+	content    []byte
+	goTemplate bool // `true` if the content is a Go template (ie, the file has the `.gotmpl` extension) instead of an OpenShift Template
 }
 
-// GenerateTiers processes the given metadata and files, generates TierTemplates and NSTemplateTiers, and ensures them via the provided EnsureObject function
-func GenerateTiers(s *runtime.Scheme, ensureObject EnsureObject, namespace string, metadata map[string]string, files map[string][]byte) error {
+// goTemplateExtension the extension of the template files which contain a Go template instead of an OpenShift Template
+const goTemplateExtension = ".gotmpl"
+
+// GenerateTiers processes the given metadata and files, generates TierTemplates and NSTemplateTiers, and ensures them via the provided EnsureObject function.
+// It returns a report of the TierTemplates that were created and of the changes in each NSTemplateTier.
+func GenerateTiers(s *runtime.Scheme, ensureObject EnsureObject, namespace string, metadata map[string]string, files map[string][]byte, options ...GenerateOption) (*Report, error) {
 	generator, err := newNSTemplateTierGenerator(s, ensureObject, namespace, metadata, files)
 	if err != nil {
-		return errors.Wrap(err, "unable to init NSTemplateTier generator")
+		return nil, errors.Wrap(err, "unable to init NSTemplateTier generator")
+	}
+	generator.config.hashVersion = hash.HashVersion1
+	for _, apply := range options {
+		apply(&generator.config)
+	}
+	if generator.config.previousHashVersion == 0 {
+		generator.config.previousHashVersion = generator.config.hashVersion
 	}
 
 	// create the TierTemplate resources
-	err = generator.createTierTemplates()
+	newTierTemplates, err := generator.createTierTemplates()
 	if err != nil {
-		return errors.Wrap(err, "unable to create TierTemplates")
+		return nil, errors.Wrap(err, "unable to create TierTemplates")
 	}
 
 	// create the NSTemplateTier resources
-	err = generator.createNSTemplateTiers()
+	report, err := generator.createNSTemplateTiers(newTierTemplates)
 	if err != nil {
-		return errors.Wrap(err, "unable to create NSTemplateTiers")
+		return nil, errors.Wrap(err, "unable to create NSTemplateTiers")
 	}
-	return nil
+	return report, nil
 }
 
 // newNSTemplateTierGenerator loads templates from the provided assets and processes the tierTemplates and NSTemplateTiers
@@ -140,6 +192,9 @@ type BasedOnTier struct {
 // Each `tierData` object contains itself a map of `template` objects indexed by the namespace type (`namespaceTemplates`);
 // an optional `template` for the cluster resources (`clusterTemplate`) and the NSTemplateTier resource object.
 // Each `template` object contains a `revision` (`string`) and the `content` of the template to apply (`[]byte`)
+//
+// The cluster, namespace and space role templates can also be Go templates instead of OpenShift Templates,
+// in which case their files have the `.gotmpl` extension (eg: `ns_dev.gotmpl`)
 func loadTemplatesByTiers(metadata map[string]string, files map[string][]byte) (map[string]*tierData, error) {
 
 	results := make(map[string]*tierData)
@@ -162,20 +217,25 @@ func loadTemplatesByTiers(metadata map[string]string, files map[string][]byte) (
 			}
 		}
 
+		ext := ".yaml"
+		if strings.HasSuffix(filename, goTemplateExtension) {
+			ext = goTemplateExtension
+		}
 		tmpl := template{
-			revision: metadata[strings.TrimSuffix(name, ".yaml")],
-			content:  content,
+			revision:   metadata[strings.TrimSuffix(name, ext)],
+			content:    content,
+			goTemplate: ext == goTemplateExtension,
 		}
 		switch {
 		case filename == "tier.yaml":
 			results[tier].rawTemplates.nsTemplateTier = &tmpl
-		case filename == "cluster.yaml":
+		case strings.TrimSuffix(filename, ext) == "cluster":
 			results[tier].rawTemplates.clusterTemplate = &tmpl
 		case strings.HasPrefix(filename, "ns_"):
-			kind := strings.TrimSuffix(strings.TrimPrefix(filename, "ns_"), ".yaml")
+			kind := strings.TrimSuffix(strings.TrimPrefix(filename, "ns_"), ext)
 			results[tier].rawTemplates.namespaceTemplates[kind] = tmpl
 		case strings.HasPrefix(filename, "spacerole_"):
-			role := strings.TrimSuffix(strings.TrimPrefix(filename, "spacerole_"), ".yaml")
+			role := strings.TrimSuffix(strings.TrimPrefix(filename, "spacerole_"), ext)
 			results[tier].rawTemplates.spaceroleTemplates[role] = tmpl
 		case filename == "based_on_tier.yaml":
 			basedOnTier := &BasedOnTier{}
@@ -272,20 +332,43 @@ func (t *TierGenerator) newTierTemplates(basedOnTierFileRevision string, tierDat
 	return tierTmpls, nil
 }
 
-// createTierTemplates creates all TierTemplate resources from the tier map
-func (t *TierGenerator) createTierTemplates() error {
+// createTierTemplates creates all TierTemplate resources from the tier map and returns the names of the new TierTemplates indexed by tier name
+func (t *TierGenerator) createTierTemplates() (map[string][]string, error) {
+	newTierTemplates := map[string][]string{}
 	// create the templates
 	for tierName, tierTmpls := range t.templatesByTier {
 		for _, tierTmpl := range tierTmpls.tierTemplates {
+			exists := false
+			if t.config.reader != nil {
+				var err error
+				if exists, err = t.exists(tierTmpl.Name, &toolchainv1alpha1.TierTemplate{}); err != nil {
+					return nil, errors.Wrapf(err, "unable to get the '%s' TierTemplate in namespace '%s'", tierTmpl.Name, tierTmpl.Namespace)
+				}
+			}
 			log.Info("creating TierTemplate", "namespace", tierTmpl.Namespace, "name", tierTmpl.Name)
 			// using the "standard" client since we don't need to support updates on such resources, they should be immutable
-			if _, err := t.ensureObject(tierTmpl, false, tierName); err != nil {
-				return errors.Wrapf(err, "unable to create the '%s' TierTemplate in namespace '%s'", tierTmpl.Name, tierTmpl.Namespace)
+			created, err := t.ensureObject(tierTmpl, false, tierName)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to create the '%s' TierTemplate in namespace '%s'", tierTmpl.Name, tierTmpl.Namespace)
+			}
+			if created && !exists {
+				newTierTemplates[tierName] = append(newTierTemplates[tierName], tierTmpl.Name)
 			}
 			log.Info("TierTemplate resource created", "namespace", tierTmpl.Namespace, "name", tierTmpl.Name)
 		}
 	}
-	return nil
+	return newTierTemplates, nil
+}
+
+// exists fetches the object with the given name in the generator's namespace, and returns `false` if it does not exist
+func (t *TierGenerator) exists(name string, obj runtimeclient.Object) (bool, error) {
+	if err := t.config.reader.Get(context.TODO(), types.NamespacedName{Namespace: t.namespace, Name: name}, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
 }
 
 // newTierTemplate generates a TierTemplate resource for a given tier and kind
@@ -295,12 +378,18 @@ func (t *TierGenerator) newTierTemplate(decoder runtime.Decoder, basedOnTierFile
 	}
 	revision := fmt.Sprintf("%s-%s", basedOnTierFileRevision, tmpl.revision)
 	name := newTierTemplateName(tier, kind, revision)
-	tmplObj := &templatev1.Template{}
-	_, _, err := decoder.Decode(tmpl.content, nil, tmplObj)
-	if err != nil {
-		return nil, errors.Wrapf(err, "unable to generate '%s' TierTemplate manifest", name)
+	var tmplObj *templatev1.Template
+	if tmpl.goTemplate {
+		// Go templates have no parameter declarations, so the parameters of the `based_on_tier.yaml` file (if any) are declared in the template
+		tmplObj = commonTemplate.NewGoTemplate(fmt.Sprintf("%s-%s", tier, kind), tmpl.content, parameters...)
+	} else {
+		tmplObj = &templatev1.Template{}
+		_, _, err := decoder.Decode(tmpl.content, nil, tmplObj)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to generate '%s' TierTemplate manifest", name)
+		}
+		setParams(parameters, tmplObj)
 	}
-	setParams(parameters, tmplObj)
 
 	return &toolchainv1alpha1.TierTemplate{
 		ObjectMeta: metav1.ObjectMeta{
@@ -358,21 +447,32 @@ func (t *TierGenerator) initNSTemplateTiers() error {
 	return nil
 }
 
-// createNSTemplateTiers creates the NSTemplateTier resources from the tier map
-func (t *TierGenerator) createNSTemplateTiers() error {
-
+// createNSTemplateTiers creates the NSTemplateTier resources from the tier map and returns the report of the changes
+func (t *TierGenerator) createNSTemplateTiers(newTierTemplates map[string][]string) (*Report, error) {
+	report := &Report{}
 	for tierName, tierData := range t.templatesByTier {
 		if len(tierData.objects) != 1 {
-			return fmt.Errorf("there is an unexpected number of NSTemplateTier object to be applied for tier name '%s'; expected: 1; actual: %d", tierName, len(tierData.objects))
+			return nil, fmt.Errorf("there is an unexpected number of NSTemplateTier object to be applied for tier name '%s'; expected: 1; actual: %d", tierName, len(tierData.objects))
 		}
 
 		unstructuredObj, ok := tierData.objects[0].(*unstructured.Unstructured)
 		if !ok {
-			return fmt.Errorf("unable to cast NSTemplateTier '%s' to Unstructured object '%+v'", tierName, tierData.objects[0])
+			return nil, fmt.Errorf("unable to cast NSTemplateTier '%s' to Unstructured object '%+v'", tierName, tierData.objects[0])
 		}
 		tier := &toolchainv1alpha1.NSTemplateTier{}
 		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredObj.Object, tier); err != nil {
-			return err
+			return nil, err
+		}
+		var previous *toolchainv1alpha1.NSTemplateTier
+		if t.config.reader != nil {
+			existing := &toolchainv1alpha1.NSTemplateTier{}
+			exists, err := t.exists(tier.Name, existing)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to get the '%s' NSTemplateTier", tierName)
+			}
+			if exists {
+				previous = existing
+			}
 		}
 
 		labels := tier.GetLabels()
@@ -380,10 +480,17 @@ func (t *TierGenerator) createNSTemplateTiers() error {
 			labels = make(map[string]string)
 		}
 		labels[toolchainv1alpha1.ProviderLabelKey] = toolchainv1alpha1.ProviderLabelValue
+		tierReport, err := newTierReport(previous, tier, t.config.reader != nil, t.config.previousHashVersion, t.config.hashVersion, newTierTemplates[tierName])
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to compute the hash of the '%s' NSTemplateTier", tierName)
+		}
 		updated, err := t.ensureObject(tier, true, tierName)
 		if err != nil {
-			return errors.Wrapf(err, "unable to create or update the '%s' NSTemplateTier", tierName)
+			return nil, errors.Wrapf(err, "unable to create or update the '%s' NSTemplateTier", tierName)
 		}
+		tierReport.Updated = updated
+		tierReport.Created = updated && previous == nil && t.config.reader != nil
+		report.Tiers = append(report.Tiers, tierReport)
 		tierLog := log.WithValues("name", tierName)
 		if tier.Spec.ClusterResources != nil {
 			tierLog = tierLog.WithValues("clusterResourcesTemplate", tier.Spec.ClusterResources.TemplateRef)
@@ -400,7 +507,10 @@ func (t *TierGenerator) createNSTemplateTiers() error {
 			tierLog.Info("NSTemplateTier wasn't updated nor created: the spec was already set as expected")
 		}
 	}
-	return nil
+	sort.Slice(report.Tiers, func(i, j int) bool {
+		return report.Tiers[i].Name < report.Tiers[j].Name
+	})
+	return report, nil
 }
 
 // NewNSTemplateTier generates a complete NSTemplateTier object via Openshift Template based on the contents of tier.yaml and
@@ -15,6 +15,7 @@ import (
 
 	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
 	commonclient "github.com/codeready-toolchain/toolchain-common/pkg/client"
+	commonTemplate "github.com/codeready-toolchain/toolchain-common/pkg/template"
 	"github.com/codeready-toolchain/toolchain-common/pkg/test"
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -206,7 +207,7 @@ func TestGenerateTiers(t *testing.T) {
 			require.Empty(t, tierTmpls.Items)
 
 			// when
-			err = GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t))
+			_, err = GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t))
 
 			// then
 			require.NoError(t, err)
@@ -278,11 +279,11 @@ func TestGenerateTiers(t *testing.T) {
 			clt := test.NewFakeClient(t)
 
 			// when
-			err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t))
+			_, err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t))
 			require.NoError(t, err)
 
 			// when calling CreateOrUpdateResources a second time
-			err = GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t))
+			_, err = GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t))
 
 			// then
 			require.NoError(t, err)
@@ -334,7 +335,7 @@ func TestGenerateTiers(t *testing.T) {
 			clt := test.NewFakeClient(t)
 
 			// when
-			err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t))
+			_, err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t))
 			require.NoError(t, err)
 
 			// given a new set of tier templates (same content but new revisions, which is what we'll want to check here)
@@ -355,7 +356,7 @@ func TestGenerateTiers(t *testing.T) {
 			}
 
 			// when calling CreateOrUpdateResources a second time
-			err = GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, metadata, getTestTemplates(t))
+			_, err = GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, metadata, getTestTemplates(t))
 
 			// then
 			require.NoError(t, err)
@@ -463,7 +464,7 @@ func TestGenerateTiers(t *testing.T) {
 				}
 
 				// when
-				err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t))
+				_, err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t))
 				// then
 				require.Error(t, err)
 				assert.Regexp(t, "unable to create or update the '\\w+' NSTemplateTier: unable to create resource of kind: NSTemplateTier, version: v1alpha1: an error", err.Error())
@@ -476,7 +477,7 @@ func TestGenerateTiers(t *testing.T) {
 				delete(testTemplates, "appstudio/tier.yaml")
 
 				// when
-				err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), testTemplates)
+				_, err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), testTemplates)
 				// then
 				require.EqualError(t, err, "unable to init NSTemplateTier generator: tier appstudio is missing a tier.yaml file")
 			})
@@ -499,7 +500,7 @@ func TestGenerateTiers(t *testing.T) {
 				}
 
 				// when
-				err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t))
+				_, err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t))
 
 				// then
 				require.Error(t, err)
@@ -518,7 +519,7 @@ func TestGenerateTiers(t *testing.T) {
 				}
 
 				// when
-				err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t))
+				_, err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t))
 
 				// then
 				require.Error(t, err)
@@ -777,6 +778,51 @@ func TestNewTierTemplate(t *testing.T) {
 				})
 			}
 		})
+
+		t.Run("with Go template", func(t *testing.T) {
+			// given
+			testTemplates := getTestTemplates(t)
+			delete(testTemplates, "base/ns_dev.yaml")
+			testTemplates["base/ns_dev.gotmpl"] = []byte(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: {{ .SPACE_NAME }}-dev
+  annotations:
+    idler-timeout: {{ index . "IDLER_TIMEOUT_SECONDS" | default "43200" | quote }}
+`)
+
+			// when
+			tc, err := newNSTemplateTierGenerator(s, nil, namespace, getTestMetadata(), testTemplates)
+
+			// then
+			require.NoError(t, err)
+			for _, tier := range []string{"base", "advanced"} {
+				var devTemplate *toolchainv1alpha1.TierTemplate
+				for _, tierTmpl := range tc.templatesByTier[tier].tierTemplates {
+					if tierTmpl.Spec.Type == "dev" {
+						devTemplate = tierTmpl
+					}
+				}
+				require.NotNil(t, devTemplate, "missing 'dev' TierTemplate for tier '%s'", tier)
+				assert.True(t, commonTemplate.IsGoTemplate(&devTemplate.Spec.Template))
+				assert.Equal(t, tier+"-dev", devTemplate.Spec.Template.Name)
+
+				objs, err := commonTemplate.NewProcessor(s).Process(devTemplate.Spec.Template.DeepCopy(), map[string]string{
+					"SPACE_NAME": "john",
+				})
+				require.NoError(t, err)
+				require.Len(t, objs, 1)
+				assert.Equal(t, "john-dev", objs[0].GetName())
+				if tier == "advanced" {
+					// the parameter of the `based_on_tier.yaml` file is declared in the template
+					assert.Equal(t, []templatev1.Parameter{{Name: "IDLER_TIMEOUT_SECONDS", Value: "518400"}}, devTemplate.Spec.Template.Parameters)
+					assert.Equal(t, "518400", objs[0].GetAnnotations()["idler-timeout"])
+				} else {
+					assert.Empty(t, devTemplate.Spec.Template.Parameters)
+					assert.Equal(t, "43200", objs[0].GetAnnotations()["idler-timeout"])
+				}
+			}
+		})
 	})
 
 	t.Run("failures", func(t *testing.T) {
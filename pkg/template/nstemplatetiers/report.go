@@ -0,0 +1,214 @@
+package nstemplatetiers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/hash"
+)
+
+// Report summarizes the outcome of GenerateTiers: for each NSTemplateTier, which TierTemplates are new,
+// which template refs changed in its spec and how its hash changed. Since the hash of an NSTemplateTier is what
+// the NSTemplateSets are compared with, a tier with a changed hash is a tier whose users will get an update.
+type Report struct {
+	Tiers []TierReport `json:"tiers"`
+}
+
+// TierReport the report for a single NSTemplateTier
+type TierReport struct {
+	Name string `json:"name"`
+	// Created is `true` when the NSTemplateTier did not exist before
+	Created bool `json:"created"`
+	// Updated is `true` when the NSTemplateTier was either created or updated
+	Updated bool `json:"updated"`
+	// NewTierTemplates the names of the TierTemplates which were created for this tier
+	NewTierTemplates []string `json:"newTierTemplates,omitempty"`
+	// ChangedTemplateRefs the template refs which differ between the previous and the new version of the NSTemplateTier spec
+	ChangedTemplateRefs []TemplateRefChange `json:"changedTemplateRefs,omitempty"`
+	// OldHash the hash of the NSTemplateTier before it was updated (empty if it did not exist or if no reader was provided)
+	OldHash string `json:"oldHash,omitempty"`
+	// NewHash the hash of the NSTemplateTier as generated
+	NewHash string `json:"newHash"`
+	// PreviousUnknown is `true` when no reader was provided, in which case the previous version of the NSTemplateTier
+	// is unknown: the template refs are not compared and the hash is not reported as changed
+	PreviousUnknown bool `json:"previousUnknown,omitempty"`
+}
+
+// TemplateRefChange a template ref which was added, removed or replaced in the NSTemplateTier spec.
+// The field is `clusterResources`, `namespaces` or `spaceRoles.<role>`.
+// For namespaces, refs are compared as a set: a removed ref only has an `Old` value and an added ref only has a `New` value.
+type TemplateRefChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// HashChanged returns `true` if the hash of the tier changed, ie, if the NSTemplateSets using this tier will be updated
+// (always `false` when the previous version of the tier is unknown)
+func (r TierReport) HashChanged() bool {
+	return !r.PreviousUnknown && r.OldHash != r.NewHash
+}
+
+// ChangedTiers returns the reports of the tiers whose hash changed
+func (r *Report) ChangedTiers() []TierReport {
+	changed := []TierReport{}
+	for _, t := range r.Tiers {
+		if t.HashChanged() {
+			changed = append(changed, t)
+		}
+	}
+	return changed
+}
+
+// WriteJSON writes the report in JSON format
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes the report in a human-readable format
+func (r *Report) WriteText(w io.Writer) error {
+	for _, t := range r.Tiers {
+		status := "unchanged"
+		switch {
+		case t.Created:
+			status = "created"
+		case t.Updated:
+			status = "updated"
+		}
+		if _, err := fmt.Fprintf(w, "NSTemplateTier '%s': %s\n", t.Name, status); err != nil {
+			return err
+		}
+		hashLine := fmt.Sprintf("  hash: %s\n", t.NewHash)
+		switch {
+		case t.PreviousUnknown:
+			hashLine = fmt.Sprintf("  hash: %s (previous hash unknown)\n", t.NewHash)
+		case t.HashChanged():
+			hashLine = fmt.Sprintf("  hash: %s -> %s (users of this tier will be updated)\n", orNone(t.OldHash), t.NewHash)
+		}
+		if _, err := io.WriteString(w, hashLine); err != nil {
+			return err
+		}
+		if len(t.NewTierTemplates) > 0 {
+			if _, err := io.WriteString(w, "  new TierTemplates:\n"); err != nil {
+				return err
+			}
+			for _, name := range t.NewTierTemplates {
+				if _, err := fmt.Fprintf(w, "    - %s\n", name); err != nil {
+					return err
+				}
+			}
+		}
+		if len(t.ChangedTemplateRefs) > 0 {
+			if _, err := io.WriteString(w, "  changed template refs:\n"); err != nil {
+				return err
+			}
+			for _, c := range t.ChangedTemplateRefs {
+				if _, err := fmt.Fprintf(w, "    - %s: %s -> %s\n", c.Field, orNone(c.Old), orNone(c.New)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
+
+// newTierReport computes the report for the given tier, compared with its previous version (which is `nil` if the tier
+// did not exist). When `previousKnown` is `false`, the previous version could not be retrieved and is not compared at all.
+// The hash of the previous version is computed with `previousVersion` of the algorithm and the new hash with `version`.
+func newTierReport(previous, tier *toolchainv1alpha1.NSTemplateTier, previousKnown bool, previousVersion, version int, newTierTemplates []string) (TierReport, error) {
+	newHash, err := hash.ComputeHashForNSTemplateTierWithVersion(tier, version)
+	if err != nil {
+		return TierReport{}, err
+	}
+	sort.Strings(newTierTemplates)
+	report := TierReport{
+		Name:             tier.Name,
+		NewTierTemplates: newTierTemplates,
+		NewHash:          newHash,
+		PreviousUnknown:  !previousKnown,
+	}
+	if !previousKnown {
+		return report, nil
+	}
+	if previous == nil {
+		previous = &toolchainv1alpha1.NSTemplateTier{}
+	} else if report.OldHash, err = hash.ComputeHashForNSTemplateTierWithVersion(previous, previousVersion); err != nil {
+		return TierReport{}, err
+	}
+	report.ChangedTemplateRefs = diffTemplateRefs(previous.Spec, tier.Spec)
+	return report, nil
+}
+
+func diffTemplateRefs(previous, current toolchainv1alpha1.NSTemplateTierSpec) []TemplateRefChange {
+	var changes []TemplateRefChange
+
+	// cluster resources
+	var previousClusterRef, currentClusterRef string
+	if previous.ClusterResources != nil {
+		previousClusterRef = previous.ClusterResources.TemplateRef
+	}
+	if current.ClusterResources != nil {
+		currentClusterRef = current.ClusterResources.TemplateRef
+	}
+	if previousClusterRef != currentClusterRef {
+		changes = append(changes, TemplateRefChange{Field: "clusterResources", Old: previousClusterRef, New: currentClusterRef})
+	}
+
+	// namespaces
+	previousNsRefs := map[string]bool{}
+	for _, ns := range previous.Namespaces {
+		previousNsRefs[ns.TemplateRef] = true
+	}
+	currentNsRefs := map[string]bool{}
+	for _, ns := range current.Namespaces {
+		currentNsRefs[ns.TemplateRef] = true
+	}
+	for _, ref := range sortedKeys(previousNsRefs) {
+		if !currentNsRefs[ref] {
+			changes = append(changes, TemplateRefChange{Field: "namespaces", Old: ref})
+		}
+	}
+	for _, ref := range sortedKeys(currentNsRefs) {
+		if !previousNsRefs[ref] {
+			changes = append(changes, TemplateRefChange{Field: "namespaces", New: ref})
+		}
+	}
+
+	// space roles
+	roles := map[string]bool{}
+	for role := range previous.SpaceRoles {
+		roles[role] = true
+	}
+	for role := range current.SpaceRoles {
+		roles[role] = true
+	}
+	for _, role := range sortedKeys(roles) {
+		previousRef := previous.SpaceRoles[role].TemplateRef
+		currentRef := current.SpaceRoles[role].TemplateRef
+		if previousRef != currentRef {
+			changes = append(changes, TemplateRefChange{Field: "spaceRoles." + role, Old: previousRef, New: currentRef})
+		}
+	}
+	return changes
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
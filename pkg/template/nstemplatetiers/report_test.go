@@ -0,0 +1,310 @@
+package nstemplatetiers
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/hash"
+	"github.com/codeready-toolchain/toolchain-common/pkg/test"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateTiersReport(t *testing.T) {
+	// given
+	s := addToScheme(t)
+
+	t.Run("with reader", func(t *testing.T) {
+		// given
+		namespace := "host-operator" + uuid.NewString()[:7]
+		clt := test.NewFakeClient(t)
+
+		// when
+		report, err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t), WithReader(clt))
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, report.Tiers, 4)
+		assert.Equal(t, []string{"advanced", "appstudio", "base", "nocluster"}, tierNames(report))
+		base := report.Tiers[2]
+		assert.True(t, base.Created)
+		assert.True(t, base.Updated)
+		assert.True(t, base.HashChanged())
+		assert.Empty(t, base.OldHash)
+		assert.Equal(t, []string{
+			"base-admin-123456d-123456d",
+			"base-clusterresources-654321a-654321a",
+			"base-dev-123456b-123456b",
+			"base-stage-123456c-123456c",
+		}, base.NewTierTemplates)
+		assert.Equal(t, []TemplateRefChange{
+			{Field: "clusterResources", New: "base-clusterresources-654321a-654321a"},
+			{Field: "namespaces", New: "base-dev-123456b-123456b"},
+			{Field: "namespaces", New: "base-stage-123456c-123456c"},
+			{Field: "spaceRoles.admin", New: "base-admin-123456d-123456d"},
+		}, base.ChangedTemplateRefs)
+
+		t.Run("same templates", func(t *testing.T) {
+			// when
+			report, err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t), WithReader(clt))
+
+			// then
+			require.NoError(t, err)
+			require.Len(t, report.Tiers, 4)
+			assert.Empty(t, report.ChangedTiers())
+			for _, tierReport := range report.Tiers {
+				assert.False(t, tierReport.Created)
+				assert.NotEmpty(t, tierReport.OldHash)
+				assert.Equal(t, tierReport.OldHash, tierReport.NewHash)
+				assert.Empty(t, tierReport.NewTierTemplates)
+				assert.Empty(t, tierReport.ChangedTemplateRefs)
+			}
+		})
+
+		t.Run("new revision of a namespace template", func(t *testing.T) {
+			// given
+			metadata := getTestMetadata()
+			metadata["base/ns_dev"] = "222222b"
+
+			// when
+			report, err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, metadata, getTestTemplates(t), WithReader(clt))
+
+			// then
+			require.NoError(t, err)
+			changed := report.ChangedTiers()
+			// the `advanced` tier is based on the `base` tier, so it is changed too
+			require.Len(t, changed, 2)
+			assert.Equal(t, "advanced", changed[0].Name)
+			assert.Equal(t, []string{"advanced-dev-abcd123-222222b"}, changed[0].NewTierTemplates)
+			base := changed[1]
+			assert.Equal(t, "base", base.Name)
+			assert.False(t, base.Created)
+			assert.True(t, base.Updated)
+			assert.Equal(t, []string{"base-dev-222222b-222222b"}, base.NewTierTemplates)
+			assert.Equal(t, []TemplateRefChange{
+				{Field: "namespaces", Old: "base-dev-123456b-123456b"},
+				{Field: "namespaces", New: "base-dev-222222b-222222b"},
+			}, base.ChangedTemplateRefs)
+			assert.NotEqual(t, base.OldHash, base.NewHash)
+		})
+	})
+
+	t.Run("with a new version of the hash", func(t *testing.T) {
+		// given
+		namespace := "host-operator" + uuid.NewString()[:7]
+		clt := test.NewFakeClient(t)
+		_, err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t), WithReader(clt))
+		require.NoError(t, err)
+
+		// when
+		report, err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t),
+			WithReader(clt), WithPreviousHashVersion(hash.HashVersion1), WithHashVersion(hash.HashVersion2))
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, report.ChangedTiers(), 4)
+		for _, tierReport := range report.Tiers {
+			assert.Equal(t, hash.HashVersion1, hash.HashVersion(tierReport.OldHash))
+			assert.Equal(t, hash.HashVersion2, hash.HashVersion(tierReport.NewHash))
+			assert.Empty(t, tierReport.NewTierTemplates)
+			assert.Empty(t, tierReport.ChangedTemplateRefs)
+		}
+
+		t.Run("same version", func(t *testing.T) {
+			// when
+			report, err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t),
+				WithReader(clt), WithHashVersion(hash.HashVersion2))
+
+			// then
+			require.NoError(t, err)
+			assert.Empty(t, report.ChangedTiers())
+		})
+	})
+
+	t.Run("without reader", func(t *testing.T) {
+		// given
+		namespace := "host-operator" + uuid.NewString()[:7]
+		clt := test.NewFakeClient(t)
+
+		// when
+		report, err := GenerateTiers(s, ensureObjectFuncForClient(clt), namespace, getTestMetadata(), getTestTemplates(t))
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, report.Tiers, 4)
+		assert.Empty(t, report.ChangedTiers()) // can't tell without a reader
+		for _, tierReport := range report.Tiers {
+			assert.False(t, tierReport.Created) // can't tell without a reader
+			assert.True(t, tierReport.Updated)
+			assert.True(t, tierReport.PreviousUnknown)
+			assert.False(t, tierReport.HashChanged())
+			assert.Empty(t, tierReport.OldHash)
+			assert.NotEmpty(t, tierReport.NewHash)
+			assert.NotEmpty(t, tierReport.NewTierTemplates)
+			assert.Empty(t, tierReport.ChangedTemplateRefs)
+		}
+	})
+}
+
+func TestNewTierReport(t *testing.T) {
+	// given
+	previous := &toolchainv1alpha1.NSTemplateTier{
+		Spec: toolchainv1alpha1.NSTemplateTierSpec{
+			ClusterResources: &toolchainv1alpha1.NSTemplateTierClusterResources{
+				TemplateRef: "base-clusterresources-abcd123-abcd123",
+			},
+			Namespaces: []toolchainv1alpha1.NSTemplateTierNamespace{
+				{TemplateRef: "base-dev-abcd123-abcd123"},
+				{TemplateRef: "base-stage-abcd123-abcd123"},
+			},
+			SpaceRoles: map[string]toolchainv1alpha1.NSTemplateTierSpaceRole{
+				"admin":  {TemplateRef: "base-admin-abcd123-abcd123"},
+				"viewer": {TemplateRef: "base-viewer-abcd123-abcd123"},
+			},
+		},
+	}
+	current := previous.DeepCopy()
+	current.Name = "base"
+	current.Spec.ClusterResources = nil
+	current.Spec.Namespaces[1].TemplateRef = "base-stage-1234567-1234567"
+	delete(current.Spec.SpaceRoles, "viewer")
+	current.Spec.SpaceRoles["contributor"] = toolchainv1alpha1.NSTemplateTierSpaceRole{TemplateRef: "base-contributor-1234567-1234567"}
+
+	t.Run("with previous version", func(t *testing.T) {
+		// when
+		report, err := newTierReport(previous, current, true, hash.HashVersion1, hash.HashVersion1, []string{"base-stage-1234567-1234567", "base-contributor-1234567-1234567"})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "base", report.Name)
+		assert.Equal(t, []string{"base-contributor-1234567-1234567", "base-stage-1234567-1234567"}, report.NewTierTemplates)
+		assert.Equal(t, []TemplateRefChange{
+			{Field: "clusterResources", Old: "base-clusterresources-abcd123-abcd123"},
+			{Field: "namespaces", Old: "base-stage-abcd123-abcd123"},
+			{Field: "namespaces", New: "base-stage-1234567-1234567"},
+			{Field: "spaceRoles.contributor", New: "base-contributor-1234567-1234567"},
+			{Field: "spaceRoles.viewer", Old: "base-viewer-abcd123-abcd123"},
+		}, report.ChangedTemplateRefs)
+		oldHash, err := hash.ComputeHashForNSTemplateTier(previous)
+		require.NoError(t, err)
+		newHash, err := hash.ComputeHashForNSTemplateTier(current)
+		require.NoError(t, err)
+		assert.Equal(t, oldHash, report.OldHash)
+		assert.Equal(t, newHash, report.NewHash)
+		assert.True(t, report.HashChanged())
+	})
+
+	t.Run("with a new version of the hash", func(t *testing.T) {
+		// given
+		previous := previous.DeepCopy()
+		previous.Name = "base"
+		same := previous.DeepCopy()
+
+		// when
+		report, err := newTierReport(previous, same, true, hash.HashVersion1, hash.HashVersion2, nil)
+
+		// then
+		require.NoError(t, err)
+		oldHash, err := hash.ComputeHashForNSTemplateTierWithVersion(previous, hash.HashVersion1)
+		require.NoError(t, err)
+		newHash, err := hash.ComputeHashForNSTemplateTierWithVersion(same, hash.HashVersion2)
+		require.NoError(t, err)
+		assert.Equal(t, oldHash, report.OldHash)
+		assert.Equal(t, newHash, report.NewHash)
+		assert.True(t, report.HashChanged()) // the spec is the same, but the users will be updated anyway
+		assert.Empty(t, report.ChangedTemplateRefs)
+	})
+
+	t.Run("previous version unknown", func(t *testing.T) {
+		// when
+		report, err := newTierReport(nil, current, false, hash.HashVersion1, hash.HashVersion1, []string{"base-stage-1234567-1234567"})
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, report.PreviousUnknown)
+		assert.False(t, report.HashChanged())
+		assert.Empty(t, report.OldHash)
+		assert.NotEmpty(t, report.NewHash)
+		assert.Empty(t, report.ChangedTemplateRefs)
+		assert.Equal(t, []string{"base-stage-1234567-1234567"}, report.NewTierTemplates)
+	})
+}
+
+func TestWriteReport(t *testing.T) {
+	// given
+	report := &Report{
+		Tiers: []TierReport{
+			{
+				Name:             "base",
+				Updated:          true,
+				NewTierTemplates: []string{"base-dev-222222b-222222b"},
+				ChangedTemplateRefs: []TemplateRefChange{
+					{Field: "namespaces", Old: "base-dev-123456b-123456b"},
+					{Field: "namespaces", New: "base-dev-222222b-222222b"},
+				},
+				OldHash: "abc",
+				NewHash: "def",
+			},
+			{
+				Name:    "nocluster",
+				OldHash: "123",
+				NewHash: "123",
+			},
+			{
+				Name:            "appstudio",
+				Updated:         true,
+				NewHash:         "456",
+				PreviousUnknown: true,
+			},
+		},
+	}
+
+	t.Run("text", func(t *testing.T) {
+		// given
+		buf := &bytes.Buffer{}
+
+		// when
+		err := report.WriteText(buf)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `NSTemplateTier 'base': updated
+  hash: abc -> def (users of this tier will be updated)
+  new TierTemplates:
+    - base-dev-222222b-222222b
+  changed template refs:
+    - namespaces: base-dev-123456b-123456b -> <none>
+    - namespaces: <none> -> base-dev-222222b-222222b
+NSTemplateTier 'nocluster': unchanged
+  hash: 123
+NSTemplateTier 'appstudio': updated
+  hash: 456 (previous hash unknown)
+`, buf.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		// given
+		buf := &bytes.Buffer{}
+
+		// when
+		err := report.WriteJSON(buf)
+
+		// then
+		require.NoError(t, err)
+		actual := &Report{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), actual))
+		assert.Equal(t, report, actual)
+		assert.Contains(t, buf.String(), `"oldHash": "abc"`)
+	})
+}
+
+func tierNames(report *Report) []string {
+	names := make([]string, len(report.Tiers))
+	for i, t := range report.Tiers {
+		names[i] = t.Name
+	}
+	return names
+}
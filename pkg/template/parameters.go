@@ -0,0 +1,133 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	templatev1 "github.com/openshift/api/template/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ParameterTypeAnnotationKeyPrefix the prefix of the template annotations which declare the type of a parameter.
+// For example, `toolchain.dev.openshift.com/param-type-IDLER_TIMEOUT_SECONDS: int` declares that the value of the
+// `IDLER_TIMEOUT_SECONDS` parameter must be an integer. Parameters without such an annotation are strings.
+const ParameterTypeAnnotationKeyPrefix = toolchainv1alpha1.LabelKeyPrefix + "param-type-"
+
+// ParameterType the type of a template parameter
+type ParameterType string
+
+const (
+	StringParameterType   ParameterType = "string"
+	IntParameterType      ParameterType = "int"
+	BoolParameterType     ParameterType = "bool"
+	DurationParameterType ParameterType = "duration"
+)
+
+var supportedParameterTypes = []string{
+	string(StringParameterType),
+	string(IntParameterType),
+	string(BoolParameterType),
+	string(DurationParameterType),
+}
+
+// ValidateParameters verifies the given values against the parameters of the given template and returns an error
+// for each unknown key in the values, for each required parameter which has no value, for each value which does not
+// match the expression in the `From` field of its parameter and for each value which does not match the type declared
+// for its parameter with the ParameterTypeAnnotationKeyPrefix annotation.
+func ValidateParameters(tmpl *templatev1.Template, values map[string]string) field.ErrorList {
+	errs := field.ErrorList{}
+	path := field.NewPath("parameters")
+
+	params := make(map[string]templatev1.Parameter, len(tmpl.Parameters))
+	for _, param := range tmpl.Parameters {
+		params[param.Name] = param
+	}
+
+	// process the keys in alphabetical order to get predictive errors
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, found := params[key]; !found {
+			errs = append(errs, field.NotFound(path.Key(key), values[key]))
+		}
+	}
+
+	for _, param := range tmpl.Parameters {
+		paramPath := path.Key(param.Name)
+		value, provided := values[param.Name]
+		if !provided || value == "" {
+			value = param.Value
+			provided = false
+		}
+		if value == "" {
+			if param.Required && param.Generate == "" {
+				errs = append(errs, field.Required(paramPath, "no value was provided for this required parameter"))
+			}
+			continue
+		}
+		if provided && param.From != "" {
+			if err := matchesExpression(param.From, value); err != nil {
+				errs = append(errs, field.Invalid(paramPath, value, err.Error()))
+			}
+		}
+		paramType := ParameterType(tmpl.Annotations[ParameterTypeAnnotationKeyPrefix+param.Name])
+		if paramType != "" && !isSupportedType(paramType) {
+			errs = append(errs, field.NotSupported(field.NewPath("metadata", "annotations").Key(ParameterTypeAnnotationKeyPrefix+param.Name), paramType, supportedParameterTypes))
+			continue
+		}
+		if err := checkType(paramType, value); err != nil {
+			errs = append(errs, field.Invalid(paramPath, value, err.Error()))
+		}
+	}
+	return errs
+}
+
+// matchesExpression verifies that the given value matches the given expression, using the same syntax as the
+// `expression` generator, ie, a regular expression in which `\a` stands for an alphanumeric character and `\A` for
+// any printable ASCII character
+func matchesExpression(expression, value string) error {
+	expr := strings.NewReplacer(`\a`, `a-zA-Z0-9`, `\A`, `!-~`).Replace(expression)
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return fmt.Errorf("invalid expression '%s': %w", expression, err)
+	}
+	if !re.MatchString(value) {
+		return fmt.Errorf("value does not match the expression '%s'", expression)
+	}
+	return nil
+}
+
+func checkType(paramType ParameterType, value string) error {
+	switch paramType {
+	case IntParameterType:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("value is not a valid %s", paramType)
+		}
+	case BoolParameterType:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("value is not a valid %s", paramType)
+		}
+	case DurationParameterType:
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("value is not a valid %s", paramType)
+		}
+	}
+	return nil
+}
+
+func isSupportedType(paramType ParameterType) bool {
+	for _, t := range supportedParameterTypes {
+		if string(paramType) == t {
+			return true
+		}
+	}
+	return false
+}
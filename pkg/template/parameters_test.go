@@ -0,0 +1,142 @@
+package template_test
+
+import (
+	"testing"
+
+	"github.com/codeready-toolchain/toolchain-common/pkg/template"
+	. "github.com/codeready-toolchain/toolchain-common/pkg/test"
+	templatev1 "github.com/openshift/api/template/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+)
+
+func TestValidateParameters(t *testing.T) {
+
+	newTemplate := func(annotations map[string]string, params ...templatev1.Parameter) *templatev1.Template {
+		return &templatev1.Template{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test",
+				Annotations: annotations,
+			},
+			Parameters: params,
+		}
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		// given
+		tmpl := newTemplate(map[string]string{
+			template.ParameterTypeAnnotationKeyPrefix + "TIMEOUT":  "int",
+			template.ParameterTypeAnnotationKeyPrefix + "ENABLED":  "bool",
+			template.ParameterTypeAnnotationKeyPrefix + "PERIOD":   "duration",
+			template.ParameterTypeAnnotationKeyPrefix + "USERNAME": "string",
+		},
+			templatev1.Parameter{Name: "USERNAME", Required: true},
+			templatev1.Parameter{Name: "TIMEOUT", Value: "30"},
+			templatev1.Parameter{Name: "ENABLED"},
+			templatev1.Parameter{Name: "PERIOD", Value: "1h"},
+			templatev1.Parameter{Name: "PASSWORD", Required: true, Generate: "expression", From: `[\a]{10}`},
+			templatev1.Parameter{Name: "SUFFIX", From: `[a-z0-9]{5}`},
+		)
+
+		// when
+		errs := template.ValidateParameters(tmpl, map[string]string{
+			"USERNAME": "johnsmith",
+			"ENABLED":  "true",
+			"SUFFIX":   "ab3d5",
+		})
+
+		// then
+		assert.Empty(t, errs)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		// given
+		tmpl := newTemplate(map[string]string{
+			template.ParameterTypeAnnotationKeyPrefix + "TIMEOUT": "int",
+			template.ParameterTypeAnnotationKeyPrefix + "ENABLED": "bool",
+			template.ParameterTypeAnnotationKeyPrefix + "PERIOD":  "duration",
+			template.ParameterTypeAnnotationKeyPrefix + "RATIO":   "float",
+		},
+			templatev1.Parameter{Name: "USERNAME", Required: true},
+			templatev1.Parameter{Name: "TIMEOUT", Value: "30"},
+			templatev1.Parameter{Name: "ENABLED"},
+			templatev1.Parameter{Name: "PERIOD", Value: "1 hour"},
+			templatev1.Parameter{Name: "RATIO", Value: "0.5"},
+			templatev1.Parameter{Name: "SUFFIX", From: `[a-z0-9]{5}`},
+		)
+
+		// when
+		errs := template.ValidateParameters(tmpl, map[string]string{
+			"TIMEOUT": "thirty",
+			"ENABLED": "yes please",
+			"SUFFIX":  "ab3d5f",
+			"UNKNOWN": "foo",
+		})
+
+		// then
+		require.Len(t, errs, 7)
+		assert.Equal(t, `parameters[UNKNOWN]: Not found: "foo"`, errs[0].Error())
+		assert.Equal(t, `parameters[USERNAME]: Required value: no value was provided for this required parameter`, errs[1].Error())
+		assert.Equal(t, `parameters[TIMEOUT]: Invalid value: "thirty": value is not a valid int`, errs[2].Error())
+		assert.Equal(t, `parameters[ENABLED]: Invalid value: "yes please": value is not a valid bool`, errs[3].Error())
+		assert.Equal(t, `parameters[PERIOD]: Invalid value: "1 hour": value is not a valid duration`, errs[4].Error())
+		assert.Equal(t, `metadata.annotations[toolchain.dev.openshift.com/param-type-RATIO]: Unsupported value: "float": supported values: "string", "int", "bool", "duration"`, errs[5].Error())
+		assert.Equal(t, `parameters[SUFFIX]: Invalid value: "ab3d5f": value does not match the expression '[a-z0-9]{5}'`, errs[6].Error())
+	})
+
+	t.Run("invalid expression", func(t *testing.T) {
+		// given
+		tmpl := newTemplate(nil, templatev1.Parameter{Name: "SUFFIX", From: `[a-z`})
+
+		// when
+		errs := template.ValidateParameters(tmpl, map[string]string{
+			"SUFFIX": "abc",
+		})
+
+		// then
+		require.Len(t, errs, 1)
+		assert.Contains(t, errs[0].Error(), `parameters[SUFFIX]: Invalid value: "abc": invalid expression '[a-z'`)
+	})
+}
+
+func TestProcessWithParameterValidation(t *testing.T) {
+	// given
+	s := addToScheme(t)
+	decoder := serializer.NewCodecFactory(s).UniversalDeserializer()
+	p := template.NewProcessor(s, template.WithParameterValidation(true))
+
+	t.Run("should process template successfully", func(t *testing.T) {
+		// given
+		tmpl, err := DecodeTemplate(decoder,
+			CreateTemplate(WithObjects(Namespace, RoleBinding), WithParams(UsernameParam, CommitParam)))
+		require.NoError(t, err)
+
+		// when
+		objs, err := p.Process(tmpl, map[string]string{
+			"USERNAME": "johnsmith",
+		})
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, objs, 2)
+	})
+
+	t.Run("should fail because of unknown and missing parameters", func(t *testing.T) {
+		// given
+		tmpl, err := DecodeTemplate(decoder,
+			CreateTemplate(WithObjects(Namespace, RoleBinding), WithParams(UsernameParamWithoutValue, CommitParam)))
+		require.NoError(t, err)
+
+		// when
+		objs, err := p.Process(tmpl, map[string]string{
+			"random": "foo",
+		})
+
+		// then
+		require.EqualError(t, err, `invalid template parameters: [parameters[random]: Not found: "foo", parameters[USERNAME]: Required value: no value was provided for this required parameter]`)
+		assert.Nil(t, objs)
+	})
+}
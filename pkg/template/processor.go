@@ -2,6 +2,7 @@ package template
 
 import (
 	"fmt"
+	"hash/fnv"
 	"math/rand"
 	"time"
 
@@ -15,19 +16,68 @@ import (
 
 // Processor the tool that will process and apply a template with variables
 type Processor struct {
-	scheme *runtime.Scheme
+	scheme             *runtime.Scheme
+	validateParameters bool
+	seed               *int64
+	seedKey            string
+	transforms         []TransformFunc
+}
+
+// ProcessorOption an option to configure the Processor
+type ProcessorOption func(*Processor)
+
+// WithParameterValidation enables the validation of the values against the template parameters before processing the template
+// (default: `false`). See ValidateParameters for more details.
+func WithParameterValidation(validate bool) ProcessorOption {
+	return func(p *Processor) {
+		p.validateParameters = validate
+	}
+}
+
+// WithSeed sets the seed of the generator used for the parameters with `generate: expression` (default: the current time).
+// Parameters are generated in the order in which they are declared in the template, so the same seed always yields the same values.
+func WithSeed(seed int64) ProcessorOption {
+	return func(p *Processor) {
+		p.seed = &seed
+	}
+}
+
+// WithSeedKey makes the generation of the parameters with `generate: expression` deterministic: the value of each such
+// parameter is generated from a seed derived from the given key (for example, the UID of the owner of the resources)
+// and from the name of the parameter. Generated values are thus stable across calls with the same key, but differ between keys.
+func WithSeedKey(key string) ProcessorOption {
+	return func(p *Processor) {
+		p.seedKey = key
+	}
+}
+
+// WithTransforms sets the funcs to apply, in order, on the objects returned by Process, after they were filtered
+func WithTransforms(transforms ...TransformFunc) ProcessorOption {
+	return func(p *Processor) {
+		p.transforms = append(p.transforms, transforms...)
+	}
 }
 
 // NewProcessor returns a new Processor
-func NewProcessor(scheme *runtime.Scheme) Processor {
-	return Processor{
+func NewProcessor(scheme *runtime.Scheme, options ...ProcessorOption) Processor {
+	p := Processor{
 		scheme: scheme,
 	}
+	for _, apply := range options {
+		apply(&p)
+	}
+	return p
 }
 
 // Process processes the template (ie, replaces the variables with their actual values) and optionally filters the result
-// to return a subset of the template objects
+// to return a subset of the template objects, on which the transforms of the Processor are applied.
+// The template is processed with the engine specified in its TemplateEngineAnnotationKey annotation (OpenShift Template by default).
 func (p Processor) Process(tmpl *templatev1.Template, values map[string]string, filters ...FilterFunc) ([]runtimeclient.Object, error) {
+	if p.validateParameters {
+		if errs := ValidateParameters(tmpl, values); len(errs) > 0 {
+			return nil, errors.Wrap(errs.ToAggregate(), "invalid template parameters")
+		}
+	}
 	// inject variables in the twmplate
 	for param, val := range values {
 		v := templateprocessing.GetParameterByName(tmpl, param)
@@ -37,18 +87,42 @@ func (p Processor) Process(tmpl *templatev1.Template, values map[string]string,
 		}
 	}
 
+	if p.seedKey != "" {
+		// work on a copy, so that the generated values are not kept in the parameters of the caller's template
+		tmpl = tmpl.DeepCopy()
+		if err := generateParameterValues(tmpl, p.seedKey); err != nil {
+			return nil, errors.Wrap(err, "unable to process template")
+		}
+	}
+
 	// convert the template into a set of objects
+	seed := time.Now().UnixNano()
+	if p.seed != nil {
+		seed = *p.seed
+	}
 	tmplProcessor := templateprocessing.NewProcessor(map[string]generator.Generator{
-		"expression": generator.NewExpressionValueGenerator(rand.New(rand.NewSource(time.Now().UnixNano()))), //nolint:gosec
+		"expression": generator.NewExpressionValueGenerator(rand.New(rand.NewSource(seed))), //nolint:gosec
 	})
-	if err := tmplProcessor.Process(tmpl); len(err) > 0 {
-		return nil, errors.Wrap(err.ToAggregate(), "unable to process template")
-	}
-	var result templatev1.Template
-	if err := p.scheme.Convert(tmpl, &result, nil); err != nil {
-		return nil, errors.Wrap(err, "failed to convert template to external template object")
+	var objs []runtime.RawExtension
+	if IsGoTemplate(tmpl) {
+		if err := tmplProcessor.GenerateParameterValues(tmpl); len(err) > 0 {
+			return nil, errors.Wrap(err.ToAggregate(), "unable to process template")
+		}
+		var err error
+		if objs, err = processGoTemplate(tmpl, values); err != nil {
+			return nil, errors.Wrap(err, "unable to process template")
+		}
+	} else {
+		if err := tmplProcessor.Process(tmpl); len(err) > 0 {
+			return nil, errors.Wrap(err.ToAggregate(), "unable to process template")
+		}
+		var result templatev1.Template
+		if err := p.scheme.Convert(tmpl, &result, nil); err != nil {
+			return nil, errors.Wrap(err, "failed to convert template to external template object")
+		}
+		objs = result.Objects
 	}
-	filtered := Filter(result.Objects, filters...)
+	filtered := Filter(objs, filters...)
 	objects := make([]runtimeclient.Object, len(filtered))
 	for i, rawObject := range filtered {
 		clientObj, ok := rawObject.Object.(runtimeclient.Object)
@@ -57,5 +131,34 @@ func (p Processor) Process(tmpl *templatev1.Template, values map[string]string,
 		}
 		objects[i] = clientObj
 	}
+	if err := Transform(objects, p.transforms...); err != nil {
+		return nil, errors.Wrap(err, "unable to transform the template objects")
+	}
 	return objects, nil
 }
+
+// generateParameterValues sets the value of each parameter with `generate: expression` and no value yet,
+// using a generator seeded with the given key and the name of the parameter
+func generateParameterValues(tmpl *templatev1.Template, key string) error {
+	for i := range tmpl.Parameters {
+		param := &tmpl.Parameters[i]
+		if param.Value != "" || param.Generate != "expression" {
+			continue
+		}
+		h := fnv.New64a()
+		// ignore the error, as this implementation cannot return one
+		_, _ = h.Write([]byte(key + "/" + param.Name))
+		gen := generator.NewExpressionValueGenerator(rand.New(rand.NewSource(int64(h.Sum64())))) //nolint:gosec
+		value, err := gen.GenerateValue(param.From)
+		if err != nil {
+			return errors.Wrapf(err, "unable to generate a value for parameter '%s'", param.Name)
+		}
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unable to convert the generated value '%#v' to string for parameter '%s'", value, param.Name)
+		}
+		param.Value = v
+		param.Generate = ""
+	}
+	return nil
+}
@@ -270,3 +270,101 @@ func newObject(template, username, commit string) (*unstructured.Unstructured, e
 	err = result.UnmarshalJSON(buf.Bytes())
 	return result, err
 }
+
+func TestProcessWithGeneratedParameters(t *testing.T) {
+	// given
+	s := addToScheme(t)
+	decoder := serializer.NewCodecFactory(s).UniversalDeserializer()
+	const passwordParam TemplateParam = `
+- name: PASSWORD
+  generate: expression
+  from: "[a-z0-9]{16}"`
+	const secret TemplateObject = `
+- apiVersion: v1
+  kind: Secret
+  metadata:
+    name: ${USERNAME}-password
+    namespace: ${USERNAME}
+  stringData:
+    password: ${PASSWORD}`
+
+	processAndGetPassword := func(t *testing.T, p template.Processor) string {
+		tmpl, err := DecodeTemplate(decoder,
+			CreateTemplate(WithObjects(secret), WithParams(UsernameParam, passwordParam)))
+		require.NoError(t, err)
+		objs, err := p.Process(tmpl, map[string]string{})
+		require.NoError(t, err)
+		require.Len(t, objs, 1)
+		password, found, err := unstructured.NestedString(objs[0].(*unstructured.Unstructured).Object, "stringData", "password")
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Regexp(t, "^[a-z0-9]{16}$", password)
+		return password
+	}
+
+	t.Run("with seed", func(t *testing.T) {
+		// when
+		first := processAndGetPassword(t, template.NewProcessor(s, template.WithSeed(42)))
+		second := processAndGetPassword(t, template.NewProcessor(s, template.WithSeed(42)))
+		other := processAndGetPassword(t, template.NewProcessor(s, template.WithSeed(24)))
+
+		// then
+		assert.Equal(t, first, second)
+		assert.NotEqual(t, first, other)
+	})
+
+	t.Run("with seed key", func(t *testing.T) {
+		// when
+		first := processAndGetPassword(t, template.NewProcessor(s, template.WithSeedKey("3c7f4a1e-space-uid")))
+		second := processAndGetPassword(t, template.NewProcessor(s, template.WithSeedKey("3c7f4a1e-space-uid")))
+		other := processAndGetPassword(t, template.NewProcessor(s, template.WithSeedKey("9b2d8e0f-space-uid")))
+
+		// then
+		assert.Equal(t, first, second)
+		assert.NotEqual(t, first, other)
+	})
+
+	t.Run("with seed key does not change the template", func(t *testing.T) {
+		// given
+		tmpl, err := DecodeTemplate(decoder,
+			CreateTemplate(WithObjects(secret), WithParams(UsernameParam, passwordParam)))
+		require.NoError(t, err)
+
+		// when
+		first, err := template.NewProcessor(s, template.WithSeedKey("3c7f4a1e-space-uid")).Process(tmpl, map[string]string{})
+		require.NoError(t, err)
+		second, err := template.NewProcessor(s, template.WithSeedKey("9b2d8e0f-space-uid")).Process(tmpl, map[string]string{})
+		require.NoError(t, err)
+
+		// then
+		for _, param := range tmpl.Parameters {
+			if param.Name == "PASSWORD" {
+				assert.Empty(t, param.Value)
+				assert.Equal(t, "expression", param.Generate)
+			}
+		}
+		firstPassword, _, err := unstructured.NestedString(first[0].(*unstructured.Unstructured).Object, "stringData", "password")
+		require.NoError(t, err)
+		secondPassword, _, err := unstructured.NestedString(second[0].(*unstructured.Unstructured).Object, "stringData", "password")
+		require.NoError(t, err)
+		assert.NotEqual(t, firstPassword, secondPassword)
+	})
+
+	t.Run("with provided value", func(t *testing.T) {
+		// given
+		p := template.NewProcessor(s, template.WithSeedKey("3c7f4a1e-space-uid"))
+		tmpl, err := DecodeTemplate(decoder,
+			CreateTemplate(WithObjects(secret), WithParams(UsernameParam, passwordParam)))
+		require.NoError(t, err)
+
+		// when
+		objs, err := p.Process(tmpl, map[string]string{"PASSWORD": "provided"})
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, objs, 1)
+		password, _, err := unstructured.NestedString(objs[0].(*unstructured.Unstructured).Object, "stringData", "password")
+		require.NoError(t, err)
+		assert.Equal(t, "provided", password)
+	})
+}
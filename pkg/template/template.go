@@ -36,26 +36,37 @@ func LoadObjectsFromEmbedFS(efs *embed.FS, variables *Variables) ([]*unstructure
 		if err != nil {
 			return objects, err
 		}
-		decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(buf.Bytes()), 100)
-		for {
-			var rawExt runtime.RawExtension
-			if err := decoder.Decode(&rawExt); err != nil {
-				if errors.Is(err, io.EOF) {
-					break
-				}
-				return objects, err
-			}
-			rawExt.Raw = bytes.TrimSpace(rawExt.Raw)
-			if len(rawExt.Raw) == 0 || bytes.Equal(rawExt.Raw, []byte("null")) {
-				continue
-			}
-			unstructuredObj := &unstructured.Unstructured{}
-			_, _, err = scheme.Codecs.UniversalDeserializer().Decode(rawExt.Raw, nil, unstructuredObj)
-			if err != nil {
-				return objects, err
+		objs, err := decodeObjects(buf.Bytes())
+		objects = append(objects, objs...)
+		if err != nil {
+			return objects, err
+		}
+	}
+	return objects, nil
+}
+
+// decodeObjects decodes all the kubernetes objects in the given content, which may contain several YAML documents
+func decodeObjects(content []byte) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 100)
+	for {
+		var rawExt runtime.RawExtension
+		if err := decoder.Decode(&rawExt); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
 			}
-			objects = append(objects, unstructuredObj)
+			return objects, err
+		}
+		rawExt.Raw = bytes.TrimSpace(rawExt.Raw)
+		if len(rawExt.Raw) == 0 || bytes.Equal(rawExt.Raw, []byte("null")) {
+			continue
+		}
+		unstructuredObj := &unstructured.Unstructured{}
+		_, _, err := scheme.Codecs.UniversalDeserializer().Decode(rawExt.Raw, nil, unstructuredObj)
+		if err != nil {
+			return objects, err
 		}
+		objects = append(objects, unstructuredObj)
 	}
 	return objects, nil
 }
@@ -0,0 +1,86 @@
+package template
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TransformFunc a function to modify an object after the template was processed and its objects were filtered
+type TransformFunc func(runtimeclient.Object) error
+
+// AddLabels returns a func to add the given labels to the objects, overwriting the existing labels with the same keys
+func AddLabels(newLabels map[string]string) TransformFunc {
+	return func(obj runtimeclient.Object) error {
+		objLabels := obj.GetLabels()
+		if objLabels == nil {
+			objLabels = make(map[string]string, len(newLabels))
+		}
+		for k, v := range newLabels {
+			objLabels[k] = v
+		}
+		obj.SetLabels(objLabels)
+		return nil
+	}
+}
+
+// AddAnnotations returns a func to add the given annotations to the objects, overwriting the existing annotations with the same keys
+func AddAnnotations(newAnnotations map[string]string) TransformFunc {
+	return func(obj runtimeclient.Object) error {
+		objAnnotations := obj.GetAnnotations()
+		if objAnnotations == nil {
+			objAnnotations = make(map[string]string, len(newAnnotations))
+		}
+		for k, v := range newAnnotations {
+			objAnnotations[k] = v
+		}
+		obj.SetAnnotations(objAnnotations)
+		return nil
+	}
+}
+
+// SetNamespace returns a func to set the namespace of the objects.
+// Combine it with TransformIf and RetainNamespacedObjects to only set the namespace of namespace-scoped objects.
+func SetNamespace(namespace string) TransformFunc {
+	return func(obj runtimeclient.Object) error {
+		obj.SetNamespace(namespace)
+		return nil
+	}
+}
+
+// StripField returns a func to remove the field at the given path (eg, `"status"` or `"metadata", "annotations", "foo"`).
+// It only supports Unstructured objects, which is what the Processor returns.
+func StripField(fields ...string) TransformFunc {
+	return func(obj runtimeclient.Object) error {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("unable to strip field %v: object '%s' is not an Unstructured object", fields, obj.GetName())
+		}
+		unstructured.RemoveNestedField(u.Object, fields...)
+		return nil
+	}
+}
+
+// TransformIf returns a func which applies the given transform on the objects retained by the given filter, and leaves the others unchanged
+func TransformIf(filter FilterFunc, transform TransformFunc) TransformFunc {
+	return func(obj runtimeclient.Object) error {
+		if !filter(runtime.RawExtension{Object: obj}) {
+			return nil
+		}
+		return transform(obj)
+	}
+}
+
+// Transform applies the given transforms, in order, on each of the given objects
+func Transform(objs []runtimeclient.Object, transforms ...TransformFunc) error {
+	for _, obj := range objs {
+		for _, transform := range transforms {
+			if err := transform(obj); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package template_test
+
+import (
+	"testing"
+
+	"github.com/codeready-toolchain/toolchain-common/pkg/template"
+	. "github.com/codeready-toolchain/toolchain-common/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestTransform(t *testing.T) {
+
+	t.Run("add labels and annotations", func(t *testing.T) {
+		// given
+		cm := newUnstructured("v1", "ConfigMap", "john-dev", "john-config", map[string]string{"foo": "bar", "override": "old"}, nil)
+
+		// when
+		err := template.Transform([]runtimeclient.Object{cm},
+			template.AddLabels(map[string]string{"override": "new", "other": "value"}),
+			template.AddAnnotations(map[string]string{"note": "added"}))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"foo": "bar", "override": "new", "other": "value"}, cm.GetLabels())
+		assert.Equal(t, map[string]string{"note": "added"}, cm.GetAnnotations())
+	})
+
+	t.Run("set namespace of namespaced objects only", func(t *testing.T) {
+		// given
+		ns := newUnstructured("v1", "Namespace", "", "john-dev", nil, nil)
+		cm := newUnstructured("v1", "ConfigMap", "", "john-config", nil, nil)
+
+		// when
+		err := template.Transform([]runtimeclient.Object{ns, cm},
+			template.TransformIf(template.Not(template.RetainNamespaces), template.SetNamespace("john-dev")))
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, ns.GetNamespace())
+		assert.Equal(t, "john-dev", cm.GetNamespace())
+	})
+
+	t.Run("strip fields", func(t *testing.T) {
+		// given
+		cm := newUnstructured("v1", "ConfigMap", "john-dev", "john-config", nil, map[string]string{"keep": "me", "remove": "me"})
+		cm.Object["status"] = map[string]interface{}{"phase": "Ready"}
+
+		// when
+		err := template.Transform([]runtimeclient.Object{cm},
+			template.StripField("status"),
+			template.StripField("metadata", "annotations", "remove"))
+
+		// then
+		require.NoError(t, err)
+		assert.NotContains(t, cm.Object, "status")
+		assert.Equal(t, map[string]string{"keep": "me"}, cm.GetAnnotations())
+	})
+
+	t.Run("strip fields fails on typed object", func(t *testing.T) {
+		// given
+		cm := &corev1.ConfigMap{}
+		cm.Name = "john-config"
+
+		// when
+		err := template.Transform([]runtimeclient.Object{cm}, template.StripField("status"))
+
+		// then
+		require.EqualError(t, err, "unable to strip field [status]: object 'john-config' is not an Unstructured object")
+	})
+}
+
+func TestProcessWithTransforms(t *testing.T) {
+	// given
+	s := addToScheme(t)
+	decoder := serializer.NewCodecFactory(s).UniversalDeserializer()
+	p := template.NewProcessor(s, template.WithTransforms(
+		template.AddLabels(map[string]string{"toolchain.dev.openshift.com/owner": "john"}),
+		template.StripField("metadata", "labels", "extra"),
+	))
+	tmpl, err := DecodeTemplate(decoder,
+		CreateTemplate(WithObjects(Namespace, RoleBinding), WithParams(UsernameParam, CommitParam)))
+	require.NoError(t, err)
+
+	// when
+	objs, err := p.Process(tmpl, map[string]string{"USERNAME": "john"}, template.RetainAllButNamespaces)
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, objs, 1)
+	assert.Equal(t, "RoleBinding", objs[0].(*unstructured.Unstructured).GetKind())
+	assert.Equal(t, map[string]string{"toolchain.dev.openshift.com/owner": "john"}, objs[0].GetLabels())
+}
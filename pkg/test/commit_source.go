@@ -0,0 +1,27 @@
+package test
+
+import (
+	"context"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-common/pkg/client"
+)
+
+// FakeCommitSource a CommitSource which returns the given commit or error
+type FakeCommitSource struct {
+	Commit *client.Commit
+	Err    error
+}
+
+func (s *FakeCommitSource) LatestCommit(context.Context, client.Repository) (*client.Commit, error) {
+	return s.Commit, s.Err
+}
+
+// MockCommitSource provides a CommitSource which will return the given commit SHA and timestamp as the latest commit
+func MockCommitSource(commitSHA string, commitTimestamp time.Time) client.GetCommitSourceFunc {
+	return func(context.Context, string) client.CommitSource {
+		return &FakeCommitSource{
+			Commit: &client.Commit{SHA: commitSHA, Timestamp: commitTimestamp},
+		}
+	}
+}
@@ -5,7 +5,9 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func AssertMetricsCounterEquals(t *testing.T, expected int, c prometheus.Counter) {
@@ -27,3 +29,70 @@ func AssertCounterGreaterOrEqualsInt(t *testing.T, threshold int, c prometheus.C
 func AssertMetricsGaugeEquals(t *testing.T, expected int, g prometheus.Gauge, msgAndArgs ...interface{}) {
 	assert.InDelta(t, float64(expected), promtestutil.ToFloat64(g), 0.01, msgAndArgs...)
 }
+
+// AssertCounterVecEquals asserts that the given vector contains a single counter with the given labels, which has the expected value.
+// As opposed to `WithLabelValues`, the counter is not created if it doesn't exist.
+func AssertCounterVecEquals(t *testing.T, expected int, c *prometheus.CounterVec, labels prometheus.Labels) {
+	series := findSeries(t, c, labels)
+	require.Len(t, series, 1, "labels: %v", labels)
+	assert.InDelta(t, float64(expected), series[0].GetCounter().GetValue(), 0.01, "labels: %v", labels)
+}
+
+// AssertGaugeVecEquals asserts that the given vector contains a single gauge with the given labels, which has the expected value.
+// As opposed to `WithLabelValues`, the gauge is not created if it doesn't exist.
+func AssertGaugeVecEquals(t *testing.T, expected int, g *prometheus.GaugeVec, labels prometheus.Labels) {
+	series := findSeries(t, g, labels)
+	require.Len(t, series, 1, "labels: %v", labels)
+	assert.InDelta(t, float64(expected), series[0].GetGauge().GetValue(), 0.01, "labels: %v", labels)
+}
+
+// AssertMetricsCount asserts that the given collector (eg, a CounterVec or a GaugeVec) contains the expected number of series
+func AssertMetricsCount(t *testing.T, expected int, c prometheus.Collector) {
+	assert.Equal(t, expected, promtestutil.CollectAndCount(c))
+}
+
+// AssertLabelledMetricExists asserts that the given collector contains a series with the given labels,
+// without creating it as `WithLabelValues` would do
+func AssertLabelledMetricExists(t *testing.T, c prometheus.Collector, labels prometheus.Labels) {
+	assert.True(t, hasSeries(t, c, labels), "no series with labels %v", labels)
+}
+
+// AssertLabelledMetricNotExists asserts that the given collector doesn't contain any series with the given labels
+func AssertLabelledMetricNotExists(t *testing.T, c prometheus.Collector, labels prometheus.Labels) {
+	assert.False(t, hasSeries(t, c, labels), "unexpected series with labels %v", labels)
+}
+
+// hasSeries returns `true` if the given collector contains a series whose labels include the given ones
+func hasSeries(t *testing.T, c prometheus.Collector, labels prometheus.Labels) bool {
+	return len(findSeries(t, c, labels)) > 0
+}
+
+// findSeries returns the series of the given collector whose labels include the given ones
+func findSeries(t *testing.T, c prometheus.Collector, labels prometheus.Labels) []*dto.Metric {
+	// all the metrics are collected before they are checked, so that the collecting goroutine is never blocked
+	// if the test stops on an assertion
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		c.Collect(metrics)
+		close(metrics)
+	}()
+	var collected []prometheus.Metric
+	for m := range metrics {
+		collected = append(collected, m)
+	}
+	var found []*dto.Metric
+	for _, m := range collected {
+		series := &dto.Metric{}
+		require.NoError(t, m.Write(series))
+		matching := 0
+		for _, pair := range series.GetLabel() {
+			if value, ok := labels[pair.GetName()]; ok && value == pair.GetValue() {
+				matching++
+			}
+		}
+		if matching == len(labels) {
+			found = append(found, series)
+		}
+	}
+	return found
+}
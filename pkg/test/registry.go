@@ -0,0 +1,82 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// FakeRegistry a local stand-in for a container image registry, which serves the digests of the manifests
+// via the OCI distribution API (`HEAD /v2/<repository>/manifests/<tag>`).
+// When a token is set, the registry requires the bearer token authentication, as the public registries do.
+type FakeRegistry struct {
+	*httptest.Server
+	sync.RWMutex
+	digests map[string]string
+	token   string
+}
+
+// NewFakeRegistry starts a new FakeRegistry, which is stopped at the end of the test
+func NewFakeRegistry(t *testing.T) *FakeRegistry {
+	r := &FakeRegistry{
+		digests: map[string]string{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/", r.serveManifest)
+	mux.HandleFunc("/token", r.serveToken)
+	r.Server = httptest.NewServer(mux)
+	t.Cleanup(r.Close)
+	return r
+}
+
+// Host returns the host and port of the registry, to be used in the image references (eg, `127.0.0.1:12345/org/image:tag`)
+func (r *FakeRegistry) Host() string {
+	return strings.TrimPrefix(r.URL, "http://")
+}
+
+// SetDigest makes the given tag of the given repository point to the given digest
+func (r *FakeRegistry) SetDigest(repository, tag, digest string) {
+	r.Lock()
+	defer r.Unlock()
+	r.digests[repository+":"+tag] = digest
+}
+
+// RequireToken makes the registry require the given bearer token, which is returned by its `/token` endpoint
+func (r *FakeRegistry) RequireToken(token string) {
+	r.Lock()
+	defer r.Unlock()
+	r.token = token
+}
+
+func (r *FakeRegistry) serveManifest(w http.ResponseWriter, req *http.Request) {
+	r.RLock()
+	defer r.RUnlock()
+	repository, tag, found := strings.Cut(strings.TrimPrefix(req.URL.Path, "/v2/"), "/manifests/")
+	if !found || req.Method != http.MethodHead {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.token != "" && req.Header.Get("Authorization") != "Bearer "+r.token {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake-registry",scope="repository:%s:pull"`, r.URL, repository))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	digest, found := r.digests[repository+":"+tag]
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Docker-Content-Digest", digest)
+	w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *FakeRegistry) serveToken(w http.ResponseWriter, _ *http.Request) {
+	r.RLock()
+	defer r.RUnlock()
+	_ = json.NewEncoder(w).Encode(map[string]string{"token": r.token})
+}
@@ -97,6 +97,16 @@ func WithTierHashLabelFor(tier *toolchainv1alpha1.NSTemplateTier) Option {
 	}
 }
 
+func WithVersionedTierHashLabelFor(tier *toolchainv1alpha1.NSTemplateTier, version int) Option {
+	return func(space *toolchainv1alpha1.Space) {
+		h, _ := hash.ComputeHashForNSTemplateTierWithVersion(tier, version) // we can assume the JSON marshalling will always work
+		if space.ObjectMeta.Labels == nil {
+			space.ObjectMeta.Labels = map[string]string{}
+		}
+		space.ObjectMeta.Labels[hash.TemplateTierHashLabelKey(tier.Name)] = h
+	}
+}
+
 func WithTierNameAndHashLabelFor(tier *toolchainv1alpha1.NSTemplateTier) Option {
 	return func(space *toolchainv1alpha1.Space) {
 		WithTierName(tier.Name)(space)